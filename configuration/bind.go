@@ -0,0 +1,321 @@
+package configuration
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/cjlapao/common-go/duration"
+	"github.com/cjlapao/common-go/guard"
+)
+
+var (
+	ErrKeyNotFound   = errors.New("key was not found")
+	ErrInvalidTarget = errors.New("target must be a non nil pointer to a struct")
+)
+
+var (
+	timeDurationType   = reflect.TypeOf(time.Duration(0))
+	timeType           = reflect.TypeOf(time.Time{})
+	isoDurationType    = reflect.TypeOf(duration.Duration{})
+	isoDurationPtrType = reflect.TypeOf(&duration.Duration{})
+)
+
+// KeyError describes a configuration key that could not be read
+type KeyError struct {
+	Key string
+	Err error
+}
+
+func (e KeyError) Error() string {
+	return fmt.Sprintf("key %v: %v", e.Key, e.Err)
+}
+
+func (e KeyError) Unwrap() error {
+	return e.Err
+}
+
+// BindError lists every key that was missing or malformed while binding a struct
+type BindError struct {
+	Errors []KeyError
+}
+
+func (e *BindError) Error() string {
+	messages := make([]string, len(e.Errors))
+	for i, keyError := range e.Errors {
+		messages[i] = keyError.Error()
+	}
+
+	return "configuration binding failed: " + strings.Join(messages, "; ")
+}
+
+func (e *BindError) Unwrap() []error {
+	result := make([]error, len(e.Errors))
+	for i, keyError := range e.Errors {
+		result[i] = keyError
+	}
+
+	return result
+}
+
+// Bind Populates the target struct from the configuration using the config tags.
+//
+//	type DatabaseOptions struct {
+//		Host    string        `config:"HOST,required"`
+//		Port    int           `config:"PORT" default:"5432"`
+//		Timeout time.Duration `config:"TIMEOUT" default:"30s"`
+//		Tags    []string      `config:"TAGS"`
+//	}
+//
+// Keys are joined to the prefix with an underscore unless the prefix already ends
+// with a separator, so Bind("DB", &options) reads DB_HOST. Nested structs use their
+// tag as a sub prefix or the parent prefix when untagged, slices are read from
+// comma separated values. All the missing and malformed keys are returned in a *BindError
+func (c *ConfigurationService) Bind(prefix string, target interface{}) error {
+	value := reflect.ValueOf(target)
+	if value.Kind() != reflect.Ptr || value.IsNil() || value.Elem().Kind() != reflect.Struct {
+		return ErrInvalidTarget
+	}
+
+	bindErr := &BindError{}
+	c.bindStruct(prefix, value.Elem(), bindErr)
+	if len(bindErr.Errors) > 0 {
+		return bindErr
+	}
+
+	return nil
+}
+
+func (c *ConfigurationService) bindStruct(prefix string, target reflect.Value, bindErr *BindError) {
+	targetType := target.Type()
+	for i := 0; i < targetType.NumField(); i++ {
+		field := targetType.Field(i)
+		fieldValue := target.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+
+		tag, hasTag := field.Tag.Lookup("config")
+		name, options, _ := strings.Cut(tag, ",")
+		name = strings.TrimSpace(name)
+		if name == "-" {
+			continue
+		}
+
+		if isNestedStruct(field.Type) {
+			nestedPrefix := prefix
+			if hasTag && name != "" {
+				nestedPrefix = joinConfigKey(prefix, name)
+			}
+			if field.Type.Kind() == reflect.Ptr {
+				if fieldValue.IsNil() {
+					fieldValue.Set(reflect.New(field.Type.Elem()))
+				}
+				fieldValue = fieldValue.Elem()
+			}
+			c.bindStruct(nestedPrefix, fieldValue, bindErr)
+			continue
+		}
+
+		if !hasTag || name == "" {
+			continue
+		}
+
+		key := joinConfigKey(prefix, name)
+		raw, err := c.resolve(key)
+		if err != nil {
+			bindErr.Errors = append(bindErr.Errors, KeyError{Key: key, Err: err})
+			continue
+		}
+		if guard.IsNill(raw) {
+			defaultValue, hasDefault := field.Tag.Lookup("default")
+			switch {
+			case hasDefault:
+				raw = defaultValue
+			case hasTagOption(options, "required"):
+				bindErr.Errors = append(bindErr.Errors, KeyError{Key: key, Err: ErrKeyNotFound})
+				continue
+			default:
+				continue
+			}
+		}
+
+		converted, err := convertConfigValue(raw, field.Type)
+		if err != nil {
+			bindErr.Errors = append(bindErr.Errors, KeyError{Key: key, Err: err})
+			continue
+		}
+		fieldValue.Set(converted)
+	}
+}
+
+func isNestedStruct(fieldType reflect.Type) bool {
+	if fieldType.Kind() == reflect.Ptr {
+		fieldType = fieldType.Elem()
+	}
+
+	return fieldType.Kind() == reflect.Struct && fieldType != timeType && fieldType != isoDurationType
+}
+
+func hasTagOption(options string, option string) bool {
+	for _, value := range strings.Split(options, ",") {
+		if strings.TrimSpace(value) == option {
+			return true
+		}
+	}
+
+	return false
+}
+
+func joinConfigKey(prefix string, key string) string {
+	if prefix == "" {
+		return key
+	}
+
+	if strings.HasSuffix(prefix, "_") || strings.HasSuffix(prefix, ".") || strings.HasSuffix(prefix, ":") {
+		return prefix + key
+	}
+
+	return prefix + "_" + key
+}
+
+// convertConfigValue converts a raw configuration value into the target type
+func convertConfigValue(raw interface{}, targetType reflect.Type) (reflect.Value, error) {
+	rawValue := reflect.ValueOf(raw)
+	if raw != nil && rawValue.Type().AssignableTo(targetType) && targetType.Kind() != reflect.Interface {
+		return rawValue, nil
+	}
+
+	switch targetType {
+	case timeDurationType:
+		text := strings.TrimSpace(fmt.Sprint(raw))
+		if seconds, err := strconv.ParseInt(text, 10, 64); err == nil {
+			return reflect.ValueOf(time.Duration(seconds) * time.Second), nil
+		}
+		result, err := time.ParseDuration(text)
+		if err != nil {
+			return reflect.Value{}, fmt.Errorf("invalid duration %q", text)
+		}
+		return reflect.ValueOf(result), nil
+	case isoDurationType, isoDurationPtrType:
+		text := strings.TrimSpace(fmt.Sprint(raw))
+		result, err := duration.FromString(text)
+		if err != nil || !strings.HasPrefix(text, "P") {
+			return reflect.Value{}, fmt.Errorf("invalid ISO 8601 duration %q", text)
+		}
+		if targetType == isoDurationPtrType {
+			return reflect.ValueOf(result), nil
+		}
+		return reflect.ValueOf(*result), nil
+	case timeType:
+		text := strings.TrimSpace(fmt.Sprint(raw))
+		result, err := time.Parse(time.RFC3339, text)
+		if err != nil {
+			return reflect.Value{}, fmt.Errorf("invalid RFC3339 time %q", text)
+		}
+		return reflect.ValueOf(result), nil
+	}
+
+	result := reflect.New(targetType).Elem()
+	switch targetType.Kind() {
+	case reflect.Interface:
+		if raw != nil {
+			result.Set(rawValue)
+		}
+	case reflect.String:
+		result.SetString(fmt.Sprint(raw))
+	case reflect.Bool:
+		value, err := strconv.ParseBool(strings.TrimSpace(fmt.Sprint(raw)))
+		if err != nil {
+			return reflect.Value{}, fmt.Errorf("invalid boolean %q", fmt.Sprint(raw))
+		}
+		result.SetBool(value)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		value, err := strconv.ParseInt(strings.TrimSpace(fmt.Sprint(raw)), 10, targetType.Bits())
+		if err != nil {
+			return reflect.Value{}, fmt.Errorf("invalid integer %q", fmt.Sprint(raw))
+		}
+		result.SetInt(value)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		value, err := strconv.ParseUint(strings.TrimSpace(fmt.Sprint(raw)), 10, targetType.Bits())
+		if err != nil {
+			return reflect.Value{}, fmt.Errorf("invalid unsigned integer %q", fmt.Sprint(raw))
+		}
+		result.SetUint(value)
+	case reflect.Float32, reflect.Float64:
+		value, err := strconv.ParseFloat(strings.TrimSpace(fmt.Sprint(raw)), targetType.Bits())
+		if err != nil {
+			return reflect.Value{}, fmt.Errorf("invalid number %q", fmt.Sprint(raw))
+		}
+		result.SetFloat(value)
+	case reflect.Slice:
+		items := splitConfigList(raw)
+		slice := reflect.MakeSlice(targetType, 0, len(items))
+		for index, item := range items {
+			converted, err := convertConfigValue(item, targetType.Elem())
+			if err != nil {
+				return reflect.Value{}, fmt.Errorf("item %d: %w", index, err)
+			}
+			slice = reflect.Append(slice, converted)
+		}
+		result.Set(slice)
+	case reflect.Map:
+		if targetType.Key().Kind() != reflect.String {
+			return reflect.Value{}, fmt.Errorf("unsupported map type %v", targetType)
+		}
+		items, ok := raw.(map[string]interface{})
+		if !ok {
+			return reflect.Value{}, fmt.Errorf("value is not a map")
+		}
+		mapValue := reflect.MakeMapWithSize(targetType, len(items))
+		for key, item := range items {
+			converted, err := convertConfigValue(item, targetType.Elem())
+			if err != nil {
+				return reflect.Value{}, fmt.Errorf("item %v: %w", key, err)
+			}
+			mapValue.SetMapIndex(reflect.ValueOf(key).Convert(targetType.Key()), converted)
+		}
+		result.Set(mapValue)
+	case reflect.Ptr:
+		converted, err := convertConfigValue(raw, targetType.Elem())
+		if err != nil {
+			return reflect.Value{}, err
+		}
+		pointer := reflect.New(targetType.Elem())
+		pointer.Elem().Set(converted)
+		result.Set(pointer)
+	default:
+		return reflect.Value{}, fmt.Errorf("unsupported type %v", targetType)
+	}
+
+	return result, nil
+}
+
+// splitConfigList reads a list either from a slice value or from a comma separated string
+func splitConfigList(raw interface{}) []interface{} {
+	if raw == nil {
+		return []interface{}{}
+	}
+
+	value := reflect.ValueOf(raw)
+	if value.Kind() == reflect.Slice && value.Type() != reflect.TypeOf([]byte{}) {
+		result := make([]interface{}, value.Len())
+		for i := 0; i < value.Len(); i++ {
+			result[i] = value.Index(i).Interface()
+		}
+		return result
+	}
+
+	result := make([]interface{}, 0)
+	for _, item := range strings.Split(fmt.Sprint(raw), ",") {
+		item = strings.TrimSpace(item)
+		if item != "" {
+			result = append(result, item)
+		}
+	}
+
+	return result
+}
@@ -0,0 +1,153 @@
+package configuration
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/cjlapao/common-go/constants"
+	"github.com/cjlapao/common-go/duration"
+	"github.com/stretchr/testify/assert"
+)
+
+type testBindDatabase struct {
+	Host string `config:"HOST,required"`
+	Port int    `config:"PORT" default:"5432"`
+}
+
+type testBindOptions struct {
+	Name       string             `config:"NAME"`
+	Enabled    bool               `config:"ENABLED"`
+	Ratio      float64            `config:"RATIO"`
+	Timeout    time.Duration      `config:"TIMEOUT" default:"30s"`
+	Retention  duration.Duration  `config:"RETENTION"`
+	Expiry     *duration.Duration `config:"EXPIRY"`
+	Tags       []string           `config:"TAGS"`
+	Ports      []int              `config:"PORTS"`
+	Database   testBindDatabase   `config:"DB"`
+	Inline     testBindInline
+	Ignored    string `config:"-"`
+	unexported string `config:"UNEXPORTED"`
+}
+
+type testBindInline struct {
+	Region string `config:"REGION" default:"eu-west"`
+}
+
+func TestBind_PopulatesStruct(t *testing.T) {
+	// Arrange
+	config := New().RegisterDefaults()
+	config.UpsertKeys(map[string]interface{}{
+		"APP_NAME":      "service",
+		"APP_ENABLED":   "true",
+		"APP_RATIO":     0.5,
+		"APP_TIMEOUT":   "1m",
+		"APP_RETENTION": "P2D",
+		"APP_EXPIRY":    "PT1H30M",
+		"APP_TAGS":      "a, b,c",
+		"APP_PORTS":     []interface{}{80, "443"},
+		"APP_DB_HOST":   "localhost",
+		"APP_IGNORED":   "ignored",
+	})
+	var options testBindOptions
+
+	// Act
+	err := config.Bind("APP", &options)
+
+	// Assert
+	assert.Nil(t, err)
+	assert.Equal(t, "service", options.Name)
+	assert.True(t, options.Enabled)
+	assert.Equal(t, 0.5, options.Ratio)
+	assert.Equal(t, time.Minute, options.Timeout)
+	assert.Equal(t, 2, options.Retention.Days)
+	assert.Equal(t, 1, options.Expiry.Hours)
+	assert.Equal(t, 30, options.Expiry.Minutes)
+	assert.Equal(t, []string{"a", "b", "c"}, options.Tags)
+	assert.Equal(t, []int{80, 443}, options.Ports)
+	assert.Equal(t, "localhost", options.Database.Host)
+	assert.Equal(t, 5432, options.Database.Port)
+	assert.Equal(t, "eu-west", options.Inline.Region)
+	assert.Equal(t, "", options.Ignored)
+	assert.Equal(t, "", options.unexported)
+}
+
+func TestBind_ReturnsEveryMissingAndMalformedKey(t *testing.T) {
+	// Arrange
+	config := New().RegisterDefaults()
+	config.UpsertKeys(map[string]interface{}{
+		"BAD_ENABLED":   "maybe",
+		"BAD_TIMEOUT":   "soon",
+		"BAD_RETENTION": "two days",
+		"BAD_PORTS":     "80,http",
+	})
+	var options testBindOptions
+
+	// Act
+	err := config.Bind("BAD", &options)
+
+	// Assert
+	var bindErr *BindError
+	assert.True(t, errors.As(err, &bindErr))
+	keys := make([]string, 0)
+	for _, keyError := range bindErr.Errors {
+		keys = append(keys, keyError.Key)
+	}
+	assert.ElementsMatch(t, []string{"BAD_ENABLED", "BAD_TIMEOUT", "BAD_RETENTION", "BAD_PORTS", "BAD_DB_HOST"}, keys)
+	assert.ErrorIs(t, err, ErrKeyNotFound)
+	assert.Contains(t, err.Error(), "key BAD_DB_HOST: key was not found")
+}
+
+func TestBind_ReportsDecryptAndReferenceErrors(t *testing.T) {
+	// Arrange
+	masterKey, _ := GenerateMasterKey()
+	t.Setenv(constants.CONFIG_MASTER_KEY_ENVIRONMENT, masterKey)
+	config := New().RegisterDefaults()
+	config.UpsertKeys(map[string]interface{}{
+		"BROKEN_NAME":    "enc:AES256GCM:bm90IGVuY3J5cHRlZA==",
+		"BROKEN_DB_HOST": "db.local",
+		"BROKEN_DB_PORT": "${BROKEN_CYCLE}",
+		"BROKEN_CYCLE":   "${BROKEN_DB_PORT}",
+	})
+	var options testBindOptions
+
+	// Act
+	err := config.Bind("BROKEN", &options)
+
+	// Assert
+	var bindErr *BindError
+	assert.True(t, errors.As(err, &bindErr))
+	keys := make([]string, 0)
+	for _, keyError := range bindErr.Errors {
+		keys = append(keys, keyError.Key)
+	}
+	assert.ElementsMatch(t, []string{"BROKEN_NAME", "BROKEN_DB_PORT"}, keys)
+	assert.ErrorIs(t, err, ErrReferenceCycle)
+	assert.Zero(t, options.Database.Port)
+	assert.Equal(t, "db.local", options.Database.Host)
+}
+
+func TestBind_PrefixWithSeparatorIsNotJoined(t *testing.T) {
+	// Arrange
+	config := New()
+	config.RegisterProvider(newTestFileProvider(t, "settings.yaml", "database:\n  host: db.local\n  port: 6543\n"))
+	var options testBindDatabase
+
+	// Act
+	err := config.Bind("database.", &options)
+
+	// Assert
+	assert.Nil(t, err)
+	assert.Equal(t, "db.local", options.Host)
+	assert.Equal(t, 6543, options.Port)
+}
+
+func TestBind_InvalidTargetReturnsError(t *testing.T) {
+	// Arrange
+	config := New().RegisterDefaults()
+	var options testBindOptions
+
+	// Act + Assert
+	assert.ErrorIs(t, config.Bind("", options), ErrInvalidTarget)
+	assert.ErrorIs(t, config.Bind("", nil), ErrInvalidTarget)
+}
@@ -2,16 +2,49 @@ package configuration
 
 import (
 	"errors"
+	"sync"
+	"time"
 
 	"github.com/cjlapao/common-go/guard"
 )
 
-var vault = make(map[string]interface{})
+type vaultEntry struct {
+	value     interface{}
+	expiresAt time.Time
+	timer     *time.Timer
+}
+
+func (e vaultEntry) expired(now time.Time) bool {
+	return !e.expiresAt.IsZero() && !now.Before(e.expiresAt)
+}
 
+func (e vaultEntry) stop() {
+	if e.timer != nil {
+		e.timer.Stop()
+	}
+}
+
+// CachedVaultConfigurationProvider is an in memory provider, each instance owns its own
+// values and is safe for concurrent use. Writes, clears and expired keys are reported to
+// the OnChange handlers
 type CachedVaultConfigurationProvider struct {
+	mutex    sync.RWMutex
+	values   map[string]vaultEntry
+	handlers []func(keys []string)
 }
 
-func (ev CachedVaultConfigurationProvider) UpsertKey(key string, value interface{}) error {
+func NewCachedVaultConfigurationProvider() *CachedVaultConfigurationProvider {
+	return &CachedVaultConfigurationProvider{
+		values: make(map[string]vaultEntry),
+	}
+}
+
+func (ev *CachedVaultConfigurationProvider) UpsertKey(key string, value interface{}) error {
+	return ev.UpsertKeyWithExpiry(key, value, 0)
+}
+
+// UpsertKeyWithExpiry Sets a key that is removed after the ttl, a ttl of zero never expires
+func (ev *CachedVaultConfigurationProvider) UpsertKeyWithExpiry(key string, value interface{}, ttl time.Duration) error {
 	emptyKey := guard.EmptyOrNil(key, "key")
 	emptyValue := guard.EmptyOrNil(value, "value")
 
@@ -23,12 +56,25 @@ func (ev CachedVaultConfigurationProvider) UpsertKey(key string, value interface
 		return emptyValue
 	}
 
-	vault[key] = value
+	entry := vaultEntry{value: value}
+	if ttl > 0 {
+		expiresAt := time.Now().Add(ttl)
+		entry.expiresAt = expiresAt
+		entry.timer = time.AfterFunc(ttl, func() {
+			ev.expire(key, expiresAt)
+		})
+	}
+
+	ev.mutex.Lock()
+	ev.initialize()
+	ev.set(key, entry)
+	ev.mutex.Unlock()
 
+	ev.notify([]string{key})
 	return nil
 }
 
-func (ev CachedVaultConfigurationProvider) UpsertKeys(values map[string]interface{}) []error {
+func (ev *CachedVaultConfigurationProvider) UpsertKeys(values map[string]interface{}) []error {
 	errorArray := make([]error, 0)
 
 	if values == nil {
@@ -37,6 +83,10 @@ func (ev CachedVaultConfigurationProvider) UpsertKeys(values map[string]interfac
 	}
 
 	if len(values) > 0 {
+		changed := make([]string, 0, len(values))
+		ev.mutex.Lock()
+		ev.initialize()
+
 		for key, value := range values {
 			emptykey := guard.EmptyOrNil(key)
 			emptyValue := guard.EmptyOrNil(value)
@@ -49,7 +99,15 @@ func (ev CachedVaultConfigurationProvider) UpsertKeys(values map[string]interfac
 				errorArray = append(errorArray, emptyValue)
 			}
 
-			vault[key] = value
+			if emptykey == nil && emptyValue == nil {
+				ev.set(key, vaultEntry{value: value})
+				changed = append(changed, key)
+			}
+		}
+		ev.mutex.Unlock()
+
+		if len(changed) > 0 {
+			ev.notify(changed)
 		}
 		return errorArray
 	}
@@ -57,21 +115,111 @@ func (ev CachedVaultConfigurationProvider) UpsertKeys(values map[string]interfac
 	return nil
 }
 
-func (ev CachedVaultConfigurationProvider) Get(key string) interface{} {
-	for keyIndex, keyValue := range vault {
-		if key == keyIndex {
-			return keyValue
-		}
+func (ev *CachedVaultConfigurationProvider) Get(key string) interface{} {
+	ev.mutex.RLock()
+	entry, ok := ev.values[key]
+	ev.mutex.RUnlock()
+
+	if !ok {
+		return nil
 	}
 
-	return nil
+	if entry.expired(time.Now()) {
+		ev.removeExpired(key)
+		return nil
+	}
+
+	return entry.value
 }
 
-func (ev CachedVaultConfigurationProvider) Clear(key string) {
+func (ev *CachedVaultConfigurationProvider) Clear(key string) {
 	emptyKey := guard.EmptyOrNil(key, "key")
 
 	if emptyKey == nil {
-		delete(vault, key)
-		//TODO: Implement the logic to remove it from the cached vault
+		ev.mutex.Lock()
+		entry, ok := ev.values[key]
+		if ok {
+			entry.stop()
+			delete(ev.values, key)
+		}
+		ev.mutex.Unlock()
+
+		if ok {
+			ev.notify([]string{key})
+		}
+	}
+}
+
+// OnChange Registers a handler called with the keys that were written, cleared or expired
+func (ev *CachedVaultConfigurationProvider) OnChange(handler func(keys []string)) {
+	ev.mutex.Lock()
+	defer ev.mutex.Unlock()
+
+	ev.handlers = append(ev.handlers, handler)
+}
+
+func (ev *CachedVaultConfigurationProvider) Keys() []string {
+	ev.mutex.RLock()
+	defer ev.mutex.RUnlock()
+
+	now := time.Now()
+	result := make([]string, 0, len(ev.values))
+	for key, entry := range ev.values {
+		if !entry.expired(now) {
+			result = append(result, key)
+		}
+	}
+
+	return result
+}
+
+func (ev *CachedVaultConfigurationProvider) initialize() {
+	if ev.values == nil {
+		ev.values = make(map[string]vaultEntry)
+	}
+}
+
+// removeExpired deletes the key if it is still expired once the write lock is held
+func (ev *CachedVaultConfigurationProvider) removeExpired(key string) {
+	ev.mutex.Lock()
+	defer ev.mutex.Unlock()
+
+	if entry, ok := ev.values[key]; ok && entry.expired(time.Now()) {
+		delete(ev.values, key)
+	}
+}
+
+// set replaces the entry of the key stopping the expiry timer of the previous one, the
+// write lock must be held
+func (ev *CachedVaultConfigurationProvider) set(key string, entry vaultEntry) {
+	if previous, ok := ev.values[key]; ok {
+		previous.stop()
+	}
+	ev.values[key] = entry
+}
+
+// expire runs when the ttl of a key ends, the key is removed and reported unless it was
+// replaced in the meantime, a key already removed by Get is still reported
+func (ev *CachedVaultConfigurationProvider) expire(key string, expiresAt time.Time) {
+	ev.mutex.Lock()
+	entry, ok := ev.values[key]
+	if ok && !entry.expiresAt.Equal(expiresAt) {
+		ev.mutex.Unlock()
+		return
+	}
+	delete(ev.values, key)
+	ev.mutex.Unlock()
+
+	ev.notify([]string{key})
+}
+
+func (ev *CachedVaultConfigurationProvider) notify(keys []string) {
+	ev.mutex.RLock()
+	handlers := make([]func(keys []string), len(ev.handlers))
+	copy(handlers, ev.handlers)
+	ev.mutex.RUnlock()
+
+	for _, handler := range handlers {
+		handler(keys)
 	}
 }
@@ -0,0 +1,80 @@
+package configuration
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCachedVault_ServicesDoNotShareValues(t *testing.T) {
+	// Arrange
+	first := New().RegisterDefaults()
+	second := New().RegisterDefaults()
+
+	// Act
+	first.UpsertKey("VAULT_SCOPED", "first")
+
+	// Assert
+	assert.Equal(t, "first", first.Get("VAULT_SCOPED"))
+	assert.Nil(t, second.Get("VAULT_SCOPED"))
+	assert.NotSame(t, first.Vault(), second.Vault())
+}
+
+func TestCachedVault_KeysExpire(t *testing.T) {
+	// Arrange
+	vault := NewCachedVaultConfigurationProvider()
+	vault.UpsertKeyWithExpiry("SHORT", "value", 20*time.Millisecond)
+	vault.UpsertKey("LONG", "value")
+
+	// Act
+	before := vault.Get("SHORT")
+	time.Sleep(40 * time.Millisecond)
+
+	// Assert
+	assert.Equal(t, "value", before)
+	assert.Nil(t, vault.Get("SHORT"))
+	assert.Equal(t, []string{"LONG"}, vault.Keys())
+}
+
+func TestCachedVault_UpsertKeysSkipsInvalidEntries(t *testing.T) {
+	// Arrange
+	vault := NewCachedVaultConfigurationProvider()
+
+	// Act
+	errs := vault.UpsertKeys(map[string]interface{}{"VALID": "value", "EMPTY": ""})
+
+	// Assert
+	assert.Len(t, errs, 1)
+	assert.Equal(t, "value", vault.Get("VALID"))
+	assert.Nil(t, vault.Get("EMPTY"))
+}
+
+func TestCachedVault_ConcurrentAccess(t *testing.T) {
+	// Arrange
+	config := New().RegisterDefaults()
+	var wg sync.WaitGroup
+
+	// Act
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func(index int) {
+			defer wg.Done()
+			key := fmt.Sprintf("CONCURRENT_%d", index%5)
+			for j := 0; j < 100; j++ {
+				config.UpsertKey(key, j+1)
+				config.Get(key)
+				config.Vault().Keys()
+				if j%10 == 0 {
+					config.Clear(key)
+				}
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	// Assert
+	assert.NotNil(t, config.Get("CONCURRENT_0"))
+}
@@ -0,0 +1,103 @@
+// encrypt-value prints the enc: form of a value so it can be stored in a configuration file or
+// an environment variable.
+//
+// The value is read from the first argument or from stdin and is encrypted with the master key
+// in CJ_CONFIG_MASTER_KEY or in the file set in CJ_CONFIG_MASTER_KEY_FILE, use -public-key to
+// wrap a random data key with an RSA public key instead and -generate-key to print a new
+// master key.
+//
+//	go run ./configuration/cmd/encrypt-value [-public-key key.pem] [-generate-key] [value]
+package main
+
+import (
+	"bufio"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/cjlapao/common-go/configuration"
+)
+
+func main() {
+	publicKeyPath := flag.String("public-key", "", "PEM file with the RSA public key used to wrap the data key")
+	generateKey := flag.Bool("generate-key", false, "print a new base64 master key and exit")
+	flag.Parse()
+
+	result, err := run(*publicKeyPath, *generateKey, flag.Args(), os.Stdin)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err.Error())
+		os.Exit(1)
+	}
+
+	fmt.Println(result)
+}
+
+func run(publicKeyPath string, generateKey bool, args []string, stdin io.Reader) (string, error) {
+	if generateKey {
+		return configuration.GenerateMasterKey()
+	}
+
+	value, err := readValue(args, stdin)
+	if err != nil {
+		return "", err
+	}
+
+	if publicKeyPath != "" {
+		content, err := os.ReadFile(publicKeyPath)
+		if err != nil {
+			return "", err
+		}
+
+		publicKey, err := parseRSAPublicKey(content)
+		if err != nil {
+			return "", fmt.Errorf("%v is not a valid RSA public key: %w", publicKeyPath, err)
+		}
+
+		return configuration.EncryptValueWithPublicKey(publicKey, value)
+	}
+
+	return configuration.New().Encrypt(value)
+}
+
+// parseRSAPublicKey reads a PKIX or PKCS1 PEM public key
+func parseRSAPublicKey(content []byte) (*rsa.PublicKey, error) {
+	block, _ := pem.Decode(content)
+	if block == nil {
+		return nil, errors.New("public key is not a PEM encoded key")
+	}
+
+	if parsed, err := x509.ParsePKIXPublicKey(block.Bytes); err == nil {
+		key, ok := parsed.(*rsa.PublicKey)
+		if !ok {
+			return nil, errors.New("public key is not an RSA key")
+		}
+		return key, nil
+	}
+
+	return x509.ParsePKCS1PublicKey(block.Bytes)
+}
+
+// readValue returns the first argument or the first line of stdin
+func readValue(args []string, stdin io.Reader) (string, error) {
+	if len(args) > 0 {
+		return args[0], nil
+	}
+
+	line, err := bufio.NewReader(stdin).ReadString('\n')
+	if err != nil && !errors.Is(err, io.EOF) {
+		return "", err
+	}
+
+	value := strings.TrimRight(line, "\r\n")
+	if value == "" {
+		return "", errors.New("no value to encrypt, pass it as an argument or through stdin")
+	}
+
+	return value, nil
+}
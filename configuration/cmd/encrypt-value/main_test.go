@@ -0,0 +1,82 @@
+package main
+
+import (
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/cjlapao/common-go/configuration"
+	"github.com/cjlapao/common-go/constants"
+	"github.com/cjlapao/common-go/security/encryption"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRun_EncryptsWithTheMasterKey(t *testing.T) {
+	// Arrange
+	masterKey, _ := run("", true, nil, nil)
+	t.Setenv(constants.CONFIG_MASTER_KEY_ENVIRONMENT, masterKey)
+	t.Setenv(constants.CONFIG_MASTER_KEY_FILE_ENVIRONMENT, "")
+
+	// Act
+	fromArgs, argsErr := run("", false, []string{"s3cr3t"}, nil)
+	fromStdin, stdinErr := run("", false, nil, strings.NewReader("from stdin\n"))
+
+	// Assert
+	key, _ := base64.StdEncoding.DecodeString(masterKey)
+	assert.Len(t, key, 32)
+	assert.Nil(t, argsErr)
+	assert.Nil(t, stdinErr)
+	assert.True(t, strings.HasPrefix(fromArgs, "enc:AES256GCM:"))
+	value, err := configuration.New().Decrypt(fromArgs)
+	assert.Nil(t, err)
+	assert.Equal(t, "s3cr3t", value)
+	value, err = configuration.New().Decrypt(fromStdin)
+	assert.Nil(t, err)
+	assert.Equal(t, "from stdin", value)
+}
+
+func TestRun_EncryptsWithPKIXAndPKCS1PublicKeys(t *testing.T) {
+	// Arrange
+	privateKey, publicKey := encryption.RSAHelper{}.GenerateKeys(encryption.Bit2048)
+	pkix, _ := x509.MarshalPKIXPublicKey(publicKey)
+	directory := t.TempDir()
+	keys := map[string][]byte{
+		"pkix.pem":  pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: pkix}),
+		"pkcs1.pem": pem.EncodeToMemory(&pem.Block{Type: "RSA PUBLIC KEY", Bytes: x509.MarshalPKCS1PublicKey(publicKey)}),
+	}
+	config := configuration.New()
+	config.SetPrivateKey(privateKey)
+
+	for name, content := range keys {
+		path := filepath.Join(directory, name)
+		os.WriteFile(path, content, 0o600)
+
+		// Act
+		encrypted, err := run(path, false, []string{"wrapped"}, nil)
+
+		// Assert
+		assert.Nil(t, err, name)
+		assert.True(t, strings.HasPrefix(encrypted, "enc:RSA-AES256GCM:"), name)
+		value, err := config.Decrypt(encrypted)
+		assert.Nil(t, err, name)
+		assert.Equal(t, "wrapped", value, name)
+	}
+}
+
+func TestRun_InvalidInputReturnsError(t *testing.T) {
+	// Arrange
+	path := filepath.Join(t.TempDir(), "invalid.pem")
+	os.WriteFile(path, []byte("not a key"), 0o600)
+
+	// Act
+	_, keyErr := run(path, false, []string{"value"}, nil)
+	_, emptyErr := run("", false, nil, strings.NewReader(""))
+
+	// Assert
+	assert.NotNil(t, keyErr)
+	assert.NotNil(t, emptyErr)
+}
@@ -1,14 +1,13 @@
 package configuration
 
 import (
+	"crypto/rsa"
 	"errors"
 	"fmt"
-	"reflect"
 	"strconv"
-	"strings"
+	"sync"
 
 	"github.com/cjlapao/common-go/guard"
-	"github.com/cjlapao/common-go/helper"
 	"github.com/cjlapao/common-go/security"
 )
 
@@ -21,8 +20,25 @@ type ConfigurationProvider interface {
 
 var globalConfigurationService *ConfigurationService
 
+// ConfigurationService reads keys from the registered providers in order of precedence,
+// Providers is kept ordered by the provider priorities
 type ConfigurationService struct {
-	Providers []ConfigurationProvider
+	Providers     []ConfigurationProvider
+	mutex         sync.RWMutex
+	registrations []ProviderRegistration
+	writeTarget   ConfigurationProvider
+	fileProvider  *FileConfigurationProvider
+	sequence      int
+	masterKey     []byte
+	privateKey    *rsa.PrivateKey
+
+	definitions     map[string]KeyDefinition
+	definitionOrder []string
+
+	watchMutex      sync.Mutex
+	watchers        []*configurationWatcher
+	watchedValues   map[string]interface{}
+	watcherSequence int
 }
 
 func New() *ConfigurationService {
@@ -31,10 +47,6 @@ func New() *ConfigurationService {
 	}
 
 	globalConfigurationService = &ConfigurationService{}
-	if vault != nil {
-		vault = nil
-	}
-	vault = make(map[string]interface{})
 
 	return globalConfigurationService
 }
@@ -51,60 +63,80 @@ func Get() *ConfigurationService {
 	return NewWithDefaults()
 }
 
+// RegisterProvider Registers the providers using their default priority, the same provider
+// instance is only registered once but several instances of the same type are allowed
 func (c *ConfigurationService) RegisterProvider(providers ...ConfigurationProvider) {
 	for _, registerProvider := range providers {
-		found := false
-		for _, provider := range c.Providers {
-			if reflect.TypeOf(provider) == reflect.TypeOf(registerProvider) {
-				found = true
-				break
-			}
+		if registerProvider == nil || c.isRegistered(registerProvider) {
+			continue
 		}
-		if !found {
-			c.Providers = append(c.Providers, registerProvider)
+
+		c.RegisterProviderWithPriority(registerProvider, defaultProviderPriority(registerProvider))
+	}
+}
+
+func (c *ConfigurationService) isRegistered(provider ConfigurationProvider) bool {
+	c.mutex.RLock()
+	defer c.mutex.RUnlock()
+
+	for _, registration := range c.registrations {
+		if sameProvider(registration.Provider, provider) {
+			return true
 		}
 	}
+
+	return false
 }
 
 func (c *ConfigurationService) UpsertKey(key string, value interface{}) error {
-	if len(c.Providers) == 0 {
+	selectedProvider := c.WriteTarget()
+	if selectedProvider == nil {
 		return errors.New("no provider registered")
 	}
-	selectedProvider := c.Providers[0]
-	for _, provider := range c.Providers {
-		if reflect.TypeOf(provider) == reflect.TypeOf(CachedVaultConfigurationProvider{}) {
-			selectedProvider = provider
-			break
-		}
+
+	if err := selectedProvider.UpsertKey(key, value); err != nil {
+		return err
 	}
 
-	return selectedProvider.UpsertKey(key, value)
+	c.notifyChanged(key)
+	return nil
 }
 
 func (c *ConfigurationService) UpsertKeys(values map[string]interface{}) []error {
-	errorArray := make([]error, 0)
-	if len(c.Providers) == 0 {
-		error := errors.New("no provider registered")
-		errorArray = append(errorArray, error)
+	selectedProvider := c.WriteTarget()
+	if selectedProvider == nil {
+		return []error{errors.New("no provider registered")}
+	}
+
+	errs := selectedProvider.UpsertKeys(values)
+	keys := make([]string, 0, len(values))
+	for key := range values {
+		keys = append(keys, key)
 	}
-	return c.Providers[0].UpsertKeys(values)
+	c.notifyChanged(keys...)
+
+	return errs
 }
 
+// Get Returns the value of the key from the provider with the highest precedence, encrypted
+// values are decrypted and ${KEY} references are expanded, nil is returned if the value
+// cannot be decrypted or expanded
 func (c *ConfigurationService) Get(key string) interface{} {
-	if len(c.Providers) == 0 {
+	value, err := c.resolve(key)
+	if err != nil {
 		return nil
 	}
-	// Prioritizing the environment provider to get the keys
-	for _, provider := range c.Providers {
-		if reflect.TypeOf(provider) == reflect.TypeOf(EnvironmentConfigurationProvider{}) {
-			result := provider.Get(key)
-			if !guard.IsNill(result) {
-				return result
-			}
-		}
-	}
 
-	for _, provider := range c.Providers {
+	return value
+}
+
+// resolve reads the value of the key decrypting and expanding it when needed
+func (c *ConfigurationService) resolve(key string) (interface{}, error) {
+	return c.resolveKey(key, nil, nil)
+}
+
+func (c *ConfigurationService) lookup(key string) interface{} {
+	for _, provider := range c.providers() {
 		result := provider.Get(key)
 		if !guard.IsNill(result) {
 			return result
@@ -159,7 +191,7 @@ func (c *ConfigurationService) GetFloat(key string) float64 {
 	if isKeyEmpty == nil {
 		value, err := strconv.ParseFloat(fmt.Sprint(c.Get(key)), 64)
 		if err != nil {
-			return value
+			return 0
 		}
 
 		return value
@@ -183,27 +215,47 @@ func (c *ConfigurationService) GetBase64(key string) string {
 }
 
 func (c *ConfigurationService) Clear(key string) {
-	for _, provider := range c.Providers {
+	for _, provider := range c.providers() {
 		provider.Clear(key)
 	}
+
+	c.notifyChanged(key)
 }
 
+// LoadFromFile Loads a YAML, JSON, TOML or dotenv file into the service file provider,
+// files that cannot be read are ignored, use TryLoadFromFile to get the error
 func (c *ConfigurationService) LoadFromFile(path string) {
-	if helper.FileExists(path) {
-		content, err := helper.ReadFromFile(path)
-		if err == nil {
-			lines := strings.Split(string(content), "\n")
-			for _, line := range lines {
-				index := strings.Index(line, "=")
-				key := line[0:index]
-				key = strings.TrimLeft(key, "\"")
-				key = strings.TrimRight(key, "\"")
-
-				value := line[index+1:]
-				value = strings.TrimLeft(value, "\"")
-				value = strings.TrimRight(value, "\"")
-				c.UpsertKey(key, value)
-			}
+	_ = c.TryLoadFromFile(path)
+}
+
+// TryLoadFromFile Loads a YAML, JSON, TOML or dotenv file into the service file provider
+// returning the error, loading a file that is already in the stack reloads the stack
+func (c *ConfigurationService) TryLoadFromFile(path string) error {
+	provider := c.getFileProvider()
+	for _, file := range provider.Files() {
+		if file == path {
+			return provider.Reload()
 		}
 	}
+
+	return provider.AddFile(path)
+}
+
+// getFileProvider returns the file provider used by LoadFromFile, registering it the first
+// time so every loaded file shares the same provider
+func (c *ConfigurationService) getFileProvider() *FileConfigurationProvider {
+	c.mutex.Lock()
+	provider := c.fileProvider
+	created := provider == nil
+	if created {
+		provider = NewFileConfigurationProvider()
+		c.fileProvider = provider
+	}
+	c.mutex.Unlock()
+
+	if created {
+		c.RegisterProvider(provider)
+	}
+
+	return provider
 }
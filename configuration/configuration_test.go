@@ -15,7 +15,6 @@ func TestGetConfigurationProvider_ReturnCorrect(t *testing.T) {
 	// Arrange + Act
 	// resetting the internal values
 	globalConfigurationService = nil
-	vault = make(map[string]interface{})
 
 	config := Get()
 	key1 := config.Get("foo")
@@ -31,7 +30,6 @@ func TestGetConfigurationProvider_ResturnsSameKeyAfterReinitialization(t *testin
 	// Arrange + Act
 	// resetting the internal values
 	globalConfigurationService = nil
-	vault = make(map[string]interface{})
 
 	config := Get()
 	key1 := config.Get("foo")
@@ -52,7 +50,6 @@ func TestConfigurationProvider_IfUpsertEmptyKeyErrorIsReturned(t *testing.T) {
 	//Arrange
 	// resetting the internal values
 	globalConfigurationService = nil
-	vault = make(map[string]interface{})
 
 	config := Get()
 
@@ -67,7 +64,6 @@ func TestConfigurationProvider_IfUpsertEmptyValueErrorIsReturned(t *testing.T) {
 	//Arrange
 	// resetting the internal values
 	globalConfigurationService = nil
-	vault = make(map[string]interface{})
 
 	config := Get()
 	var emptyInterface interface{}
@@ -100,7 +96,6 @@ func TestConfigurationProvider_IfUpsertKeyValueIsStoredInVault(t *testing.T) {
 	//Arrange
 	// resetting the internal values
 	globalConfigurationService = nil
-	vault = make(map[string]interface{})
 
 	config := Get()
 	testStruct := helper.TestStructure{
@@ -139,7 +134,6 @@ func TestConfigurationProvider_GetKeyShouldReturnEnvValues(t *testing.T) {
 	//Arrange
 	// resetting the internal values
 	globalConfigurationService = nil
-	vault = make(map[string]interface{})
 	config := Get()
 	os.Setenv("foo", "bar")
 
@@ -157,7 +151,6 @@ func TestConfigurationProvider_GetKeyShouldReturnEnvValuesInsteadOfVaultValues(t
 	//Arrange
 	// resetting the internal values
 	globalConfigurationService = nil
-	vault = make(map[string]interface{})
 
 	config := Get()
 	os.Setenv("foo", "bar")
@@ -177,7 +170,6 @@ func TestConfigurationProvider_UpsertEmptyKeysShouldReturnNil(t *testing.T) {
 	//Arrange
 	// resetting the internal values
 	globalConfigurationService = nil
-	vault = make(map[string]interface{})
 
 	config := Get()
 	inserts := make(map[string]interface{})
@@ -193,7 +185,6 @@ func TestConfigurationProvider_UpsertEmptyKeysShouldReturnError(t *testing.T) {
 	//Arrange
 	// resetting the internal values
 	globalConfigurationService = nil
-	vault = make(map[string]interface{})
 
 	config := Get()
 	var inserts map[string]interface{}
@@ -210,7 +201,6 @@ func TestConfigurationProvider_UpsertKeysShouldAddArrayIntoVault(t *testing.T) {
 	//Arrange
 	// resetting the internal values
 	globalConfigurationService = nil
-	vault = make(map[string]interface{})
 
 	config := Get()
 	inserts := make(map[string]interface{})
@@ -234,7 +224,6 @@ func TestConfigurationProvider_UpsertKeysWithErrorsShouldReturnArray(t *testing.
 	//Arrange
 	// resetting the internal values
 	globalConfigurationService = nil
-	vault = make(map[string]interface{})
 
 	config := Get()
 	inserts := make(map[string]interface{})
@@ -1,7 +1,42 @@
 package configuration
 
+// RegisterDefaults Registers a cached vault owned by this service, the environment provider
+// and the command line flags, calling it again does not add them twice
 func (c *ConfigurationService) RegisterDefaults() *ConfigurationService {
-	c.RegisterProvider(CachedVaultConfigurationProvider{}, EnvironmentConfigurationProvider{})
+	if c.Vault() == nil {
+		c.RegisterProvider(NewCachedVaultConfigurationProvider())
+	}
+	c.RegisterProvider(EnvironmentConfigurationProvider{})
+	if !c.hasFlagProvider() {
+		c.RegisterProvider(NewFlagConfigurationProvider())
+	}
 
 	return c
 }
+
+// Vault Returns the cached vault registered in the service or nil if there is none
+func (c *ConfigurationService) Vault() *CachedVaultConfigurationProvider {
+	c.mutex.RLock()
+	defer c.mutex.RUnlock()
+
+	for _, provider := range c.Providers {
+		if vault, ok := provider.(*CachedVaultConfigurationProvider); ok {
+			return vault
+		}
+	}
+
+	return nil
+}
+
+func (c *ConfigurationService) hasFlagProvider() bool {
+	c.mutex.RLock()
+	defer c.mutex.RUnlock()
+
+	for _, provider := range c.Providers {
+		if _, ok := provider.(*FlagConfigurationProvider); ok {
+			return true
+		}
+	}
+
+	return false
+}
@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"os"
 	"strconv"
+	"strings"
 
 	"github.com/cjlapao/common-go/guard"
 )
@@ -86,3 +87,18 @@ func (ev EnvironmentConfigurationProvider) Clear(key string) {
 		os.Setenv(key, "")
 	}
 }
+
+func (ev EnvironmentConfigurationProvider) Priority() int {
+	return PriorityEnvironment
+}
+
+func (ev EnvironmentConfigurationProvider) Keys() []string {
+	result := make([]string, 0)
+	for _, variable := range os.Environ() {
+		if key, value, found := strings.Cut(variable, "="); found && key != "" && value != "" {
+			result = append(result, key)
+		}
+	}
+
+	return result
+}
@@ -0,0 +1,450 @@
+package configuration
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/cjlapao/common-go/constants"
+	"github.com/cjlapao/common-go/guard"
+	"github.com/cjlapao/common-go/helper"
+)
+
+type configurationFile struct {
+	path     string
+	format   FileFormat
+	optional bool
+}
+
+type configurationFileState struct {
+	modTime time.Time
+	size    int64
+	exists  bool
+}
+
+const (
+	DefaultFileWatchInterval = 2 * time.Second
+	DefaultFileWatchDebounce = 500 * time.Millisecond
+)
+
+// FileConfigurationProvider reads keys from a stack of YAML, JSON, TOML and dotenv files,
+// files added later override the keys of the ones added before them.
+// Nested documents are flattened into dotted keys, they can also be read using the
+// environment style __ separator, for example DATABASE__HOST for database.host
+type FileConfigurationProvider struct {
+	mutex     sync.RWMutex
+	loadMutex sync.Mutex
+	files     []configurationFile
+	values    map[string]interface{}
+	names     map[string]string
+	overrides map[string]interface{}
+	states    map[string]configurationFileState
+	handlers  []func(keys []string)
+	stop      chan struct{}
+	stopped   chan struct{}
+}
+
+func NewFileConfigurationProvider() *FileConfigurationProvider {
+	result := FileConfigurationProvider{
+		files:     make([]configurationFile, 0),
+		values:    make(map[string]interface{}),
+		names:     make(map[string]string),
+		overrides: make(map[string]interface{}),
+		states:    make(map[string]configurationFileState),
+	}
+
+	return &result
+}
+
+// AddFile Adds a required file to the stack, the format is detected by the extension
+func (fp *FileConfigurationProvider) AddFile(path string) error {
+	return fp.addFile(configurationFile{path: path, format: DetectFileFormat(path)})
+}
+
+// AddOptionalFile Adds a file to the stack that is ignored if it does not exist
+func (fp *FileConfigurationProvider) AddOptionalFile(path string) error {
+	return fp.addFile(configurationFile{path: path, format: DetectFileFormat(path), optional: true})
+}
+
+// AddFileWithFormat Adds a required file to the stack using an explicit format
+func (fp *FileConfigurationProvider) AddFileWithFormat(path string, format FileFormat) error {
+	if format == FileFormatAuto {
+		format = DetectFileFormat(path)
+	}
+
+	return fp.addFile(configurationFile{path: path, format: format})
+}
+
+// AddEnvironmentFiles Adds the base file and the optional environment file on top of it,
+// the environment is read from CJ_ENVIRONMENT, for example appsettings.json and
+// appsettings.Development.json
+func (fp *FileConfigurationProvider) AddEnvironmentFiles(path string) error {
+	if err := fp.AddFile(path); err != nil {
+		return err
+	}
+
+	environment := os.Getenv(constants.ENVIRONMENT)
+	if environment == "" {
+		return nil
+	}
+
+	return fp.AddOptionalFile(EnvironmentFilePath(path, environment))
+}
+
+// EnvironmentFilePath Returns the environment specific path of a file,
+// appsettings.json becomes appsettings.<environment>.json
+func EnvironmentFilePath(path string, environment string) string {
+	extension := filepath.Ext(path)
+	return strings.TrimSuffix(path, extension) + "." + environment + extension
+}
+
+// Files Returns the paths of the files in the stack
+func (fp *FileConfigurationProvider) Files() []string {
+	fp.mutex.RLock()
+	defer fp.mutex.RUnlock()
+
+	result := make([]string, len(fp.files))
+	for i, file := range fp.files {
+		result[i] = file.path
+	}
+
+	return result
+}
+
+// Reload Reads all the files in the stack again, notifying the keys that changed
+func (fp *FileConfigurationProvider) Reload() error {
+	fp.loadMutex.Lock()
+	defer fp.loadMutex.Unlock()
+
+	fp.mutex.RLock()
+	files := make([]configurationFile, len(fp.files))
+	copy(files, fp.files)
+	fp.mutex.RUnlock()
+
+	states := readConfigurationFileStates(files)
+	values, names, err := loadConfigurationFiles(files)
+	if err != nil {
+		fp.mutex.Lock()
+		fp.states = states
+		fp.mutex.Unlock()
+		return err
+	}
+
+	fp.apply(files, states, values, names)
+	return nil
+}
+
+// apply replaces the files and their values, notifying the keys that changed
+func (fp *FileConfigurationProvider) apply(files []configurationFile, states map[string]configurationFileState, values map[string]interface{}, names map[string]string) {
+	fp.mutex.Lock()
+	changed := make([]string, 0)
+	for key, value := range values {
+		if previous, ok := fp.values[key]; !ok || !reflect.DeepEqual(previous, value) {
+			changed = append(changed, fileKeyName(key, names))
+		}
+	}
+	for key := range fp.values {
+		if _, ok := values[key]; !ok {
+			changed = append(changed, fileKeyName(key, fp.names))
+		}
+	}
+
+	fp.files = files
+	fp.states = states
+	fp.values = values
+	fp.names = names
+	fp.mutex.Unlock()
+
+	if len(changed) > 0 {
+		fp.notify(changed)
+	}
+}
+
+func (fp *FileConfigurationProvider) notify(keys []string) {
+	fp.mutex.RLock()
+	handlers := make([]func(keys []string), len(fp.handlers))
+	copy(handlers, fp.handlers)
+	fp.mutex.RUnlock()
+
+	for _, handler := range handlers {
+		handler(keys)
+	}
+}
+
+// OnChange Registers a handler called with the keys that changed after a reload
+func (fp *FileConfigurationProvider) OnChange(handler func(keys []string)) {
+	fp.mutex.Lock()
+	defer fp.mutex.Unlock()
+
+	fp.handlers = append(fp.handlers, handler)
+}
+
+// Keys Returns the flattened keys of the files and the in memory keys
+func (fp *FileConfigurationProvider) Keys() []string {
+	fp.mutex.RLock()
+	defer fp.mutex.RUnlock()
+
+	result := make([]string, 0, len(fp.names)+len(fp.overrides))
+	for key, name := range fp.names {
+		if _, overridden := fp.overrides[key]; !overridden {
+			result = append(result, name)
+		}
+	}
+	for key := range fp.overrides {
+		result = append(result, fileKeyName(key, fp.names))
+	}
+
+	return result
+}
+
+// WatchFiles Starts polling the modification time of the files, reloading them when they
+// change. A reload only happens once a file stopped changing for the debounce period
+func (fp *FileConfigurationProvider) WatchFiles(interval time.Duration, debounce time.Duration) {
+	if interval <= 0 {
+		interval = DefaultFileWatchInterval
+	}
+	if debounce < 0 {
+		debounce = DefaultFileWatchDebounce
+	}
+
+	fp.StopWatching()
+
+	fp.mutex.Lock()
+	stop := make(chan struct{})
+	stopped := make(chan struct{})
+	fp.stop = stop
+	fp.stopped = stopped
+	fp.mutex.Unlock()
+
+	go fp.watchFiles(interval, debounce, stop, stopped)
+}
+
+// StopWatching Stops polling the files for changes
+func (fp *FileConfigurationProvider) StopWatching() {
+	fp.mutex.Lock()
+	stop := fp.stop
+	stopped := fp.stopped
+	fp.stop = nil
+	fp.stopped = nil
+	fp.mutex.Unlock()
+
+	if stop != nil {
+		close(stop)
+		<-stopped
+	}
+}
+
+func (fp *FileConfigurationProvider) watchFiles(interval time.Duration, debounce time.Duration, stop chan struct{}, stopped chan struct{}) {
+	defer close(stopped)
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	var observed map[string]configurationFileState
+	var changedAt time.Time
+	pending := false
+
+	for {
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+			fp.mutex.RLock()
+			files := make([]configurationFile, len(fp.files))
+			copy(files, fp.files)
+			loaded := fp.states
+			fp.mutex.RUnlock()
+
+			current := readConfigurationFileStates(files)
+			if !reflect.DeepEqual(current, loaded) {
+				if !pending || !reflect.DeepEqual(current, observed) {
+					pending = true
+					observed = current
+					changedAt = time.Now()
+				}
+			} else {
+				pending = false
+			}
+
+			if pending && time.Since(changedAt) >= debounce {
+				pending = false
+				fp.Reload()
+			}
+		}
+	}
+}
+
+func readConfigurationFileStates(files []configurationFile) map[string]configurationFileState {
+	result := make(map[string]configurationFileState, len(files))
+	for _, file := range files {
+		info, err := os.Stat(file.path)
+		if err != nil {
+			result[file.path] = configurationFileState{}
+			continue
+		}
+
+		result[file.path] = configurationFileState{
+			modTime: info.ModTime(),
+			size:    info.Size(),
+			exists:  true,
+		}
+	}
+
+	return result
+}
+
+func fileKeyName(key string, names map[string]string) string {
+	if name, ok := names[key]; ok {
+		return name
+	}
+
+	return key
+}
+
+func (fp *FileConfigurationProvider) addFile(file configurationFile) error {
+	if err := guard.EmptyOrNil(file.path, "path"); err != nil {
+		return err
+	}
+
+	fp.loadMutex.Lock()
+	defer fp.loadMutex.Unlock()
+
+	// the file is only added to the stack once every file of the new stack loads
+	fp.mutex.RLock()
+	files := make([]configurationFile, len(fp.files), len(fp.files)+1)
+	copy(files, fp.files)
+	fp.mutex.RUnlock()
+	files = append(files, file)
+
+	values, names, err := loadConfigurationFiles(files)
+	if err != nil {
+		return err
+	}
+
+	fp.apply(files, readConfigurationFileStates(files), values, names)
+	return nil
+}
+
+func loadConfigurationFiles(files []configurationFile) (map[string]interface{}, map[string]string, error) {
+	values := make(map[string]interface{})
+	names := make(map[string]string)
+
+	for _, file := range files {
+		if !helper.FileExists(file.path) {
+			if file.optional {
+				continue
+			}
+			return nil, nil, fmt.Errorf("configuration file %v was not found", file.path)
+		}
+
+		content, err := helper.ReadFromFile(file.path)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		document, err := parseConfigurationContent(content, file.format)
+		if err != nil {
+			return nil, nil, fmt.Errorf("error parsing configuration file %v: %w", file.path, err)
+		}
+
+		flattened := make(map[string]interface{})
+		leaves := make(map[string]bool)
+		flattenDocument("", document, flattened, leaves)
+		for key, value := range flattened {
+			normalized := normalizeFileKey(key)
+			values[normalized] = value
+			if leaves[key] {
+				names[normalized] = key
+			}
+		}
+	}
+
+	return values, names, nil
+}
+
+// UpsertKey Stores the key in memory on top of the file values, files are never written
+func (fp *FileConfigurationProvider) UpsertKey(key string, value interface{}) error {
+	emptyKey := guard.EmptyOrNil(key, "key")
+	emptyValue := guard.EmptyOrNil(value, "value")
+
+	if emptyKey != nil {
+		return emptyKey
+	}
+
+	if emptyValue != nil {
+		return emptyValue
+	}
+
+	fp.mutex.Lock()
+	fp.overrides[normalizeFileKey(key)] = value
+	fp.mutex.Unlock()
+
+	fp.notify([]string{key})
+	return nil
+}
+
+func (fp *FileConfigurationProvider) UpsertKeys(values map[string]interface{}) []error {
+	errorArray := make([]error, 0)
+
+	if values == nil {
+		errorArray = append(errorArray, errors.New("array is nil"))
+		return errorArray
+	}
+
+	if len(values) > 0 {
+		for key, value := range values {
+			if err := fp.UpsertKey(key, value); err != nil {
+				errorArray = append(errorArray, err)
+			}
+		}
+
+		return errorArray
+	}
+
+	return nil
+}
+
+func (fp *FileConfigurationProvider) Get(key string) interface{} {
+	normalized := normalizeFileKey(key)
+
+	fp.mutex.RLock()
+	defer fp.mutex.RUnlock()
+
+	if value, ok := fp.overrides[normalized]; ok {
+		return value
+	}
+
+	if value, ok := fp.values[normalized]; ok {
+		return value
+	}
+
+	return nil
+}
+
+func (fp *FileConfigurationProvider) Clear(key string) {
+	emptyKey := guard.EmptyOrNil(key, "key")
+
+	if emptyKey == nil {
+		normalized := normalizeFileKey(key)
+		fp.mutex.Lock()
+		delete(fp.overrides, normalized)
+		delete(fp.values, normalized)
+		delete(fp.names, normalized)
+		fp.mutex.Unlock()
+
+		fp.notify([]string{key})
+	}
+}
+
+func (fp *FileConfigurationProvider) Priority() int {
+	return PriorityFile
+}
+
+func (fp *FileConfigurationProvider) Name() string {
+	return "File(" + strings.Join(fp.Files(), ", ") + ")"
+}
@@ -0,0 +1,188 @@
+package configuration
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// FileFormat defines the supported configuration file formats
+type FileFormat string
+
+const (
+	FileFormatAuto   FileFormat = ""
+	FileFormatJson   FileFormat = "json"
+	FileFormatYaml   FileFormat = "yaml"
+	FileFormatToml   FileFormat = "toml"
+	FileFormatDotEnv FileFormat = "dotenv"
+)
+
+// DetectFileFormat Detects the file format using the file extension, files without a known
+// extension are parsed as dotenv files
+func DetectFileFormat(path string) FileFormat {
+	name := strings.ToLower(filepath.Base(path))
+	switch filepath.Ext(name) {
+	case ".json":
+		return FileFormatJson
+	case ".yaml", ".yml":
+		return FileFormatYaml
+	case ".toml":
+		return FileFormatToml
+	default:
+		return FileFormatDotEnv
+	}
+}
+
+// parseConfigurationContent parses the content into a nested document
+func parseConfigurationContent(content []byte, format FileFormat) (map[string]interface{}, error) {
+	switch format {
+	case FileFormatJson:
+		result := make(map[string]interface{})
+		if len(bytes.TrimSpace(content)) == 0 {
+			return result, nil
+		}
+		decoder := json.NewDecoder(bytes.NewReader(content))
+		decoder.UseNumber()
+		if err := decoder.Decode(&result); err != nil {
+			return nil, err
+		}
+		return result, nil
+	case FileFormatYaml:
+		result := make(map[string]interface{})
+		if err := yaml.Unmarshal(content, &result); err != nil {
+			return nil, err
+		}
+		return result, nil
+	case FileFormatToml:
+		return parseToml(content)
+	case FileFormatDotEnv:
+		return parseDotEnv(content)
+	default:
+		return nil, fmt.Errorf("unsupported file format %v", format)
+	}
+}
+
+// parseDotEnv parses KEY=VALUE lines, supporting comments, the export keyword and quoted values
+func parseDotEnv(content []byte) (map[string]interface{}, error) {
+	result := make(map[string]interface{})
+	lines := strings.Split(strings.ReplaceAll(string(content), "\r\n", "\n"), "\n")
+
+	for i := 0; i < len(lines); i++ {
+		line := strings.TrimSpace(lines[i])
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		line = strings.TrimPrefix(line, "export ")
+		index := strings.Index(line, "=")
+		if index <= 0 {
+			return nil, fmt.Errorf("dotenv: line %d: expected KEY=VALUE", i+1)
+		}
+
+		key := strings.Trim(strings.TrimSpace(line[0:index]), "\"")
+		value := strings.TrimSpace(line[index+1:])
+
+		switch {
+		case strings.HasPrefix(value, "\""):
+			// double quoted values can span several lines and support escapes
+			raw := value[1:]
+			for !hasClosingQuote(raw, '"') {
+				i++
+				if i >= len(lines) {
+					return nil, fmt.Errorf("dotenv: key %v: unterminated quoted value", key)
+				}
+				raw += "\n" + lines[i]
+			}
+			end := closingQuoteIndex(raw, '"')
+			value = unescapeDotEnv(raw[:end])
+		case strings.HasPrefix(value, "'"):
+			end := strings.Index(value[1:], "'")
+			if end < 0 {
+				return nil, fmt.Errorf("dotenv: line %d: unterminated quoted value", i+1)
+			}
+			value = value[1 : end+1]
+		default:
+			if comment := strings.Index(value, " #"); comment >= 0 {
+				value = strings.TrimSpace(value[:comment])
+			}
+		}
+
+		result[key] = value
+	}
+
+	return result, nil
+}
+
+func hasClosingQuote(value string, quote byte) bool {
+	return closingQuoteIndex(value, quote) >= 0
+}
+
+func closingQuoteIndex(value string, quote byte) int {
+	for i := 0; i < len(value); i++ {
+		if value[i] == '\\' {
+			i++
+			continue
+		}
+		if value[i] == quote {
+			return i
+		}
+	}
+
+	return -1
+}
+
+func unescapeDotEnv(value string) string {
+	replacer := strings.NewReplacer(`\n`, "\n", `\r`, "\r", `\t`, "\t", `\"`, "\"", `\\`, "\\")
+	return replacer.Replace(value)
+}
+
+// flattenDocument flattens a nested document into dotted keys, arrays are indexed by position.
+// Container keys are kept as well so whole sections can be read at once.
+func flattenDocument(prefix string, value interface{}, result map[string]interface{}, leaves map[string]bool) {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		if prefix != "" {
+			result[prefix] = v
+		}
+		for key, item := range v {
+			flattenDocument(joinFileKey(prefix, key), item, result, leaves)
+		}
+	case map[interface{}]interface{}:
+		converted := make(map[string]interface{}, len(v))
+		for key, item := range v {
+			converted[fmt.Sprint(key)] = item
+		}
+		flattenDocument(prefix, converted, result, leaves)
+	case []interface{}:
+		if prefix != "" {
+			result[prefix] = v
+		}
+		for index, item := range v {
+			flattenDocument(joinFileKey(prefix, fmt.Sprint(index)), item, result, leaves)
+		}
+	default:
+		if prefix == "" {
+			return
+		}
+		result[prefix] = v
+		leaves[prefix] = true
+	}
+}
+
+func joinFileKey(prefix string, key string) string {
+	if prefix == "" {
+		return key
+	}
+
+	return prefix + "." + key
+}
+
+// normalizeFileKey makes file keys case insensitive and maps the environment
+// style __ separator to the dotted form, so DATABASE__HOST matches database.host
+func normalizeFileKey(key string) string {
+	return strings.ToLower(strings.ReplaceAll(key, "__", "."))
+}
@@ -0,0 +1,245 @@
+package configuration
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/cjlapao/common-go/constants"
+	"github.com/stretchr/testify/assert"
+)
+
+func writeTestFile(t *testing.T, name string, content string) string {
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatalf("failed to write test file: %v", err)
+	}
+
+	return path
+}
+
+func TestFileProvider_ParsesAllFormats(t *testing.T) {
+	var tests = []struct {
+		name    string
+		content string
+	}{
+		{"appsettings.json", `{"database": {"host": "localhost", "port": 5432}, "features": ["a", "b"]}`},
+		{"appsettings.yaml", "database:\n  host: localhost\n  port: 5432\nfeatures:\n  - a\n  - b\n"},
+		{"appsettings.toml", "features = [\"a\", \"b\"]\n\n[database]\nhost = \"localhost\" # the host\nport = 5432\n"},
+		{".env", "# comment\nexport DATABASE__HOST=localhost\nDATABASE__PORT=\"5432\"\nFEATURES__0=a\nFEATURES__1='b'\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			provider := NewFileConfigurationProvider()
+
+			// Act
+			err := provider.AddFile(writeTestFile(t, tt.name, tt.content))
+
+			// Assert
+			assert.Nil(t, err)
+			assert.Equal(t, "localhost", provider.Get("database.host"))
+			assert.Equal(t, "localhost", provider.Get("DATABASE__HOST"))
+			assert.Equal(t, "5432", fmt.Sprint(provider.Get("Database.Port")))
+			assert.Equal(t, "a", provider.Get("features.0"))
+			assert.Equal(t, "b", provider.Get("FEATURES__1"))
+		})
+	}
+}
+
+func TestFileProvider_LaterFilesOverrideEarlierOnes(t *testing.T) {
+	// Arrange
+	provider := NewFileConfigurationProvider()
+	base := writeTestFile(t, "base.json", `{"logging": {"level": "info", "format": "text"}}`)
+	override := writeTestFile(t, "override.yaml", "logging:\n  level: debug\n")
+
+	// Act
+	baseErr := provider.AddFile(base)
+	overrideErr := provider.AddFile(override)
+
+	// Assert
+	assert.Nil(t, baseErr)
+	assert.Nil(t, overrideErr)
+	assert.Equal(t, "debug", provider.Get("logging.level"))
+	assert.Equal(t, "text", provider.Get("logging.format"))
+}
+
+func TestFileProvider_AddEnvironmentFilesUsesEnvironmentVariable(t *testing.T) {
+	// Arrange
+	directory := t.TempDir()
+	base := filepath.Join(directory, "appsettings.json")
+	os.WriteFile(base, []byte(`{"name": "base", "level": "info"}`), 0o600)
+	os.WriteFile(filepath.Join(directory, "appsettings.Development.json"), []byte(`{"level": "debug"}`), 0o600)
+	t.Setenv(constants.ENVIRONMENT, "Development")
+	provider := NewFileConfigurationProvider()
+
+	// Act
+	err := provider.AddEnvironmentFiles(base)
+
+	// Assert
+	assert.Nil(t, err)
+	assert.Equal(t, "base", provider.Get("name"))
+	assert.Equal(t, "debug", provider.Get("level"))
+	assert.Len(t, provider.Files(), 2)
+}
+
+func TestFileProvider_MissingFiles(t *testing.T) {
+	// Arrange
+	provider := NewFileConfigurationProvider()
+	missing := filepath.Join(t.TempDir(), "missing.json")
+
+	// Act
+	optionalErr := provider.AddOptionalFile(missing)
+	requiredErr := NewFileConfigurationProvider().AddFile(missing)
+
+	// Assert
+	assert.Nil(t, optionalErr)
+	assert.NotNil(t, requiredErr)
+}
+
+func TestFileProvider_InvalidDotEnvLineReturnsError(t *testing.T) {
+	// Arrange
+	provider := NewFileConfigurationProvider()
+	path := writeTestFile(t, "invalid.env", "FOO=bar\nNOT_A_PAIR\n")
+
+	// Act
+	err := provider.AddFile(path)
+
+	// Assert
+	assert.NotNil(t, err)
+	assert.Contains(t, err.Error(), "line 2")
+}
+
+func TestFileProvider_FailedAddFileKeepsTheStack(t *testing.T) {
+	// Arrange
+	provider := NewFileConfigurationProvider()
+	valid := writeTestFile(t, "valid.json", `{"name": "api"}`)
+	invalid := writeTestFile(t, "invalid.env", "NOT_A_PAIR\n")
+	other := writeTestFile(t, "other.json", `{"port": "8080"}`)
+	provider.AddFile(valid)
+
+	// Act
+	invalidErr := provider.AddFile(invalid)
+	otherErr := provider.AddFile(other)
+	reloadErr := provider.Reload()
+
+	// Assert
+	assert.NotNil(t, invalidErr)
+	assert.Nil(t, otherErr)
+	assert.Nil(t, reloadErr)
+	assert.Equal(t, []string{valid, other}, provider.Files())
+	assert.Equal(t, "api", provider.Get("name"))
+	assert.Equal(t, "8080", provider.Get("port"))
+}
+
+func TestFileProvider_UpsertAndClearDoNotTouchFiles(t *testing.T) {
+	// Arrange
+	provider := NewFileConfigurationProvider()
+	provider.AddFile(writeTestFile(t, "settings.yml", "foo: bar\n"))
+
+	// Act
+	upsertErr := provider.UpsertKey("foo", "override")
+	overridden := provider.Get("foo")
+	provider.Clear("foo")
+
+	// Assert
+	assert.Nil(t, upsertErr)
+	assert.Equal(t, "override", overridden)
+	assert.Nil(t, provider.Get("foo"))
+}
+
+func TestParseToml(t *testing.T) {
+	// Arrange
+	content := `
+title = "TOML \"Example\""
+literal = 'C:\path'
+multiline = """
+first \
+  second"""
+hex = 0xff
+big = 1_000_000
+pi = 3.14
+enabled = true
+date = 1979-05-27T07:32:00Z
+numbers = [
+  1,
+  2, # two
+]
+point = { x = 1, y = 2 }
+site."google.com" = true
+
+[servers.alpha]
+ip = "10.0.0.1"
+
+[[products]]
+name = "Hammer"
+
+[[products]]
+name = "Nail"
+`
+
+	// Act
+	document, err := parseToml([]byte(content))
+
+	// Assert
+	assert.Nil(t, err)
+	assert.Equal(t, `TOML "Example"`, document["title"])
+	assert.Equal(t, `C:\path`, document["literal"])
+	assert.Equal(t, "first second", document["multiline"])
+	assert.Equal(t, int64(255), document["hex"])
+	assert.Equal(t, int64(1000000), document["big"])
+	assert.Equal(t, 3.14, document["pi"])
+	assert.Equal(t, true, document["enabled"])
+	assert.Equal(t, "1979-05-27T07:32:00Z", document["date"])
+	assert.Equal(t, []interface{}{int64(1), int64(2)}, document["numbers"])
+	assert.Equal(t, map[string]interface{}{"x": int64(1), "y": int64(2)}, document["point"])
+	assert.Equal(t, map[string]interface{}{"google.com": true}, document["site"])
+	assert.Equal(t, "10.0.0.1", document["servers"].(map[string]interface{})["alpha"].(map[string]interface{})["ip"])
+	assert.Len(t, document["products"], 2)
+}
+
+func TestParseToml_DuplicateKeyReturnsError(t *testing.T) {
+	// Act
+	_, err := parseToml([]byte("foo = 1\nfoo = 2\n"))
+
+	// Assert
+	assert.NotNil(t, err)
+	assert.Contains(t, err.Error(), "line 2")
+}
+
+func TestLoadFromFile_RegistersFileProvider(t *testing.T) {
+	// Arrange
+	config := New().RegisterDefaults()
+	path := writeTestFile(t, "settings.env", "FILE_PROVIDER_KEY=bar\n")
+
+	// Act
+	err := config.TryLoadFromFile(path)
+	missingErr := config.TryLoadFromFile(filepath.Join(t.TempDir(), "missing.env"))
+	config.LoadFromFile(filepath.Join(t.TempDir(), "ignored.env"))
+
+	// Assert
+	assert.Nil(t, err)
+	assert.NotNil(t, missingErr)
+	assert.Equal(t, "bar", config.GetString("FILE_PROVIDER_KEY"))
+}
+
+func TestLoadFromFile_ReusesTheFileProvider(t *testing.T) {
+	// Arrange
+	config := New()
+	first := writeTestFile(t, "first.env", "FIRST_KEY=one\nSHARED_KEY=first\n")
+	second := writeTestFile(t, "second.env", "SHARED_KEY=second\n")
+
+	// Act
+	config.LoadFromFile(first)
+	config.LoadFromFile(second)
+	os.WriteFile(first, []byte("FIRST_KEY=reloaded\n"), 0o600)
+	config.LoadFromFile(first)
+
+	// Assert
+	assert.Len(t, config.providers(), 1)
+	assert.Equal(t, []string{first, second}, config.fileProvider.Files())
+	assert.Equal(t, "reloaded", config.GetString("FIRST_KEY"))
+	assert.Equal(t, "second", config.GetString("SHARED_KEY"))
+}
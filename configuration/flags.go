@@ -0,0 +1,175 @@
+package configuration
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+
+	"github.com/cjlapao/common-go/guard"
+)
+
+const (
+	FlagPrefix         = "--"
+	FlagValueSeparator = "="
+)
+
+// FlagConfigurationProvider reads the command line flags, --db-host=localhost is read as the
+// DB_HOST key and a switch like --verbose is read as "true". Values must use = so the argument
+// after a switch stays a positional argument, repeated flags are joined with commas and
+// parsing stops at a bare --
+type FlagConfigurationProvider struct {
+	mutex  sync.RWMutex
+	values map[string]string
+}
+
+// NewFlagConfigurationProvider Creates a provider with the flags of the current process
+func NewFlagConfigurationProvider() *FlagConfigurationProvider {
+	args := make([]string, 0)
+	if len(os.Args) > 1 {
+		args = os.Args[1:]
+	}
+
+	return NewFlagConfigurationProviderFromArgs(args)
+}
+
+// NewFlagConfigurationProviderFromArgs Creates a provider with the given arguments, the
+// program name should not be included
+func NewFlagConfigurationProviderFromArgs(args []string) *FlagConfigurationProvider {
+	return &FlagConfigurationProvider{
+		values: parseFlags(args),
+	}
+}
+
+func (fp *FlagConfigurationProvider) UpsertKey(key string, value interface{}) error {
+	emptyKey := guard.EmptyOrNil(key, "key")
+	emptyValue := guard.EmptyOrNil(value, "value")
+
+	if emptyKey != nil {
+		return emptyKey
+	}
+
+	if emptyValue != nil {
+		return emptyValue
+	}
+
+	fp.mutex.Lock()
+	defer fp.mutex.Unlock()
+	fp.values[flagKey(key)] = toFlagValue(value)
+
+	return nil
+}
+
+func (fp *FlagConfigurationProvider) UpsertKeys(values map[string]interface{}) []error {
+	errorArray := make([]error, 0)
+
+	if values == nil {
+		errorArray = append(errorArray, errors.New("array is nil"))
+		return errorArray
+	}
+
+	for key, value := range values {
+		if err := fp.UpsertKey(key, value); err != nil {
+			errorArray = append(errorArray, err)
+		}
+	}
+
+	return errorArray
+}
+
+func (fp *FlagConfigurationProvider) Get(key string) interface{} {
+	fp.mutex.RLock()
+	defer fp.mutex.RUnlock()
+
+	if value, ok := fp.values[flagKey(key)]; ok {
+		return value
+	}
+
+	return nil
+}
+
+func (fp *FlagConfigurationProvider) Clear(key string) {
+	fp.mutex.Lock()
+	defer fp.mutex.Unlock()
+	delete(fp.values, flagKey(key))
+}
+
+func (fp *FlagConfigurationProvider) Keys() []string {
+	fp.mutex.RLock()
+	defer fp.mutex.RUnlock()
+
+	result := make([]string, 0, len(fp.values))
+	for key := range fp.values {
+		result = append(result, key)
+	}
+
+	return result
+}
+
+func (fp *FlagConfigurationProvider) Priority() int {
+	return PriorityFlags
+}
+
+func (fp *FlagConfigurationProvider) Name() string {
+	return "Flags"
+}
+
+func parseFlags(args []string) map[string]string {
+	result := make(map[string]string)
+	for _, arg := range args {
+		if arg == FlagPrefix {
+			break
+		}
+		if !strings.HasPrefix(arg, FlagPrefix) {
+			continue
+		}
+
+		name, value, hasValue := strings.Cut(strings.TrimPrefix(arg, FlagPrefix), FlagValueSeparator)
+		if name == "" {
+			continue
+		}
+		if !hasValue {
+			value = "true"
+		}
+
+		key := flagKey(name)
+		value = unquoteFlagValue(value)
+		if existing, ok := result[key]; ok {
+			value = existing + "," + value
+		}
+		result[key] = value
+	}
+
+	return result
+}
+
+// flagKey maps a flag name to a configuration key, db-host becomes DB_HOST
+func flagKey(name string) string {
+	name = strings.ToUpper(strings.TrimSpace(name))
+	return strings.NewReplacer("-", "_", ".", "_").Replace(name)
+}
+
+func unquoteFlagValue(value string) string {
+	if len(value) >= 2 {
+		if (value[0] == '"' && value[len(value)-1] == '"') || (value[0] == '\'' && value[len(value)-1] == '\'') {
+			return value[1 : len(value)-1]
+		}
+	}
+
+	return value
+}
+
+func toFlagValue(value interface{}) string {
+	if text, ok := value.(string); ok {
+		return text
+	}
+
+	items := splitConfigList(value)
+	values := make([]string, len(items))
+	for i, item := range items {
+		values[i] = strings.TrimSpace(fmt.Sprint(item))
+	}
+
+	return strings.Join(values, ",")
+}
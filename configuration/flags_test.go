@@ -0,0 +1,44 @@
+package configuration
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFlagProvider_ParsesFlags(t *testing.T) {
+	// Arrange
+	args := []string{"serve", "--db-host=db.local", "--db.port=5432", "--verbose", "input.txt", "--tag=a", "--tag='b'", "--name=\"my service\"", "--", "--ignored=true"}
+
+	// Act
+	provider := NewFlagConfigurationProviderFromArgs(args)
+
+	// Assert
+	assert.Equal(t, "db.local", provider.Get("DB_HOST"))
+	assert.Equal(t, "db.local", provider.Get("db-host"))
+	assert.Equal(t, "5432", provider.Get("DB_PORT"))
+	assert.Equal(t, "true", provider.Get("VERBOSE"))
+	assert.Equal(t, "a,b", provider.Get("TAG"))
+	assert.Equal(t, "my service", provider.Get("NAME"))
+	assert.Nil(t, provider.Get("IGNORED"))
+	assert.ElementsMatch(t, []string{"DB_HOST", "DB_PORT", "VERBOSE", "TAG", "NAME"}, provider.Keys())
+}
+
+func TestFlagProvider_HasPrecedenceOverEnvironment(t *testing.T) {
+	// Arrange
+	t.Setenv("FLAG_DB_HOST", "env.local")
+	t.Setenv("FLAG_ONLY_ENV", "env")
+	config := New().RegisterDefaults()
+	config.RegisterProvider(NewFlagConfigurationProviderFromArgs([]string{"--flag-db-host=flag.local", "--flag-debug"}))
+
+	// Act
+	host := config.GetString("FLAG_DB_HOST")
+	debug, err := config.TryGetBool("FLAG_DEBUG")
+
+	// Assert
+	assert.Equal(t, "flag.local", host)
+	assert.Equal(t, "env", config.GetString("FLAG_ONLY_ENV"))
+	assert.Nil(t, err)
+	assert.True(t, debug)
+	assert.Equal(t, "Flags", config.ProviderRegistrations()[0].Name)
+}
@@ -0,0 +1,303 @@
+package configuration
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/cjlapao/common-go/guard"
+)
+
+var (
+	stringType      = reflect.TypeOf("")
+	intType         = reflect.TypeOf(0)
+	boolType        = reflect.TypeOf(false)
+	floatType       = reflect.TypeOf(float64(0))
+	stringSliceType = reflect.TypeOf([]string{})
+	stringMapType   = reflect.TypeOf(map[string]string{})
+)
+
+// TryGetString Returns the value of the key as a string, a KeyError wrapping ErrKeyNotFound
+// is returned if the key is missing
+func (c *ConfigurationService) TryGetString(key string) (string, error) {
+	value, err := c.tryGet(key, stringType)
+	if err != nil {
+		return "", err
+	}
+
+	return value.String(), nil
+}
+
+func (c *ConfigurationService) GetStringOrDefault(key string, defaultValue string) string {
+	if value, err := c.TryGetString(key); err == nil {
+		return value
+	}
+
+	return defaultValue
+}
+
+func (c *ConfigurationService) MustGetString(key string) string {
+	value, err := c.TryGetString(key)
+	if err != nil {
+		panic(err)
+	}
+
+	return value
+}
+
+// TryGetInt Returns the value of the key as an int, a KeyError wrapping ErrKeyNotFound or
+// ErrInvalidValue is returned if the key is missing or is not an integer
+func (c *ConfigurationService) TryGetInt(key string) (int, error) {
+	value, err := c.tryGet(key, intType)
+	if err != nil {
+		return 0, err
+	}
+
+	return int(value.Int()), nil
+}
+
+func (c *ConfigurationService) GetIntOrDefault(key string, defaultValue int) int {
+	if value, err := c.TryGetInt(key); err == nil {
+		return value
+	}
+
+	return defaultValue
+}
+
+func (c *ConfigurationService) MustGetInt(key string) int {
+	value, err := c.TryGetInt(key)
+	if err != nil {
+		panic(err)
+	}
+
+	return value
+}
+
+// TryGetBool Returns the value of the key as a bool, a KeyError wrapping ErrKeyNotFound or
+// ErrInvalidValue is returned if the key is missing or is not a boolean
+func (c *ConfigurationService) TryGetBool(key string) (bool, error) {
+	value, err := c.tryGet(key, boolType)
+	if err != nil {
+		return false, err
+	}
+
+	return value.Bool(), nil
+}
+
+func (c *ConfigurationService) GetBoolOrDefault(key string, defaultValue bool) bool {
+	if value, err := c.TryGetBool(key); err == nil {
+		return value
+	}
+
+	return defaultValue
+}
+
+func (c *ConfigurationService) MustGetBool(key string) bool {
+	value, err := c.TryGetBool(key)
+	if err != nil {
+		panic(err)
+	}
+
+	return value
+}
+
+// TryGetFloat Returns the value of the key as a float64, a KeyError wrapping ErrKeyNotFound or
+// ErrInvalidValue is returned if the key is missing or is not a number
+func (c *ConfigurationService) TryGetFloat(key string) (float64, error) {
+	value, err := c.tryGet(key, floatType)
+	if err != nil {
+		return 0, err
+	}
+
+	return value.Float(), nil
+}
+
+func (c *ConfigurationService) GetFloatOrDefault(key string, defaultValue float64) float64 {
+	if value, err := c.TryGetFloat(key); err == nil {
+		return value
+	}
+
+	return defaultValue
+}
+
+func (c *ConfigurationService) MustGetFloat(key string) float64 {
+	value, err := c.TryGetFloat(key)
+	if err != nil {
+		panic(err)
+	}
+
+	return value
+}
+
+// GetDuration Returns the value of the key as a duration, plain integers are read as seconds
+// and other values use the time.ParseDuration format, for example 1m30s
+func (c *ConfigurationService) GetDuration(key string) (time.Duration, error) {
+	value, err := c.tryGet(key, timeDurationType)
+	if err != nil {
+		return 0, err
+	}
+
+	return time.Duration(value.Int()), nil
+}
+
+func (c *ConfigurationService) GetDurationOrDefault(key string, defaultValue time.Duration) time.Duration {
+	if value, err := c.GetDuration(key); err == nil {
+		return value
+	}
+
+	return defaultValue
+}
+
+// GetStringSlice Returns the value of the key as a list, string values are split by commas
+func (c *ConfigurationService) GetStringSlice(key string) ([]string, error) {
+	value, err := c.tryGet(key, stringSliceType)
+	if err != nil {
+		return nil, err
+	}
+
+	return value.Interface().([]string), nil
+}
+
+// GetStringMap Returns the value of the key as a map, the value can be a map from a file,
+// a JSON object or a comma separated list of key=value pairs
+func (c *ConfigurationService) GetStringMap(key string) (map[string]string, error) {
+	raw, err := c.tryGetRaw(key)
+	if err != nil {
+		return nil, err
+	}
+
+	if text, ok := raw.(string); ok {
+		text = strings.TrimSpace(text)
+		if strings.HasPrefix(text, "{") {
+			result := make(map[string]interface{})
+			if err := json.Unmarshal([]byte(text), &result); err != nil {
+				return nil, invalidKeyError(key, err)
+			}
+			raw = result
+		} else {
+			result := make(map[string]interface{})
+			for _, pair := range strings.Split(text, ",") {
+				if strings.TrimSpace(pair) == "" {
+					continue
+				}
+				name, value, found := strings.Cut(pair, "=")
+				if !found {
+					return nil, invalidKeyError(key, fmt.Errorf("expected key=value, got %q", pair))
+				}
+				result[strings.TrimSpace(name)] = strings.TrimSpace(value)
+			}
+			raw = result
+		}
+	}
+
+	value, err := convertConfigValue(raw, stringMapType)
+	if err != nil {
+		return nil, invalidKeyError(key, err)
+	}
+
+	return value.Interface().(map[string]string), nil
+}
+
+// GetURL Returns the value of the key as an absolute url
+func (c *ConfigurationService) GetURL(key string) (*url.URL, error) {
+	text, err := c.TryGetString(key)
+	if err != nil {
+		return nil, err
+	}
+
+	result, err := url.Parse(strings.TrimSpace(text))
+	if err != nil {
+		return nil, invalidKeyError(key, err)
+	}
+	if result.Scheme == "" || result.Host == "" {
+		return nil, invalidKeyError(key, fmt.Errorf("url %q is not absolute", text))
+	}
+
+	return result, nil
+}
+
+// GetTime Returns the value of the key as a time, values use the RFC3339 format or
+// the 2006-01-02 date format
+func (c *ConfigurationService) GetTime(key string) (time.Time, error) {
+	text, err := c.TryGetString(key)
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	text = strings.TrimSpace(text)
+	if result, err := time.Parse(time.RFC3339, text); err == nil {
+		return result, nil
+	}
+	if result, err := time.Parse(time.DateOnly, text); err == nil {
+		return result, nil
+	}
+
+	return time.Time{}, invalidKeyError(key, fmt.Errorf("invalid time %q", text))
+}
+
+// GetJSON Decodes the value of the key into the destination, the value can be a JSON string
+// or a structured value read from a file
+func (c *ConfigurationService) GetJSON(key string, destination interface{}) error {
+	raw, err := c.tryGetRaw(key)
+	if err != nil {
+		return err
+	}
+
+	var content []byte
+	switch value := raw.(type) {
+	case string:
+		content = []byte(value)
+	case []byte:
+		content = value
+	default:
+		content, err = json.Marshal(value)
+		if err != nil {
+			return invalidKeyError(key, err)
+		}
+	}
+
+	if err := json.Unmarshal(content, destination); err != nil {
+		return invalidKeyError(key, err)
+	}
+
+	return nil
+}
+
+// tryGetRaw returns the resolved value of the key or a KeyError if it is missing
+// or cannot be resolved
+func (c *ConfigurationService) tryGetRaw(key string) (interface{}, error) {
+	if err := guard.EmptyOrNil(key, "key"); err != nil {
+		return nil, err
+	}
+
+	value, err := c.resolve(key)
+	if err != nil {
+		return nil, KeyError{Key: key, Err: err}
+	}
+	if guard.IsNill(value) {
+		return nil, KeyError{Key: key, Err: ErrKeyNotFound}
+	}
+
+	return value, nil
+}
+
+func (c *ConfigurationService) tryGet(key string, targetType reflect.Type) (reflect.Value, error) {
+	raw, err := c.tryGetRaw(key)
+	if err != nil {
+		return reflect.Value{}, err
+	}
+
+	value, err := convertConfigValue(raw, targetType)
+	if err != nil {
+		return reflect.Value{}, invalidKeyError(key, err)
+	}
+
+	return value, nil
+}
+
+func invalidKeyError(key string, err error) error {
+	return KeyError{Key: key, Err: fmt.Errorf("%w: %v", ErrInvalidValue, err)}
+}
@@ -0,0 +1,123 @@
+package configuration
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTryGet_DistinguishesMissingInvalidAndZero(t *testing.T) {
+	// Arrange
+	config := New().RegisterDefaults()
+	config.UpsertKeys(map[string]interface{}{
+		"GETTER_ZERO":    "0",
+		"GETTER_INVALID": "abc",
+		"GETTER_BOOL":    "true",
+		"GETTER_FLOAT":   "1.5",
+	})
+
+	// Act
+	zero, zeroErr := config.TryGetInt("GETTER_ZERO")
+	_, missingErr := config.TryGetInt("GETTER_MISSING")
+	_, invalidErr := config.TryGetInt("GETTER_INVALID")
+	boolValue, boolErr := config.TryGetBool("GETTER_BOOL")
+	floatValue, floatErr := config.TryGetFloat("GETTER_FLOAT")
+
+	// Assert
+	assert.Nil(t, zeroErr)
+	assert.Equal(t, 0, zero)
+	assert.ErrorIs(t, missingErr, ErrKeyNotFound)
+	assert.ErrorIs(t, invalidErr, ErrInvalidValue)
+	var keyErr KeyError
+	assert.True(t, errors.As(invalidErr, &keyErr))
+	assert.Equal(t, "GETTER_INVALID", keyErr.Key)
+	assert.Nil(t, boolErr)
+	assert.True(t, boolValue)
+	assert.Nil(t, floatErr)
+	assert.Equal(t, 1.5, floatValue)
+}
+
+func TestGetOrDefaultAndMustGet(t *testing.T) {
+	// Arrange
+	config := New().RegisterDefaults()
+	config.UpsertKeys(map[string]interface{}{
+		"DEFAULT_PORT":    8080,
+		"DEFAULT_INVALID": "1.2.3",
+	})
+
+	// Act + Assert
+	assert.Equal(t, 8080, config.GetIntOrDefault("DEFAULT_PORT", 80))
+	assert.Equal(t, 80, config.GetIntOrDefault("DEFAULT_MISSING", 80))
+	assert.Equal(t, 2.5, config.GetFloatOrDefault("DEFAULT_INVALID", 2.5))
+	assert.Equal(t, "value", config.GetStringOrDefault("DEFAULT_MISSING", "value"))
+	assert.True(t, config.GetBoolOrDefault("DEFAULT_MISSING", true))
+	assert.Equal(t, "8080", config.MustGetString("DEFAULT_PORT"))
+	assert.Equal(t, 8080, config.MustGetInt("DEFAULT_PORT"))
+	assert.Panics(t, func() { config.MustGetInt("DEFAULT_MISSING") })
+	assert.Panics(t, func() { config.MustGetFloat("DEFAULT_INVALID") })
+	assert.Equal(t, float64(0), config.GetFloat("DEFAULT_INVALID"))
+}
+
+func TestTypedGetters(t *testing.T) {
+	// Arrange
+	config := New().RegisterDefaults()
+	config.RegisterProvider(newTestFileProvider(t, "settings.yaml", "labels:\n  team: core\n  tier: 1\npayload:\n  name: service\n  replicas: 3\n"))
+	config.UpsertKeys(map[string]interface{}{
+		"TYPED_TIMEOUT":  "1m30s",
+		"TYPED_SECONDS":  "45",
+		"TYPED_HOSTS":    "a.local, b.local",
+		"TYPED_TAGS":     "env=prod,region=eu",
+		"TYPED_JSON_MAP": `{"a": "1"}`,
+		"TYPED_URL":      "https://example.com:8443/api",
+		"TYPED_BAD_URL":  "example.com",
+		"TYPED_TIME":     "2024-05-01T10:00:00Z",
+		"TYPED_DATE":     "2024-05-01",
+		"TYPED_JSON":     `{"name": "json", "replicas": 2}`,
+	})
+	var payload struct {
+		Name     string `json:"name"`
+		Replicas int    `json:"replicas"`
+	}
+
+	// Act
+	timeout, timeoutErr := config.GetDuration("TYPED_TIMEOUT")
+	seconds, _ := config.GetDuration("TYPED_SECONDS")
+	hosts, hostsErr := config.GetStringSlice("TYPED_HOSTS")
+	tags, tagsErr := config.GetStringMap("TYPED_TAGS")
+	jsonMap, _ := config.GetStringMap("TYPED_JSON_MAP")
+	labels, labelsErr := config.GetStringMap("labels")
+	address, urlErr := config.GetURL("TYPED_URL")
+	_, badURLErr := config.GetURL("TYPED_BAD_URL")
+	timestamp, timeErr := config.GetTime("TYPED_TIME")
+	date, _ := config.GetTime("TYPED_DATE")
+	jsonErr := config.GetJSON("TYPED_JSON", &payload)
+	jsonName := payload.Name
+	fileJSONErr := config.GetJSON("payload", &payload)
+
+	// Assert
+	assert.Nil(t, timeoutErr)
+	assert.Equal(t, 90*time.Second, timeout)
+	assert.Equal(t, 45*time.Second, seconds)
+	assert.Equal(t, time.Minute, config.GetDurationOrDefault("TYPED_MISSING", time.Minute))
+	assert.Nil(t, hostsErr)
+	assert.Equal(t, []string{"a.local", "b.local"}, hosts)
+	assert.Nil(t, tagsErr)
+	assert.Equal(t, map[string]string{"env": "prod", "region": "eu"}, tags)
+	assert.Equal(t, map[string]string{"a": "1"}, jsonMap)
+	assert.Nil(t, labelsErr)
+	assert.Equal(t, map[string]string{"team": "core", "tier": "1"}, labels)
+	assert.Nil(t, urlErr)
+	assert.Equal(t, "example.com:8443", address.Host)
+	assert.ErrorIs(t, badURLErr, ErrInvalidValue)
+	assert.Nil(t, timeErr)
+	assert.Equal(t, 10, timestamp.Hour())
+	assert.Equal(t, time.May, date.Month())
+	assert.Nil(t, jsonErr)
+	assert.Equal(t, "json", jsonName)
+	assert.Nil(t, fileJSONErr)
+	assert.Equal(t, "service", payload.Name)
+	assert.Equal(t, 3, payload.Replicas)
+	assert.ErrorIs(t, config.GetJSON("TYPED_MISSING", &payload), ErrKeyNotFound)
+}
@@ -0,0 +1,124 @@
+package configuration
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/cjlapao/common-go/guard"
+)
+
+var (
+	ErrReferenceCycle   = errors.New("configuration reference cycle")
+	ErrInvalidReference = errors.New("invalid configuration reference")
+)
+
+// Expand Replaces the ${KEY} and ${KEY:-default} references in the text with the values of
+// the keys, the default is used when the key is missing or empty and $$ writes a literal $
+func (c *ConfigurationService) Expand(text string) (string, error) {
+	return c.expand(text, nil, nil)
+}
+
+// resolveKey reads the key, decrypting or expanding its value, stack holds the keys being
+// resolved to detect reference cycles and secret is set when the value or one of the values it
+// references is a secret. Only the values with a ${ are expanded, so $$ in plain values is
+// kept, and a value that is not a valid reference template is returned as it is
+func (c *ConfigurationService) resolveKey(key string, stack []string, secret *bool) (interface{}, error) {
+	for _, item := range stack {
+		if item == key {
+			return nil, fmt.Errorf("%w: %v", ErrReferenceCycle, strings.Join(append(stack, key), " -> "))
+		}
+	}
+
+	value := c.lookup(key)
+	if secret != nil && c.isSecret(key, value) {
+		*secret = true
+	}
+	if IsEncryptedValue(value) {
+		return c.Decrypt(value.(string))
+	}
+
+	text, ok := value.(string)
+	if !ok || !strings.Contains(text, "${") {
+		return value, nil
+	}
+
+	expanded, err := c.expand(text, append(stack[:len(stack):len(stack)], key), secret)
+	if errors.Is(err, ErrInvalidReference) {
+		return text, nil
+	}
+
+	return expanded, err
+}
+
+func (c *ConfigurationService) expand(text string, stack []string, secret *bool) (string, error) {
+	var builder strings.Builder
+	for i := 0; i < len(text); i++ {
+		if text[i] != '$' || i+1 >= len(text) {
+			builder.WriteByte(text[i])
+			continue
+		}
+
+		switch text[i+1] {
+		case '$':
+			builder.WriteByte('$')
+			i++
+		case '{':
+			end := findReferenceEnd(text, i+2)
+			if end < 0 {
+				return "", fmt.Errorf("%w: unterminated reference in %q", ErrInvalidReference, text)
+			}
+			value, err := c.expandReference(text[i+2:end], stack, secret)
+			if err != nil {
+				return "", err
+			}
+			builder.WriteString(value)
+			i = end
+		default:
+			builder.WriteByte('$')
+		}
+	}
+
+	return builder.String(), nil
+}
+
+func (c *ConfigurationService) expandReference(expression string, stack []string, secret *bool) (string, error) {
+	name, defaultValue, hasDefault := strings.Cut(expression, ":-")
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return "", fmt.Errorf("%w: empty reference ${%v}", ErrInvalidReference, expression)
+	}
+
+	value, err := c.resolveKey(name, stack, secret)
+	if err != nil {
+		return "", err
+	}
+
+	if guard.IsNill(value) {
+		if hasDefault {
+			return c.expand(defaultValue, stack, secret)
+		}
+		return "", nil
+	}
+
+	return fmt.Sprint(value), nil
+}
+
+// findReferenceEnd returns the index of the brace closing the reference that starts
+// at the given index, nested references are skipped
+func findReferenceEnd(text string, start int) int {
+	depth := 1
+	for i := start; i < len(text); i++ {
+		switch text[i] {
+		case '{':
+			depth++
+		case '}':
+			depth--
+			if depth == 0 {
+				return i
+			}
+		}
+	}
+
+	return -1
+}
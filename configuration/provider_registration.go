@@ -0,0 +1,209 @@
+package configuration
+
+import (
+	"errors"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// Provider priorities, providers with a higher priority are read first.
+// Providers with the same priority are read in the order they were registered
+const (
+	PriorityLowest      = 0
+	PriorityFile        = 50
+	PriorityDefault     = 100
+	PriorityEnvironment = 200
+	PriorityFlags       = 500
+	PriorityHighest     = 1000
+)
+
+var ErrProviderNotRegistered = errors.New("provider is not registered")
+
+// PrioritizedProvider is implemented by providers that define their own default priority
+type PrioritizedProvider interface {
+	Priority() int
+}
+
+// NamedProvider is implemented by providers that want to be identified by a friendly name
+type NamedProvider interface {
+	Name() string
+}
+
+// ProviderRegistration holds the precedence information of a registered provider
+type ProviderRegistration struct {
+	Name     string
+	Provider ConfigurationProvider
+	Priority int
+	order    int
+}
+
+// RegisterProviderWithPriority Registers a provider with an explicit priority, if the
+// provider is already registered its priority is updated
+func (c *ConfigurationService) RegisterProviderWithPriority(provider ConfigurationProvider, priority int) {
+	if provider == nil {
+		return
+	}
+
+	c.mutex.Lock()
+	isNew := true
+	for i := range c.registrations {
+		if sameProvider(c.registrations[i].Provider, provider) {
+			c.registrations[i].Priority = priority
+			isNew = false
+			break
+		}
+	}
+
+	if isNew {
+		c.sequence++
+		c.registrations = append(c.registrations, ProviderRegistration{
+			Name:     providerName(provider),
+			Provider: provider,
+			Priority: priority,
+			order:    c.sequence,
+		})
+	}
+	c.sortProviders()
+	c.mutex.Unlock()
+
+	if isNew {
+		c.subscribeToProvider(provider)
+	}
+	c.notifyChanged()
+}
+
+// UnregisterProvider Removes a provider from the service
+func (c *ConfigurationService) UnregisterProvider(provider ConfigurationProvider) error {
+	c.mutex.Lock()
+	found := false
+	for i := range c.registrations {
+		if sameProvider(c.registrations[i].Provider, provider) {
+			c.registrations = append(c.registrations[:i], c.registrations[i+1:]...)
+			if sameProvider(c.writeTarget, provider) {
+				c.writeTarget = nil
+			}
+			c.sortProviders()
+			found = true
+			break
+		}
+	}
+	c.mutex.Unlock()
+
+	if !found {
+		return ErrProviderNotRegistered
+	}
+
+	c.notifyChanged()
+	return nil
+}
+
+// SetWriteTarget Defines the registered provider that receives the UpsertKey and UpsertKeys calls
+func (c *ConfigurationService) SetWriteTarget(provider ConfigurationProvider) error {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	for _, registration := range c.registrations {
+		if sameProvider(registration.Provider, provider) {
+			c.writeTarget = registration.Provider
+			return nil
+		}
+	}
+
+	return ErrProviderNotRegistered
+}
+
+// WriteTarget Returns the provider that receives the writes, if none was set the cached
+// vault is used, falling back to the provider with the highest priority
+func (c *ConfigurationService) WriteTarget() ConfigurationProvider {
+	c.mutex.RLock()
+	defer c.mutex.RUnlock()
+
+	return c.writeTargetProvider()
+}
+
+// ProviderRegistrations Returns the registered providers ordered by precedence
+func (c *ConfigurationService) ProviderRegistrations() []ProviderRegistration {
+	c.mutex.RLock()
+	defer c.mutex.RUnlock()
+
+	result := make([]ProviderRegistration, len(c.registrations))
+	copy(result, c.registrations)
+	return result
+}
+
+func (c *ConfigurationService) writeTargetProvider() ConfigurationProvider {
+	if c.writeTarget != nil {
+		return c.writeTarget
+	}
+
+	for _, provider := range c.Providers {
+		if _, ok := provider.(*CachedVaultConfigurationProvider); ok {
+			return provider
+		}
+	}
+
+	if len(c.Providers) > 0 {
+		return c.Providers[0]
+	}
+
+	return nil
+}
+
+// providers returns a snapshot of the providers ordered by precedence
+func (c *ConfigurationService) providers() []ConfigurationProvider {
+	c.mutex.RLock()
+	defer c.mutex.RUnlock()
+
+	result := make([]ConfigurationProvider, len(c.Providers))
+	copy(result, c.Providers)
+	return result
+}
+
+func (c *ConfigurationService) sortProviders() {
+	sort.SliceStable(c.registrations, func(i, j int) bool {
+		if c.registrations[i].Priority != c.registrations[j].Priority {
+			return c.registrations[i].Priority > c.registrations[j].Priority
+		}
+		return c.registrations[i].order < c.registrations[j].order
+	})
+
+	c.Providers = make([]ConfigurationProvider, len(c.registrations))
+	for i, registration := range c.registrations {
+		c.Providers[i] = registration.Provider
+	}
+}
+
+func defaultProviderPriority(provider ConfigurationProvider) int {
+	if prioritized, ok := provider.(PrioritizedProvider); ok {
+		return prioritized.Priority()
+	}
+
+	return PriorityDefault
+}
+
+func providerName(provider ConfigurationProvider) string {
+	if named, ok := provider.(NamedProvider); ok {
+		return named.Name()
+	}
+
+	name := reflect.TypeOf(provider).String()
+	name = strings.TrimPrefix(name, "*")
+	name = strings.TrimPrefix(name, "configuration.")
+	return strings.TrimSuffix(name, "ConfigurationProvider")
+}
+
+// sameProvider compares two providers by instance, values of the same comparable type
+// with the same content are considered the same provider
+func sameProvider(a ConfigurationProvider, b ConfigurationProvider) bool {
+	if a == nil || b == nil {
+		return false
+	}
+
+	typeA := reflect.TypeOf(a)
+	if typeA != reflect.TypeOf(b) || !typeA.Comparable() {
+		return false
+	}
+
+	return a == b
+}
@@ -0,0 +1,132 @@
+package configuration
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestFileProvider(t *testing.T, name string, content string) *FileConfigurationProvider {
+	provider := NewFileConfigurationProvider()
+	if err := provider.AddFile(writeTestFile(t, name, content)); err != nil {
+		t.Fatalf("failed to load test file: %v", err)
+	}
+
+	return provider
+}
+
+func TestRegisterProvider_AllowsSeveralInstancesOfTheSameType(t *testing.T) {
+	// Arrange
+	config := New()
+	first := newTestFileProvider(t, "first.env", "FOO=first\nONLY_FIRST=yes\n")
+	second := newTestFileProvider(t, "second.env", "FOO=second\n")
+
+	// Act
+	config.RegisterProvider(first, second, first)
+
+	// Assert
+	assert.Len(t, config.Providers, 2)
+	assert.Equal(t, "first", config.Get("FOO"))
+	assert.Equal(t, "yes", config.Get("ONLY_FIRST"))
+}
+
+func TestRegisterProvider_DeduplicatesDefaults(t *testing.T) {
+	// Arrange
+	config := New()
+
+	// Act
+	config.RegisterDefaults()
+	config.RegisterDefaults()
+
+	// Assert
+	assert.Len(t, config.Providers, 3)
+}
+
+func TestRegisterProviderWithPriority_HigherPriorityIsReadFirst(t *testing.T) {
+	// Arrange
+	config := New()
+	low := newTestFileProvider(t, "low.env", "FOO=low\n")
+	high := newTestFileProvider(t, "high.env", "FOO=high\n")
+
+	// Act
+	config.RegisterProviderWithPriority(low, PriorityLowest)
+	config.RegisterProviderWithPriority(high, PriorityHighest)
+	highValue := config.Get("FOO")
+	config.RegisterProviderWithPriority(low, PriorityHighest+1)
+	lowValue := config.Get("FOO")
+
+	// Assert
+	assert.Equal(t, "high", highValue)
+	assert.Equal(t, "low", lowValue)
+	assert.Len(t, config.ProviderRegistrations(), 2)
+	assert.Equal(t, low, config.Providers[0])
+}
+
+func TestRegisterProvider_EnvironmentHasPrecedenceOverVaultAndFiles(t *testing.T) {
+	// Arrange
+	t.Setenv("PRECEDENCE_KEY", "env")
+	config := New()
+	file := newTestFileProvider(t, "settings.env", "PRECEDENCE_KEY=file\nFILE_ONLY=file\n")
+
+	// Act
+	config.RegisterProvider(file)
+	config.RegisterDefaults()
+	config.UpsertKey("FILE_ONLY", "vault")
+
+	// Assert
+	assert.Equal(t, "env", config.Get("PRECEDENCE_KEY"))
+	assert.Equal(t, "vault", config.Get("FILE_ONLY"))
+	assert.Equal(t, "Flags", config.ProviderRegistrations()[0].Name)
+	assert.Equal(t, "Environment", config.ProviderRegistrations()[1].Name)
+}
+
+func TestSetWriteTarget_UpsertsIntoSelectedProvider(t *testing.T) {
+	// Arrange
+	config := New().RegisterDefaults()
+	file := newTestFileProvider(t, "settings.env", "FOO=bar\n")
+	unregistered := NewFileConfigurationProvider()
+	config.RegisterProvider(file)
+
+	// Act
+	err := config.SetWriteTarget(file)
+	unregisteredErr := config.SetWriteTarget(unregistered)
+	config.UpsertKey("WRITE_TARGET_KEY", "value")
+	config.UpsertKeys(map[string]interface{}{"WRITE_TARGET_KEYS": "values"})
+
+	// Assert
+	assert.Nil(t, err)
+	assert.ErrorIs(t, unregisteredErr, ErrProviderNotRegistered)
+	assert.Equal(t, file, config.WriteTarget())
+	assert.Equal(t, "value", file.Get("WRITE_TARGET_KEY"))
+	assert.Equal(t, "values", file.Get("WRITE_TARGET_KEYS"))
+}
+
+func TestUnregisterProvider_RemovesProviderAndResetsWriteTarget(t *testing.T) {
+	// Arrange
+	config := New().RegisterDefaults()
+	file := newTestFileProvider(t, "settings.env", "UNREGISTER_KEY=bar\n")
+	config.RegisterProvider(file)
+	config.SetWriteTarget(file)
+
+	// Act
+	err := config.UnregisterProvider(file)
+
+	// Assert
+	assert.Nil(t, err)
+	assert.Len(t, config.Providers, 3)
+	assert.Nil(t, config.Get("UNREGISTER_KEY"))
+	assert.NotEqual(t, file, config.WriteTarget())
+}
+
+func TestUpsertKey_WithoutProvidersReturnsError(t *testing.T) {
+	// Arrange
+	config := New()
+
+	// Act
+	err := config.UpsertKey("foo", "bar")
+	errs := config.UpsertKeys(map[string]interface{}{"foo": "bar"})
+
+	// Assert
+	assert.NotNil(t, err)
+	assert.Len(t, errs, 1)
+}
@@ -4,26 +4,163 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
-	"os"
+	"net"
+	"net/url"
 	"strconv"
+	"strings"
+	"sync"
+	"time"
 
 	"github.com/cjlapao/common-go/guard"
 )
 
+const (
+	defaultRedisPort        = "6379"
+	defaultRedisPoolSize    = 5
+	defaultRedisDialTimeout = 5 * time.Second
+	defaultRedisReadTimeout = 5 * time.Second
+)
+
+// RedisConfigurationOptions holds the connection settings for the redis provider
+type RedisConfigurationOptions struct {
+	Address     string
+	Username    string
+	Password    string
+	Database    int
+	KeyPrefix   string
+	TTL         time.Duration
+	PoolSize    int
+	DialTimeout time.Duration
+	ReadTimeout time.Duration
+}
+
+// RedisConfigurationProvider stores the keys in a redis server. Writes and clears made through
+// the provider are reported to the OnChange handlers, keys expired by the server ttl are not
 type RedisConfigurationProvider struct {
 	connectionString string
-	client           string
+	options          RedisConfigurationOptions
+	pool             *redisPool
+	err              error
+	mutex            sync.RWMutex
+	handlers         []func(keys []string)
 }
 
+// NewRedisConfigurationProvider Creates a redis provider from a connection string in the
+// format redis://[username:password@]host[:port][/database][?prefix=app:&ttl=1h&pool_size=5&timeout=5s]
 func NewRedisConfigurationProvider(connString string) *RedisConfigurationProvider {
+	options, err := ParseRedisConnectionString(connString)
+	if err != nil {
+		return &RedisConfigurationProvider{
+			connectionString: connString,
+			err:              err,
+		}
+	}
+
+	result := NewRedisConfigurationProviderWithOptions(options)
+	result.connectionString = connString
+
+	return result
+}
+
+// NewRedisConfigurationProviderWithOptions Creates a redis provider from the given options
+func NewRedisConfigurationProviderWithOptions(options RedisConfigurationOptions) *RedisConfigurationProvider {
+	if options.Address == "" {
+		options.Address = net.JoinHostPort("localhost", defaultRedisPort)
+	}
+	if options.PoolSize <= 0 {
+		options.PoolSize = defaultRedisPoolSize
+	}
+	if options.DialTimeout <= 0 {
+		options.DialTimeout = defaultRedisDialTimeout
+	}
+	if options.ReadTimeout <= 0 {
+		options.ReadTimeout = defaultRedisReadTimeout
+	}
+
 	result := RedisConfigurationProvider{
-		connectionString: connString,
+		options: options,
+		pool:    newRedisPool(options),
 	}
 
 	return &result
 }
 
-func (ev RedisConfigurationProvider) UpsertKey(key string, value interface{}) error {
+// ParseRedisConnectionString Parses a redis connection string into the provider options
+func ParseRedisConnectionString(connString string) (RedisConfigurationOptions, error) {
+	options := RedisConfigurationOptions{}
+	if err := guard.EmptyOrNil(connString, "connectionString"); err != nil {
+		return options, err
+	}
+
+	if !strings.Contains(connString, "://") {
+		connString = "redis://" + connString
+	}
+
+	parsed, err := url.Parse(connString)
+	if err != nil {
+		return options, err
+	}
+
+	if parsed.Scheme != "redis" {
+		return options, fmt.Errorf("unsupported redis scheme %v", parsed.Scheme)
+	}
+
+	host := parsed.Hostname()
+	if host == "" {
+		host = "localhost"
+	}
+	port := parsed.Port()
+	if port == "" {
+		port = defaultRedisPort
+	}
+	options.Address = net.JoinHostPort(host, port)
+
+	if parsed.User != nil {
+		password, hasPassword := parsed.User.Password()
+		if hasPassword {
+			options.Username = parsed.User.Username()
+			options.Password = password
+		} else {
+			options.Password = parsed.User.Username()
+		}
+	}
+
+	database := strings.Trim(parsed.Path, "/")
+	if database != "" {
+		options.Database, err = strconv.Atoi(database)
+		if err != nil {
+			return options, fmt.Errorf("invalid redis database %v", database)
+		}
+	}
+
+	query := parsed.Query()
+	options.KeyPrefix = query.Get("prefix")
+	if value := query.Get("ttl"); value != "" {
+		if options.TTL, err = time.ParseDuration(value); err != nil {
+			return options, fmt.Errorf("invalid redis ttl %v", value)
+		}
+	}
+	if value := query.Get("pool_size"); value != "" {
+		if options.PoolSize, err = strconv.Atoi(value); err != nil {
+			return options, fmt.Errorf("invalid redis pool_size %v", value)
+		}
+	}
+	if value := query.Get("timeout"); value != "" {
+		if options.DialTimeout, err = time.ParseDuration(value); err != nil {
+			return options, fmt.Errorf("invalid redis timeout %v", value)
+		}
+		options.ReadTimeout = options.DialTimeout
+	}
+
+	return options, nil
+}
+
+func (ev *RedisConfigurationProvider) UpsertKey(key string, value interface{}) error {
+	return ev.UpsertKeyWithTTL(key, value, ev.options.TTL)
+}
+
+// UpsertKeyWithTTL Stores the key in redis expiring it after the ttl, a zero ttl never expires
+func (ev *RedisConfigurationProvider) UpsertKeyWithTTL(key string, value interface{}, ttl time.Duration) error {
 	emptyKey := guard.EmptyOrNil(key, "key")
 	emptyValue := guard.EmptyOrNil(value, "value")
 
@@ -35,27 +172,42 @@ func (ev RedisConfigurationProvider) UpsertKey(key string, value interface{}) er
 		return emptyValue
 	}
 
+	if ev.err != nil {
+		return ev.err
+	}
+
+	var stringValue string
 	switch v := value.(type) {
 	case int:
-		os.Setenv(key, strconv.Itoa(v))
+		stringValue = strconv.Itoa(v)
 	case float64:
-		os.Setenv(key, fmt.Sprintf("%v", v))
+		stringValue = fmt.Sprintf("%v", v)
 	case bool:
-		os.Setenv(key, fmt.Sprintf("%v", v))
-		os.Setenv(key, fmt.Sprintf("%v", v))
+		stringValue = fmt.Sprintf("%v", v)
 	case string:
-		os.Setenv(key, v)
+		stringValue = v
 	default:
 		jsonBytes, err := json.Marshal(v)
-		if err == nil {
-			os.Setenv(key, string(jsonBytes))
+		if err != nil {
+			return err
 		}
+		stringValue = string(jsonBytes)
 	}
 
+	args := []string{"SET", ev.prefixed(key), stringValue}
+	if ttl > 0 {
+		args = append(args, "PX", strconv.FormatInt(ttl.Milliseconds(), 10))
+	}
+
+	if _, err := ev.pool.do(args...); err != nil {
+		return err
+	}
+
+	ev.notify([]string{key})
 	return nil
 }
 
-func (ev RedisConfigurationProvider) UpsertKeys(values map[string]interface{}) []error {
+func (ev *RedisConfigurationProvider) UpsertKeys(values map[string]interface{}) []error {
 	errorArray := make([]error, 0)
 
 	if values == nil {
@@ -65,18 +217,9 @@ func (ev RedisConfigurationProvider) UpsertKeys(values map[string]interface{}) [
 
 	if len(values) > 0 {
 		for key, value := range values {
-			emptykey := guard.EmptyOrNil(key)
-			emptyValue := guard.EmptyOrNil(value)
-
-			if emptykey != nil {
-				errorArray = append(errorArray, emptykey)
+			if err := ev.UpsertKey(key, value); err != nil {
+				errorArray = append(errorArray, err)
 			}
-
-			if emptyValue != nil {
-				errorArray = append(errorArray, emptyValue)
-			}
-
-			ev.UpsertKey(key, value)
 		}
 
 		return errorArray
@@ -85,14 +228,69 @@ func (ev RedisConfigurationProvider) UpsertKeys(values map[string]interface{}) [
 	return nil
 }
 
-func (ev RedisConfigurationProvider) Get(key string) interface{} {
-	return os.Getenv(key)
+func (ev *RedisConfigurationProvider) Get(key string) interface{} {
+	if ev.err != nil || guard.EmptyOrNil(key) != nil {
+		return nil
+	}
+
+	value, err := ev.pool.do("GET", ev.prefixed(key))
+	if err != nil {
+		return nil
+	}
+
+	return value
 }
 
-func (ev RedisConfigurationProvider) Clear(key string) {
+func (ev *RedisConfigurationProvider) Clear(key string) {
 	emptyKey := guard.EmptyOrNil(key, "key")
 
-	if emptyKey == nil {
-		os.Setenv(key, "")
+	if emptyKey == nil && ev.err == nil {
+		if _, err := ev.pool.do("DEL", ev.prefixed(key)); err == nil {
+			ev.notify([]string{key})
+		}
+	}
+}
+
+// OnChange Registers a handler called with the keys written or cleared through the provider
+func (ev *RedisConfigurationProvider) OnChange(handler func(keys []string)) {
+	ev.mutex.Lock()
+	defer ev.mutex.Unlock()
+
+	ev.handlers = append(ev.handlers, handler)
+}
+
+func (ev *RedisConfigurationProvider) notify(keys []string) {
+	ev.mutex.RLock()
+	handlers := make([]func(keys []string), len(ev.handlers))
+	copy(handlers, ev.handlers)
+	ev.mutex.RUnlock()
+
+	for _, handler := range handlers {
+		handler(keys)
 	}
 }
+
+// Ping Checks if the redis server is reachable with the configured credentials
+func (ev *RedisConfigurationProvider) Ping() error {
+	if ev.err != nil {
+		return ev.err
+	}
+
+	_, err := ev.pool.do("PING")
+	return err
+}
+
+// Close Closes all the pooled connections
+func (ev *RedisConfigurationProvider) Close() {
+	if ev.pool != nil {
+		ev.pool.close()
+	}
+}
+
+func (ev *RedisConfigurationProvider) prefixed(key string) string {
+	return ev.options.KeyPrefix + key
+}
+
+func (ev *RedisConfigurationProvider) Name() string {
+	return "Redis(" + ev.options.Address + "/" + strconv.Itoa(ev.options.Database) + ")"
+}
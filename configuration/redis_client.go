@@ -0,0 +1,270 @@
+package configuration
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ErrRedisNil is returned when redis replies with a nil bulk string
+var ErrRedisNil = errors.New("redis: nil reply")
+
+// RedisError represents an error reply sent by the redis server
+type RedisError struct {
+	Message string
+}
+
+func (e RedisError) Error() string {
+	return "redis: " + e.Message
+}
+
+var errRedisPoolClosed = errors.New("redis: pool is closed")
+
+// redisConn is a single RESP connection to a redis server
+type redisConn struct {
+	conn    net.Conn
+	reader  *bufio.Reader
+	writer  *bufio.Writer
+	timeout time.Duration
+	broken  bool
+}
+
+func (c *redisConn) do(args ...string) (interface{}, error) {
+	if c.timeout > 0 {
+		c.conn.SetDeadline(time.Now().Add(c.timeout))
+	}
+
+	if err := writeRedisCommand(c.writer, args...); err != nil {
+		c.broken = true
+		return nil, err
+	}
+
+	reply, err := readRedisReply(c.reader)
+	if err != nil {
+		var redisErr RedisError
+		if !errors.As(err, &redisErr) && !errors.Is(err, ErrRedisNil) {
+			c.broken = true
+		}
+		return nil, err
+	}
+
+	return reply, nil
+}
+
+func (c *redisConn) close() error {
+	return c.conn.Close()
+}
+
+// redisPool keeps a bounded set of idle connections to a redis server
+type redisPool struct {
+	options RedisConfigurationOptions
+	idle    chan *redisConn
+	mutex   sync.Mutex
+	closed  bool
+}
+
+func newRedisPool(options RedisConfigurationOptions) *redisPool {
+	size := options.PoolSize
+	if size <= 0 {
+		size = 1
+	}
+
+	return &redisPool{
+		options: options,
+		idle:    make(chan *redisConn, size),
+	}
+}
+
+// get returns an idle connection or dials a new one, the closed flag and the idle channel
+// are read under the same lock as close() closes the channel
+func (p *redisPool) get() (*redisConn, error) {
+	p.mutex.Lock()
+	if p.closed {
+		p.mutex.Unlock()
+		return nil, errRedisPoolClosed
+	}
+
+	select {
+	case conn, ok := <-p.idle:
+		p.mutex.Unlock()
+		if !ok || conn == nil {
+			return nil, errRedisPoolClosed
+		}
+		return conn, nil
+	default:
+		p.mutex.Unlock()
+		return p.dial()
+	}
+}
+
+func (p *redisPool) put(conn *redisConn) {
+	if conn.broken {
+		conn.close()
+		return
+	}
+
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	if p.closed {
+		conn.close()
+		return
+	}
+
+	select {
+	case p.idle <- conn:
+	default:
+		conn.close()
+	}
+}
+
+func (p *redisPool) dial() (*redisConn, error) {
+	dialer := net.Dialer{Timeout: p.options.DialTimeout}
+	netConn, err := dialer.Dial("tcp", p.options.Address)
+	if err != nil {
+		return nil, err
+	}
+
+	conn := &redisConn{
+		conn:    netConn,
+		reader:  bufio.NewReader(netConn),
+		writer:  bufio.NewWriter(netConn),
+		timeout: p.options.ReadTimeout,
+	}
+
+	if p.options.Password != "" {
+		args := []string{"AUTH"}
+		if p.options.Username != "" {
+			args = append(args, p.options.Username)
+		}
+		args = append(args, p.options.Password)
+		if _, err := conn.do(args...); err != nil {
+			conn.close()
+			return nil, err
+		}
+	}
+
+	if p.options.Database != 0 {
+		if _, err := conn.do("SELECT", strconv.Itoa(p.options.Database)); err != nil {
+			conn.close()
+			return nil, err
+		}
+	}
+
+	return conn, nil
+}
+
+// do runs a single command on a pooled connection
+func (p *redisPool) do(args ...string) (interface{}, error) {
+	conn, err := p.get()
+	if err != nil {
+		return nil, err
+	}
+	defer p.put(conn)
+
+	return conn.do(args...)
+}
+
+func (p *redisPool) close() {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	if p.closed {
+		return
+	}
+
+	p.closed = true
+	close(p.idle)
+	for conn := range p.idle {
+		conn.close()
+	}
+}
+
+func writeRedisCommand(w *bufio.Writer, args ...string) error {
+	if _, err := fmt.Fprintf(w, "*%d\r\n", len(args)); err != nil {
+		return err
+	}
+
+	for _, arg := range args {
+		if _, err := fmt.Fprintf(w, "$%d\r\n%s\r\n", len(arg), arg); err != nil {
+			return err
+		}
+	}
+
+	return w.Flush()
+}
+
+func readRedisLine(r *bufio.Reader) (string, error) {
+	line, err := r.ReadString('\n')
+	if err != nil {
+		return "", err
+	}
+
+	if !strings.HasSuffix(line, "\r\n") {
+		return "", errors.New("redis: invalid line terminator")
+	}
+
+	return line[:len(line)-2], nil
+}
+
+// readRedisReply reads a RESP reply, bulk strings are returned as string,
+// integers as int64, arrays as []interface{} and nil replies as ErrRedisNil
+func readRedisReply(r *bufio.Reader) (interface{}, error) {
+	line, err := readRedisLine(r)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(line) == 0 {
+		return nil, errors.New("redis: empty reply")
+	}
+
+	switch line[0] {
+	case '+':
+		return line[1:], nil
+	case '-':
+		return nil, RedisError{Message: line[1:]}
+	case ':':
+		return strconv.ParseInt(line[1:], 10, 64)
+	case '$':
+		size, err := strconv.Atoi(line[1:])
+		if err != nil {
+			return nil, err
+		}
+		if size < 0 {
+			return nil, ErrRedisNil
+		}
+
+		buffer := make([]byte, size+2)
+		if _, err := io.ReadFull(r, buffer); err != nil {
+			return nil, err
+		}
+
+		return string(buffer[:size]), nil
+	case '*':
+		size, err := strconv.Atoi(line[1:])
+		if err != nil {
+			return nil, err
+		}
+		if size < 0 {
+			return nil, ErrRedisNil
+		}
+
+		result := make([]interface{}, size)
+		for i := 0; i < size; i++ {
+			item, err := readRedisReply(r)
+			if err != nil && !errors.Is(err, ErrRedisNil) {
+				return nil, err
+			}
+			result[i] = item
+		}
+
+		return result, nil
+	default:
+		return nil, fmt.Errorf("redis: unknown reply type %q", line[0])
+	}
+}
@@ -0,0 +1,291 @@
+package configuration
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"reflect"
+	"strings"
+	"text/tabwriter"
+	"time"
+	"unicode/utf8"
+
+	"github.com/cjlapao/common-go/guard"
+)
+
+type KeyType int
+
+const (
+	StringKey KeyType = iota
+	IntKey
+	FloatKey
+	BoolKey
+	DurationKey
+	ListKey
+	URLKey
+)
+
+func (k KeyType) String() string {
+	return toKeyTypeString[k]
+}
+
+var toKeyTypeString = map[KeyType]string{
+	StringKey:   "string",
+	IntKey:      "int",
+	FloatKey:    "float",
+	BoolKey:     "bool",
+	DurationKey: "duration",
+	ListKey:     "list",
+	URLKey:      "url",
+}
+
+var toKeyTypeReflect = map[KeyType]reflect.Type{
+	StringKey:   reflect.TypeOf(""),
+	IntKey:      reflect.TypeOf(int64(0)),
+	FloatKey:    reflect.TypeOf(float64(0)),
+	BoolKey:     reflect.TypeOf(false),
+	DurationKey: timeDurationType,
+	ListKey:     reflect.TypeOf([]string{}),
+	URLKey:      reflect.TypeOf(""),
+}
+
+const maskedValue = "******"
+
+var (
+	ErrInvalidValue    = errors.New("value is not valid")
+	ErrValueNotAllowed = errors.New("value is not allowed")
+	ErrValueOutOfRange = errors.New("value is out of range")
+)
+
+// KeyRange limits numeric values, for strings and lists it limits their length
+// and for durations it is expressed in seconds
+type KeyRange struct {
+	Min float64
+	Max float64
+}
+
+// KeyDefinition describes an expected configuration key
+type KeyDefinition struct {
+	Key           string
+	Type          KeyType
+	Required      bool
+	Secret        bool
+	AllowedValues []string
+	Range         *KeyRange
+	Description   string
+}
+
+// ValidationError lists every key that does not match its definition
+type ValidationError struct {
+	Errors []KeyError
+}
+
+func (e *ValidationError) Error() string {
+	messages := make([]string, len(e.Errors))
+	for i, keyError := range e.Errors {
+		messages[i] = keyError.Error()
+	}
+
+	return "configuration validation failed: " + strings.Join(messages, "; ")
+}
+
+func (e *ValidationError) Unwrap() []error {
+	result := make([]error, len(e.Errors))
+	for i, keyError := range e.Errors {
+		result[i] = keyError
+	}
+
+	return result
+}
+
+// Define Declares the expected keys, defining a key again replaces its definition
+func (c *ConfigurationService) Define(definitions ...KeyDefinition) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	if c.definitions == nil {
+		c.definitions = make(map[string]KeyDefinition)
+	}
+
+	for _, definition := range definitions {
+		if definition.Key == "" {
+			continue
+		}
+		if _, exists := c.definitions[definition.Key]; !exists {
+			c.definitionOrder = append(c.definitionOrder, definition.Key)
+		}
+		c.definitions[definition.Key] = definition
+	}
+}
+
+// Definitions Returns the declared keys in the order they were defined
+func (c *ConfigurationService) Definitions() []KeyDefinition {
+	c.mutex.RLock()
+	defer c.mutex.RUnlock()
+
+	result := make([]KeyDefinition, 0, len(c.definitionOrder))
+	for _, key := range c.definitionOrder {
+		result = append(result, c.definitions[key])
+	}
+
+	return result
+}
+
+// Validate Checks the effective values against the definitions, all the problems are
+// returned in a *ValidationError
+func (c *ConfigurationService) Validate() error {
+	validationErr := &ValidationError{}
+	for _, definition := range c.Definitions() {
+		if err := c.validateKey(definition); err != nil {
+			validationErr.Errors = append(validationErr.Errors, KeyError{Key: definition.Key, Err: err})
+		}
+	}
+
+	if len(validationErr.Errors) > 0 {
+		return validationErr
+	}
+
+	return nil
+}
+
+func (c *ConfigurationService) validateKey(definition KeyDefinition) error {
+	value, err := c.resolve(definition.Key)
+	if err != nil {
+		return err
+	}
+
+	if guard.IsNill(value) {
+		if definition.Required {
+			return ErrKeyNotFound
+		}
+		return nil
+	}
+
+	converted, err := convertConfigValue(value, toKeyTypeReflect[definition.Type])
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidValue, err)
+	}
+
+	if definition.Type == URLKey {
+		parsed, err := url.Parse(converted.String())
+		if err != nil || parsed.Scheme == "" || parsed.Host == "" {
+			return fmt.Errorf("%w: invalid url %q", ErrInvalidValue, converted.String())
+		}
+	}
+
+	if len(definition.AllowedValues) > 0 && !isAllowedValue(value, definition.AllowedValues) {
+		return fmt.Errorf("%w: %q must be one of %v", ErrValueNotAllowed, fmt.Sprint(value), strings.Join(definition.AllowedValues, ", "))
+	}
+
+	if definition.Range != nil {
+		measure := rangeMeasure(converted)
+		if measure < definition.Range.Min || measure > definition.Range.Max {
+			return fmt.Errorf("%w: %v is not between %v and %v", ErrValueOutOfRange, measure, definition.Range.Min, definition.Range.Max)
+		}
+	}
+
+	return nil
+}
+
+func isAllowedValue(value interface{}, allowed []string) bool {
+	text := fmt.Sprint(value)
+	for _, item := range allowed {
+		if strings.EqualFold(item, text) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func rangeMeasure(value reflect.Value) float64 {
+	if value.Type() == timeDurationType {
+		return time.Duration(value.Int()).Seconds()
+	}
+
+	switch value.Kind() {
+	case reflect.Int64:
+		return float64(value.Int())
+	case reflect.Float64:
+		return value.Float()
+	case reflect.String:
+		return float64(utf8.RuneCountInString(value.String()))
+	case reflect.Slice:
+		return float64(value.Len())
+	}
+
+	return 0
+}
+
+// Describe Writes a table with the defined keys, their effective value and the provider
+// that supplied it, secret and encrypted values and the values referencing them are masked
+func (c *ConfigurationService) Describe(w io.Writer) error {
+	writer := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(writer, "KEY\tTYPE\tVALUE\tSOURCE\tDESCRIPTION")
+
+	for _, definition := range c.Definitions() {
+		raw, source := c.lookupWithSource(definition.Key)
+		value := "<not set>"
+		if !guard.IsNill(raw) {
+			if resolved, secret, err := c.resolveMasked(definition.Key); secret {
+				value = maskedValue
+			} else if err != nil {
+				value = "<error: " + err.Error() + ">"
+			} else {
+				value = fmt.Sprint(resolved)
+			}
+		} else {
+			source = "-"
+		}
+
+		fmt.Fprintf(writer, "%v\t%v\t%v\t%v\t%v\n", definition.Key, definition.Type, value, source, definition.Description)
+	}
+
+	return writer.Flush()
+}
+
+// resolveMasked resolves the key and returns true if the value must be masked because the key
+// or any key its value references is a secret or holds an encrypted value
+func (c *ConfigurationService) resolveMasked(key string) (interface{}, bool, error) {
+	secret := false
+	value, err := c.resolveKey(key, nil, &secret)
+	return value, secret, err
+}
+
+// isSecret returns true if the key is defined as a secret, its name suggests a secret or its
+// raw value is encrypted
+func (c *ConfigurationService) isSecret(key string, raw interface{}) bool {
+	if IsSecretKey(key) || IsEncryptedValue(raw) {
+		return true
+	}
+
+	c.mutex.RLock()
+	defer c.mutex.RUnlock()
+	definition, ok := c.definitions[key]
+	return ok && definition.Secret
+}
+
+// IsSecretKey Returns true when the key name suggests it holds a secret
+func IsSecretKey(key string) bool {
+	upper := strings.ToUpper(key)
+	for _, marker := range []string{"PASSWORD", "PASSWD", "SECRET", "TOKEN", "PRIVATE_KEY", "API_KEY"} {
+		if strings.Contains(upper, marker) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// lookupWithSource returns the raw value of the key and the name of the provider holding it
+func (c *ConfigurationService) lookupWithSource(key string) (interface{}, string) {
+	for _, registration := range c.ProviderRegistrations() {
+		result := registration.Provider.Get(key)
+		if !guard.IsNill(result) {
+			return result, registration.Name
+		}
+	}
+
+	return nil, ""
+}
@@ -0,0 +1,140 @@
+package configuration
+
+import (
+	"bytes"
+	"encoding/base64"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/cjlapao/common-go/constants"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidate_ReturnsStructuredErrors(t *testing.T) {
+	// Arrange
+	config := New().RegisterDefaults()
+	config.UpsertKeys(map[string]interface{}{
+		"SCHEMA_PORT":    "70000",
+		"SCHEMA_DEBUG":   "maybe",
+		"SCHEMA_LEVEL":   "verbose",
+		"SCHEMA_URL":     "not a url",
+		"SCHEMA_TIMEOUT": "90s",
+		"SCHEMA_NAME":    "service",
+	})
+	config.Define(
+		KeyDefinition{Key: "SCHEMA_HOST", Type: StringKey, Required: true},
+		KeyDefinition{Key: "SCHEMA_PORT", Type: IntKey, Range: &KeyRange{Min: 1, Max: 65535}},
+		KeyDefinition{Key: "SCHEMA_DEBUG", Type: BoolKey},
+		KeyDefinition{Key: "SCHEMA_LEVEL", Type: StringKey, AllowedValues: []string{"debug", "info"}},
+		KeyDefinition{Key: "SCHEMA_URL", Type: URLKey},
+		KeyDefinition{Key: "SCHEMA_TIMEOUT", Type: DurationKey, Range: &KeyRange{Min: 1, Max: 60}},
+		KeyDefinition{Key: "SCHEMA_NAME", Type: StringKey, Required: true},
+		KeyDefinition{Key: "SCHEMA_OPTIONAL", Type: IntKey},
+	)
+
+	// Act
+	err := config.Validate()
+
+	// Assert
+	var validationErr *ValidationError
+	assert.True(t, errors.As(err, &validationErr))
+	result := make(map[string]error)
+	for _, keyError := range validationErr.Errors {
+		result[keyError.Key] = keyError.Err
+	}
+	assert.Len(t, result, 6)
+	assert.ErrorIs(t, result["SCHEMA_HOST"], ErrKeyNotFound)
+	assert.ErrorIs(t, result["SCHEMA_PORT"], ErrValueOutOfRange)
+	assert.ErrorIs(t, result["SCHEMA_DEBUG"], ErrInvalidValue)
+	assert.ErrorIs(t, result["SCHEMA_LEVEL"], ErrValueNotAllowed)
+	assert.ErrorIs(t, result["SCHEMA_URL"], ErrInvalidValue)
+	assert.ErrorIs(t, result["SCHEMA_TIMEOUT"], ErrValueOutOfRange)
+}
+
+func TestValidate_ValidConfigurationReturnsNil(t *testing.T) {
+	// Arrange
+	config := New().RegisterDefaults()
+	config.UpsertKeys(map[string]interface{}{
+		"VALID_PORT":  5432,
+		"VALID_LEVEL": "INFO",
+		"VALID_URL":   "https://example.com/path",
+	})
+	config.Define(
+		KeyDefinition{Key: "VALID_PORT", Type: IntKey, Required: true, Range: &KeyRange{Min: 1, Max: 65535}},
+		KeyDefinition{Key: "VALID_LEVEL", Type: StringKey, AllowedValues: []string{"debug", "info"}},
+		KeyDefinition{Key: "VALID_URL", Type: URLKey},
+	)
+
+	// Act
+	err := config.Validate()
+
+	// Assert
+	assert.Nil(t, err)
+}
+
+func TestDescribe_PrintsValuesSourcesAndMasksSecrets(t *testing.T) {
+	// Arrange
+	config := New().RegisterDefaults()
+	config.RegisterProvider(newTestFileProvider(t, "settings.env", "DESCRIBE_HOST=db.local\n"))
+	config.UpsertKeys(map[string]interface{}{
+		"DESCRIBE_PASSWORD": "hunter2",
+		"DESCRIBE_CERT":     "certificate",
+	})
+	config.Define(
+		KeyDefinition{Key: "DESCRIBE_HOST", Description: "Database host"},
+		KeyDefinition{Key: "DESCRIBE_PASSWORD"},
+		KeyDefinition{Key: "DESCRIBE_CERT", Secret: true},
+		KeyDefinition{Key: "DESCRIBE_MISSING", Type: IntKey},
+	)
+	var buffer bytes.Buffer
+
+	// Act
+	err := config.Describe(&buffer)
+
+	// Assert
+	assert.Nil(t, err)
+	lines := strings.Split(strings.TrimSpace(buffer.String()), "\n")
+	assert.Len(t, lines, 5)
+	assert.Regexp(t, `^KEY\s+TYPE\s+VALUE\s+SOURCE\s+DESCRIPTION$`, lines[0])
+	assert.Regexp(t, `^DESCRIBE_HOST\s+string\s+db\.local\s+File\(.*settings\.env\)\s+Database host$`, lines[1])
+	assert.Regexp(t, `^DESCRIBE_PASSWORD\s+string\s+\*{6}\s+CachedVault`, lines[2])
+	assert.Regexp(t, `^DESCRIBE_CERT\s+string\s+\*{6}\s+CachedVault`, lines[3])
+	assert.Regexp(t, `^DESCRIBE_MISSING\s+int\s+<not set>\s+-`, lines[4])
+	assert.NotContains(t, buffer.String(), "hunter2")
+}
+
+func TestDescribe_MasksValuesReferencingSecrets(t *testing.T) {
+	// Arrange
+	masterKey, _ := GenerateMasterKey()
+	t.Setenv(constants.CONFIG_MASTER_KEY_ENVIRONMENT, masterKey)
+	key, _ := base64.StdEncoding.DecodeString(masterKey)
+	encrypted, _ := EncryptValue(key, "hunter2")
+	config := New().RegisterDefaults()
+	config.UpsertKeys(map[string]interface{}{
+		"REFERENCE_DB_PASS": encrypted,
+		"REFERENCE_DB_URL":  "postgres://u:${REFERENCE_DB_PASS}@h",
+		"REFERENCE_CERT":    "certificate",
+		"REFERENCE_BUNDLE":  "${REFERENCE_MISSING:-${REFERENCE_CERT}}",
+		"REFERENCE_HOST":    "${REFERENCE_MISSING:-h}",
+	})
+	config.Define(
+		KeyDefinition{Key: "REFERENCE_DB_URL"},
+		KeyDefinition{Key: "REFERENCE_BUNDLE"},
+		KeyDefinition{Key: "REFERENCE_HOST"},
+		KeyDefinition{Key: "REFERENCE_CERT", Secret: true},
+	)
+	var buffer bytes.Buffer
+
+	// Act
+	err := config.Describe(&buffer)
+
+	// Assert
+	assert.Nil(t, err)
+	lines := strings.Split(strings.TrimSpace(buffer.String()), "\n")
+	assert.Regexp(t, `^REFERENCE_DB_URL\s+string\s+\*{6}\s+`, lines[1])
+	assert.Regexp(t, `^REFERENCE_BUNDLE\s+string\s+\*{6}\s+`, lines[2])
+	assert.Regexp(t, `^REFERENCE_HOST\s+string\s+h\s+`, lines[3])
+	assert.NotContains(t, buffer.String(), "hunter2")
+	assert.NotContains(t, buffer.String(), "certificate")
+}
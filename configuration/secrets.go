@@ -0,0 +1,288 @@
+package configuration
+
+import (
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/cjlapao/common-go/constants"
+	"github.com/cjlapao/common-go/security/encryption"
+)
+
+// Encrypted values have the form enc:AES256GCM:<base64 nonce and cipher text> when they are
+// encrypted with the master key, or enc:RSA-AES256GCM:<base64 wrapped key>:<base64 cipher text>
+// when a random data key is wrapped with an RSA public key
+const (
+	EncryptedValuePrefix  = "enc:"
+	AES256GCMAlgorithm    = "AES256GCM"
+	RSAAES256GCMAlgorithm = "RSA-AES256GCM"
+)
+
+var (
+	ErrMasterKeyNotConfigured  = errors.New("configuration master key is not configured")
+	ErrPrivateKeyNotConfigured = errors.New("configuration private key is not configured")
+	ErrInvalidMasterKey        = errors.New("configuration master key must be 32 bytes")
+	ErrInvalidEncryptedValue   = errors.New("invalid encrypted value")
+)
+
+// IsEncryptedValue Returns true if the value is a string using the enc: prefix
+func IsEncryptedValue(value interface{}) bool {
+	text, ok := value.(string)
+	return ok && strings.HasPrefix(text, EncryptedValuePrefix)
+}
+
+// GenerateMasterKey Generates a random base64 encoded master key that can be used in
+// the CJ_CONFIG_MASTER_KEY environment variable
+func GenerateMasterKey() (string, error) {
+	key, err := encryption.AESHelper{}.GenerateKey(encryption.Bit256)
+	if err != nil {
+		return "", err
+	}
+
+	return base64.StdEncoding.EncodeToString(key), nil
+}
+
+// EncryptValue Encrypts the value with the master key returning the enc:AES256GCM: value
+// ready to be stored in a file or an environment variable
+func EncryptValue(masterKey []byte, plainText string) (string, error) {
+	if len(masterKey) != 32 {
+		return "", ErrInvalidMasterKey
+	}
+
+	cipherText, err := encryption.AESHelper{}.Encrypt(masterKey, []byte(plainText))
+	if err != nil {
+		return "", err
+	}
+
+	return EncryptedValuePrefix + AES256GCMAlgorithm + ":" + base64.StdEncoding.EncodeToString(cipherText), nil
+}
+
+// Encrypt Encrypts the value with the configured master key returning the enc:AES256GCM:
+// value, the key is read in the same way as Decrypt reads it
+func (c *ConfigurationService) Encrypt(plainText string) (string, error) {
+	key, err := c.getMasterKey()
+	if err != nil {
+		return "", err
+	}
+
+	return EncryptValue(key, plainText)
+}
+
+// EncryptValueWithPublicKey Encrypts the value with a random data key wrapped by the RSA
+// public key returning the enc:RSA-AES256GCM: value
+func EncryptValueWithPublicKey(publicKey *rsa.PublicKey, plainText string) (string, error) {
+	if publicKey == nil {
+		return "", errors.New("public key is nil")
+	}
+
+	aesHelper := encryption.AESHelper{}
+	dataKey, err := aesHelper.GenerateKey(encryption.Bit256)
+	if err != nil {
+		return "", err
+	}
+
+	wrappedKey, err := encryption.RSAHelper{}.WrapKey(publicKey, dataKey)
+	if err != nil {
+		return "", err
+	}
+
+	cipherText, err := aesHelper.Encrypt(dataKey, []byte(plainText))
+	if err != nil {
+		return "", err
+	}
+
+	return EncryptedValuePrefix + RSAAES256GCMAlgorithm + ":" +
+		base64.StdEncoding.EncodeToString(wrappedKey) + ":" +
+		base64.StdEncoding.EncodeToString(cipherText), nil
+}
+
+// SetMasterKey Defines the key used to decrypt the enc:AES256GCM: values, when it is not set
+// the key is read from CJ_CONFIG_MASTER_KEY or from the file in CJ_CONFIG_MASTER_KEY_FILE
+func (c *ConfigurationService) SetMasterKey(key []byte) error {
+	if len(key) != 32 {
+		return ErrInvalidMasterKey
+	}
+
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.masterKey = key
+	return nil
+}
+
+// SetPrivateKey Defines the key used to decrypt the enc:RSA-AES256GCM: values, when it is not set
+// the PEM key is read from CJ_CONFIG_PRIVATE_KEY or from the file in CJ_CONFIG_PRIVATE_KEY_FILE
+func (c *ConfigurationService) SetPrivateKey(key *rsa.PrivateKey) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.privateKey = key
+}
+
+// Decrypt Decrypts an enc: value using the configured keys, values without the prefix
+// are returned unchanged
+func (c *ConfigurationService) Decrypt(value string) (string, error) {
+	if !IsEncryptedValue(value) {
+		return value, nil
+	}
+
+	algorithm, payload, found := strings.Cut(strings.TrimPrefix(value, EncryptedValuePrefix), ":")
+	if !found {
+		return "", ErrInvalidEncryptedValue
+	}
+
+	switch algorithm {
+	case AES256GCMAlgorithm:
+		key, err := c.getMasterKey()
+		if err != nil {
+			return "", err
+		}
+		return decryptPayload(key, payload)
+	case RSAAES256GCMAlgorithm:
+		privateKey, err := c.getPrivateKey()
+		if err != nil {
+			return "", err
+		}
+		encodedKey, encodedPayload, found := strings.Cut(payload, ":")
+		if !found {
+			return "", ErrInvalidEncryptedValue
+		}
+		wrappedKey, err := base64.StdEncoding.DecodeString(encodedKey)
+		if err != nil {
+			return "", ErrInvalidEncryptedValue
+		}
+		dataKey, err := encryption.RSAHelper{}.UnwrapKey(privateKey, wrappedKey)
+		if err != nil {
+			return "", fmt.Errorf("unable to unwrap the data key: %w", err)
+		}
+		return decryptPayload(dataKey, encodedPayload)
+	default:
+		return "", fmt.Errorf("unsupported encryption algorithm %q", algorithm)
+	}
+}
+
+func decryptPayload(key []byte, payload string) (string, error) {
+	cipherText, err := base64.StdEncoding.DecodeString(payload)
+	if err != nil {
+		return "", ErrInvalidEncryptedValue
+	}
+
+	plainText, err := encryption.AESHelper{}.Decrypt(key, cipherText)
+	if err != nil {
+		return "", fmt.Errorf("unable to decrypt the value: %w", err)
+	}
+
+	return string(plainText), nil
+}
+
+func (c *ConfigurationService) getMasterKey() ([]byte, error) {
+	c.mutex.RLock()
+	key := c.masterKey
+	c.mutex.RUnlock()
+	if key != nil {
+		return key, nil
+	}
+
+	content, err := readSecretSetting(constants.CONFIG_MASTER_KEY_ENVIRONMENT, constants.CONFIG_MASTER_KEY_FILE_ENVIRONMENT)
+	if err != nil {
+		return nil, err
+	}
+	if content == "" {
+		return nil, ErrMasterKeyNotConfigured
+	}
+
+	key = decodeMasterKey(content)
+	if err := c.SetMasterKey(key); err != nil {
+		return nil, err
+	}
+
+	return key, nil
+}
+
+// decodeMasterKey returns the base64 decoded key when it decodes to 32 bytes, otherwise the
+// content is used as the raw key
+func decodeMasterKey(content string) []byte {
+	if key, err := base64.StdEncoding.DecodeString(content); err == nil && len(key) == 32 {
+		return key
+	}
+
+	return []byte(content)
+}
+
+func (c *ConfigurationService) getPrivateKey() (*rsa.PrivateKey, error) {
+	c.mutex.RLock()
+	key := c.privateKey
+	c.mutex.RUnlock()
+	if key != nil {
+		return key, nil
+	}
+
+	content, err := readSecretSetting(constants.CONFIG_PRIVATE_KEY_ENVIRONMENT, constants.CONFIG_PRIVATE_KEY_FILE_ENVIRONMENT)
+	if err != nil {
+		return nil, err
+	}
+	if content == "" {
+		return nil, ErrPrivateKeyNotConfigured
+	}
+
+	key, err = parseRSAPrivateKey(content)
+	if err != nil {
+		return nil, err
+	}
+	c.SetPrivateKey(key)
+
+	return key, nil
+}
+
+// readSecretSetting reads a value from the environment variable or from the file its
+// companion _FILE variable points to
+func readSecretSetting(variable string, fileVariable string) (string, error) {
+	if value := strings.TrimSpace(os.Getenv(variable)); value != "" {
+		return value, nil
+	}
+
+	path := strings.TrimSpace(os.Getenv(fileVariable))
+	if path == "" {
+		return "", nil
+	}
+
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return "", err
+	}
+
+	return strings.TrimSpace(string(content)), nil
+}
+
+// parseRSAPrivateKey reads a PKCS1 or PKCS8 PEM key, the PEM can also be base64 encoded
+func parseRSAPrivateKey(content string) (*rsa.PrivateKey, error) {
+	block, _ := pem.Decode([]byte(content))
+	if block == nil {
+		decoded, err := base64.StdEncoding.DecodeString(content)
+		if err == nil {
+			block, _ = pem.Decode(decoded)
+		}
+	}
+	if block == nil {
+		return nil, errors.New("private key is not a PEM encoded key")
+	}
+
+	if key, err := x509.ParsePKCS1PrivateKey(block.Bytes); err == nil {
+		return key, nil
+	}
+
+	parsed, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, err
+	}
+
+	key, ok := parsed.(*rsa.PrivateKey)
+	if !ok {
+		return nil, errors.New("private key is not an RSA key")
+	}
+
+	return key, nil
+}
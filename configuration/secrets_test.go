@@ -0,0 +1,135 @@
+package configuration
+
+import (
+	"encoding/base64"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/cjlapao/common-go/constants"
+	"github.com/cjlapao/common-go/security/encryption"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSecrets_GetDecryptsMasterKeyValues(t *testing.T) {
+	// Arrange
+	masterKey, _ := GenerateMasterKey()
+	t.Setenv(constants.CONFIG_MASTER_KEY_ENVIRONMENT, masterKey)
+	key, _ := base64.StdEncoding.DecodeString(masterKey)
+	encrypted, err := EncryptValue(key, "s3cr3t")
+	config := New().RegisterDefaults()
+
+	// Act
+	config.UpsertKey("DB_PASSWORD", encrypted)
+
+	// Assert
+	assert.Nil(t, err)
+	assert.Contains(t, encrypted, "enc:AES256GCM:")
+	assert.NotContains(t, encrypted, "s3cr3t")
+	assert.Equal(t, "s3cr3t", config.GetString("DB_PASSWORD"))
+}
+
+func TestSecrets_MasterKeyIsReadFromFile(t *testing.T) {
+	// Arrange
+	masterKey, _ := GenerateMasterKey()
+	path := filepath.Join(t.TempDir(), "master.key")
+	os.WriteFile(path, []byte(masterKey+"\n"), 0o600)
+	t.Setenv(constants.CONFIG_MASTER_KEY_ENVIRONMENT, "")
+	t.Setenv(constants.CONFIG_MASTER_KEY_FILE_ENVIRONMENT, path)
+	key, _ := base64.StdEncoding.DecodeString(masterKey)
+	encrypted, _ := EncryptValue(key, "from-file")
+	config := New()
+	config.RegisterProvider(newTestFileProvider(t, "settings.env", "API_KEY="+encrypted+"\n"))
+
+	// Act
+	value := config.Get("API_KEY")
+
+	// Assert
+	assert.Equal(t, "from-file", value)
+}
+
+func TestSecrets_DecryptsRSAWrappedValues(t *testing.T) {
+	// Arrange
+	privateKey, publicKey := encryption.RSAHelper{}.GenerateKeys(encryption.Bit2048)
+	encrypted, err := EncryptValueWithPublicKey(publicKey, "wrapped")
+	config := New().RegisterDefaults()
+	config.SetPrivateKey(privateKey)
+	config.UpsertKey("TOKEN", encrypted)
+
+	// Act
+	value := config.GetString("TOKEN")
+
+	// Assert
+	assert.Nil(t, err)
+	assert.Contains(t, encrypted, "enc:RSA-AES256GCM:")
+	assert.Equal(t, "wrapped", value)
+}
+
+func TestSecrets_InvalidValuesReturnErrors(t *testing.T) {
+	// Arrange
+	t.Setenv(constants.CONFIG_MASTER_KEY_ENVIRONMENT, "")
+	t.Setenv(constants.CONFIG_MASTER_KEY_FILE_ENVIRONMENT, "")
+	otherKey, _ := encryption.AESHelper{}.GenerateKey(encryption.Bit256)
+	encrypted, _ := EncryptValue(otherKey, "value")
+	config := New().RegisterDefaults()
+	config.UpsertKey("SECRET", encrypted)
+
+	// Act
+	_, notConfigured := config.Decrypt(encrypted)
+	masterKey, _ := encryption.AESHelper{}.GenerateKey(encryption.Bit256)
+	config.SetMasterKey(masterKey)
+	_, wrongKey := config.Decrypt(encrypted)
+	_, unsupported := config.Decrypt("enc:ROT13:value")
+	plain, plainErr := config.Decrypt("plain")
+
+	// Assert
+	assert.ErrorIs(t, notConfigured, ErrMasterKeyNotConfigured)
+	assert.NotNil(t, wrongKey)
+	assert.NotNil(t, unsupported)
+	assert.Nil(t, plainErr)
+	assert.Equal(t, "plain", plain)
+	assert.Nil(t, config.Get("SECRET"))
+	assert.ErrorIs(t, config.SetMasterKey([]byte("short")), ErrInvalidMasterKey)
+}
+
+func TestSecrets_MasterKeyCanBeBase64OrRaw(t *testing.T) {
+	// Arrange
+	encoded, _ := GenerateMasterKey()
+	decoded, _ := base64.StdEncoding.DecodeString(encoded)
+	raw := "abcdefghijklmnopqrstuvwxyz012345"
+	t.Setenv(constants.CONFIG_MASTER_KEY_FILE_ENVIRONMENT, "")
+	tests := map[string][]byte{encoded: decoded, raw: []byte(raw)}
+
+	for content, key := range tests {
+		t.Setenv(constants.CONFIG_MASTER_KEY_ENVIRONMENT, content)
+		encrypted, _ := EncryptValue(key, "value")
+
+		// Act
+		value, err := New().Decrypt(encrypted)
+
+		// Assert
+		assert.Nil(t, err, content)
+		assert.Equal(t, "value", value, content)
+	}
+}
+
+func TestSecrets_EncryptUsesTheConfiguredMasterKey(t *testing.T) {
+	// Arrange
+	masterKey, _ := GenerateMasterKey()
+	t.Setenv(constants.CONFIG_MASTER_KEY_ENVIRONMENT, masterKey)
+	t.Setenv(constants.CONFIG_MASTER_KEY_FILE_ENVIRONMENT, "")
+	config := New().RegisterDefaults()
+
+	// Act
+	encrypted, err := config.Encrypt("round-trip")
+	decrypted, decryptErr := New().Decrypt(encrypted)
+	t.Setenv(constants.CONFIG_MASTER_KEY_ENVIRONMENT, "")
+	_, missingErr := New().Encrypt("value")
+
+	// Assert
+	assert.Nil(t, err)
+	assert.Contains(t, encrypted, "enc:AES256GCM:")
+	assert.Nil(t, decryptErr)
+	assert.Equal(t, "round-trip", decrypted)
+	assert.ErrorIs(t, missingErr, ErrMasterKeyNotConfigured)
+}
@@ -0,0 +1,163 @@
+package configuration
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"sync"
+
+	"github.com/cjlapao/common-go/guard"
+	"gopkg.in/yaml.v3"
+)
+
+// SnapshotEntry is the effective value of a key and the provider that supplied it, masked
+// entries keep an in memory hash of the real value so Diff can detect changes, the hash is
+// never serialized
+type SnapshotEntry struct {
+	Value  interface{} `json:"value" yaml:"value"`
+	Source string      `json:"source" yaml:"source"`
+	Masked bool        `json:"masked,omitempty" yaml:"masked,omitempty"`
+	Error  string      `json:"error,omitempty" yaml:"error,omitempty"`
+	hash   string
+}
+
+var (
+	snapshotHashOnce sync.Once
+	snapshotHashKey  []byte
+)
+
+// Snapshot holds the effective configuration by key
+type Snapshot map[string]SnapshotEntry
+
+// SnapshotChange describes a key that differs between two snapshots, Old is nil for
+// added keys and New is nil for removed keys
+type SnapshotChange struct {
+	Key string         `json:"key" yaml:"key"`
+	Old *SnapshotEntry `json:"old,omitempty" yaml:"old,omitempty"`
+	New *SnapshotEntry `json:"new,omitempty" yaml:"new,omitempty"`
+}
+
+// SnapshotDiff lists the changes between two snapshots ordered by key
+type SnapshotDiff struct {
+	Added   []SnapshotChange `json:"added" yaml:"added"`
+	Removed []SnapshotChange `json:"removed" yaml:"removed"`
+	Changed []SnapshotChange `json:"changed" yaml:"changed"`
+}
+
+// Snapshot Returns the effective value and source of every key listed by the providers and
+// of the defined keys, values of secret or encrypted keys and values that reference them are
+// masked
+func (c *ConfigurationService) Snapshot() Snapshot {
+	keys := c.keysWithPrefix("")
+	for _, definition := range c.Definitions() {
+		keys = append(keys, definition.Key)
+	}
+
+	result := make(Snapshot, len(keys))
+	for _, key := range keys {
+		if _, exists := result[key]; exists {
+			continue
+		}
+
+		raw, source := c.lookupWithSource(key)
+		if guard.IsNill(raw) {
+			continue
+		}
+
+		entry := SnapshotEntry{Source: source}
+		value, secret, err := c.resolveMasked(key)
+		switch {
+		case secret:
+			entry.Value = maskedValue
+			entry.Masked = true
+			if err != nil {
+				entry.Error = err.Error()
+			} else {
+				entry.hash = hashValue(value)
+			}
+		case err != nil:
+			entry.Error = err.Error()
+		default:
+			entry.Value = value
+		}
+		result[key] = entry
+	}
+
+	return result
+}
+
+// ToJSON Returns the snapshot as indented JSON
+func (s Snapshot) ToJSON() ([]byte, error) {
+	return json.MarshalIndent(s, "", "  ")
+}
+
+// ToYAML Returns the snapshot as YAML
+func (s Snapshot) ToYAML() ([]byte, error) {
+	return yaml.Marshal(s)
+}
+
+// Diff Compares two snapshots, a key is changed when its value differs. Masked values taken
+// by this process are compared by the hash of their real value, masked values read back from
+// JSON or YAML have no hash and are only compared by their presence
+func Diff(a Snapshot, b Snapshot) SnapshotDiff {
+	result := SnapshotDiff{
+		Added:   make([]SnapshotChange, 0),
+		Removed: make([]SnapshotChange, 0),
+		Changed: make([]SnapshotChange, 0),
+	}
+
+	for _, key := range sortedSnapshotKeys(a) {
+		oldEntry := a[key]
+		newEntry, exists := b[key]
+		switch {
+		case !exists:
+			result.Removed = append(result.Removed, SnapshotChange{Key: key, Old: &oldEntry})
+		case !reflect.DeepEqual(oldEntry.Value, newEntry.Value) || oldEntry.hash != newEntry.hash || oldEntry.Error != newEntry.Error:
+			result.Changed = append(result.Changed, SnapshotChange{Key: key, Old: &oldEntry, New: &newEntry})
+		}
+	}
+
+	for _, key := range sortedSnapshotKeys(b) {
+		if _, exists := a[key]; !exists {
+			newEntry := b[key]
+			result.Added = append(result.Added, SnapshotChange{Key: key, New: &newEntry})
+		}
+	}
+
+	return result
+}
+
+// HasChanges Returns true if any key was added, removed or changed
+func (d SnapshotDiff) HasChanges() bool {
+	return len(d.Added) > 0 || len(d.Removed) > 0 || len(d.Changed) > 0
+}
+
+// hashValue returns the hex HMAC-SHA256 of the value as text, keyed with a random key that
+// only lives in this process
+func hashValue(value interface{}) string {
+	snapshotHashOnce.Do(func() {
+		snapshotHashKey = make([]byte, 32)
+		if _, err := rand.Read(snapshotHashKey); err != nil {
+			panic(err)
+		}
+	})
+
+	mac := hmac.New(sha256.New, snapshotHashKey)
+	mac.Write([]byte(fmt.Sprint(value)))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+func sortedSnapshotKeys(snapshot Snapshot) []string {
+	result := make([]string, 0, len(snapshot))
+	for key := range snapshot {
+		result = append(result, key)
+	}
+	sort.Strings(result)
+
+	return result
+}
@@ -0,0 +1,97 @@
+package configuration
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSnapshot_RecordsValuesSourcesAndMasksSecrets(t *testing.T) {
+	// Arrange
+	config := New()
+	config.RegisterProvider(NewCachedVaultConfigurationProvider())
+	config.RegisterProvider(newTestFileProvider(t, "settings.env", "SNAPSHOT_HOST=db.local\nSNAPSHOT_URL=http://${SNAPSHOT_HOST}\n"))
+	config.UpsertKeys(map[string]interface{}{
+		"SNAPSHOT_DB_PASSWORD": "hunter2",
+		"SNAPSHOT_PORT":        5432,
+		"SNAPSHOT_CYCLE":       "${SNAPSHOT_CYCLE}",
+	})
+
+	// Act
+	snapshot := config.Snapshot()
+	content, jsonErr := snapshot.ToJSON()
+	yamlContent, yamlErr := snapshot.ToYAML()
+
+	// Assert
+	assert.Equal(t, SnapshotEntry{Value: "db.local", Source: config.ProviderRegistrations()[1].Name}, snapshot["SNAPSHOT_HOST"])
+	assert.Equal(t, "http://db.local", snapshot["SNAPSHOT_URL"].Value)
+	assert.Equal(t, SnapshotEntry{Value: 5432, Source: "CachedVault"}, snapshot["SNAPSHOT_PORT"])
+	assert.Equal(t, SnapshotEntry{Value: maskedValue, Source: "CachedVault", Masked: true, hash: hashValue("hunter2")}, snapshot["SNAPSHOT_DB_PASSWORD"])
+	assert.Contains(t, snapshot["SNAPSHOT_CYCLE"].Error, "reference cycle")
+	assert.Nil(t, jsonErr)
+	assert.NotContains(t, string(content), "hunter2")
+	decoded := make(map[string]SnapshotEntry)
+	assert.Nil(t, json.Unmarshal(content, &decoded))
+	assert.Equal(t, "db.local", decoded["SNAPSHOT_HOST"].Value)
+	assert.Nil(t, yamlErr)
+	assert.True(t, strings.Contains(string(yamlContent), "SNAPSHOT_HOST:\n    value: db.local\n"))
+}
+
+func TestDiff_ReportsAddedRemovedAndChangedKeys(t *testing.T) {
+	// Arrange
+	a := Snapshot{
+		"SAME":    {Value: "1", Source: "Environment"},
+		"CHANGED": {Value: "old", Source: "Environment"},
+		"REMOVED": {Value: "gone", Source: "CachedVault"},
+		"SOURCE":  {Value: "x", Source: "Environment"},
+	}
+	b := Snapshot{
+		"SAME":    {Value: "1", Source: "Environment"},
+		"CHANGED": {Value: "new", Source: "File"},
+		"ADDED":   {Value: "here", Source: "Flags"},
+		"SOURCE":  {Value: "x", Source: "File"},
+	}
+
+	// Act
+	diff := Diff(a, b)
+	empty := Diff(a, a)
+
+	// Assert
+	assert.True(t, diff.HasChanges())
+	assert.Equal(t, []SnapshotChange{{Key: "ADDED", New: &SnapshotEntry{Value: "here", Source: "Flags"}}}, diff.Added)
+	assert.Equal(t, []SnapshotChange{{Key: "REMOVED", Old: &SnapshotEntry{Value: "gone", Source: "CachedVault"}}}, diff.Removed)
+	assert.Len(t, diff.Changed, 1)
+	assert.Equal(t, "CHANGED", diff.Changed[0].Key)
+	assert.Equal(t, "old", diff.Changed[0].Old.Value)
+	assert.Equal(t, "new", diff.Changed[0].New.Value)
+	assert.False(t, empty.HasChanges())
+}
+
+func TestSnapshot_MasksReferencesAndDiffsChangedSecrets(t *testing.T) {
+	// Arrange
+	config := New()
+	config.RegisterProvider(NewCachedVaultConfigurationProvider())
+	config.UpsertKeys(map[string]interface{}{
+		"SNAPSHOT_REF_PASSWORD": "hunter2",
+		"SNAPSHOT_REF_URL":      "postgres://u:${SNAPSHOT_REF_PASSWORD}@h",
+	})
+	before := config.Snapshot()
+	config.UpsertKey("SNAPSHOT_REF_PASSWORD", "hunter3")
+
+	// Act
+	after := config.Snapshot()
+	diff := Diff(before, after)
+	content, _ := after.ToJSON()
+
+	// Assert
+	assert.True(t, after["SNAPSHOT_REF_URL"].Masked)
+	assert.Equal(t, maskedValue, after["SNAPSHOT_REF_URL"].Value)
+	assert.NotContains(t, string(content), "hunter")
+	assert.NotContains(t, string(content), after["SNAPSHOT_REF_PASSWORD"].hash)
+	assert.Len(t, diff.Changed, 2)
+	assert.Equal(t, "SNAPSHOT_REF_PASSWORD", diff.Changed[0].Key)
+	assert.Equal(t, "SNAPSHOT_REF_URL", diff.Changed[1].Key)
+	assert.False(t, Diff(after, config.Snapshot()).HasChanges())
+}
@@ -0,0 +1,563 @@
+package configuration
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// tomlParser is a small TOML v1.0 reader covering tables, arrays of tables, dotted keys,
+// inline tables, arrays and all the string and number forms. Dates are kept as strings.
+type tomlParser struct {
+	data string
+	pos  int
+	line int
+}
+
+func parseToml(content []byte) (map[string]interface{}, error) {
+	p := tomlParser{data: string(content), line: 1}
+	root := make(map[string]interface{})
+	current := root
+
+	for {
+		p.skipBlank(true)
+		if p.eof() {
+			return root, nil
+		}
+
+		if p.peek() == '[' {
+			isArray := strings.HasPrefix(p.data[p.pos:], "[[")
+			if isArray {
+				p.pos += 2
+			} else {
+				p.pos++
+			}
+
+			path, err := p.parseKey()
+			if err != nil {
+				return nil, err
+			}
+
+			closing := "]"
+			if isArray {
+				closing = "]]"
+			}
+			p.skipBlank(false)
+			if !strings.HasPrefix(p.data[p.pos:], closing) {
+				return nil, p.errorf("expected %v", closing)
+			}
+			p.pos += len(closing)
+
+			if isArray {
+				current, err = tomlAppendTable(root, path)
+			} else {
+				current, err = tomlTable(root, path)
+			}
+			if err != nil {
+				return nil, p.errorf("%v", err)
+			}
+		} else {
+			path, err := p.parseKey()
+			if err != nil {
+				return nil, err
+			}
+
+			p.skipBlank(false)
+			if p.eof() || p.peek() != '=' {
+				return nil, p.errorf("expected '=' after key %v", strings.Join(path, "."))
+			}
+			p.pos++
+			p.skipBlank(false)
+
+			value, err := p.parseValue()
+			if err != nil {
+				return nil, err
+			}
+
+			if err := tomlSet(current, path, value); err != nil {
+				return nil, p.errorf("%v", err)
+			}
+		}
+
+		if err := p.endOfLine(); err != nil {
+			return nil, err
+		}
+	}
+}
+
+func (p *tomlParser) eof() bool {
+	return p.pos >= len(p.data)
+}
+
+func (p *tomlParser) peek() byte {
+	return p.data[p.pos]
+}
+
+func (p *tomlParser) errorf(format string, args ...interface{}) error {
+	return fmt.Errorf("toml: line %d: %v", p.line, fmt.Sprintf(format, args...))
+}
+
+// skipBlank skips spaces, tabs and comments, and newlines when multiline is set
+func (p *tomlParser) skipBlank(multiline bool) {
+	for !p.eof() {
+		switch p.peek() {
+		case ' ', '\t', '\r':
+			p.pos++
+		case '\n':
+			if !multiline {
+				return
+			}
+			p.line++
+			p.pos++
+		case '#':
+			for !p.eof() && p.peek() != '\n' {
+				p.pos++
+			}
+		default:
+			return
+		}
+	}
+}
+
+func (p *tomlParser) endOfLine() error {
+	p.skipBlank(false)
+	if p.eof() {
+		return nil
+	}
+	if p.peek() != '\n' {
+		return p.errorf("unexpected character %q", p.peek())
+	}
+
+	return nil
+}
+
+func (p *tomlParser) parseKey() ([]string, error) {
+	path := make([]string, 0)
+	for {
+		p.skipBlank(false)
+		if p.eof() {
+			return nil, p.errorf("unexpected end of file in key")
+		}
+
+		var part string
+		var err error
+		switch p.peek() {
+		case '"':
+			part, err = p.parseBasicString()
+		case '\'':
+			part, err = p.parseLiteralString()
+		default:
+			start := p.pos
+			for !p.eof() && isTomlBareKeyChar(p.peek()) {
+				p.pos++
+			}
+			if start == p.pos {
+				return nil, p.errorf("invalid key character %q", p.peek())
+			}
+			part = p.data[start:p.pos]
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		path = append(path, part)
+		p.skipBlank(false)
+		if p.eof() || p.peek() != '.' {
+			return path, nil
+		}
+		p.pos++
+	}
+}
+
+func isTomlBareKeyChar(c byte) bool {
+	return c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' || c == '_' || c == '-'
+}
+
+func (p *tomlParser) parseValue() (interface{}, error) {
+	if p.eof() {
+		return nil, p.errorf("missing value")
+	}
+
+	switch p.peek() {
+	case '"':
+		if strings.HasPrefix(p.data[p.pos:], `"""`) {
+			return p.parseMultilineBasicString()
+		}
+		return p.parseBasicString()
+	case '\'':
+		if strings.HasPrefix(p.data[p.pos:], `'''`) {
+			return p.parseMultilineLiteralString()
+		}
+		return p.parseLiteralString()
+	case '[':
+		return p.parseArray()
+	case '{':
+		return p.parseInlineTable()
+	default:
+		return p.parseScalar()
+	}
+}
+
+func (p *tomlParser) parseBasicString() (string, error) {
+	p.pos++
+	var result strings.Builder
+	for {
+		if p.eof() || p.peek() == '\n' {
+			return "", p.errorf("unterminated string")
+		}
+
+		c := p.peek()
+		switch c {
+		case '"':
+			p.pos++
+			return result.String(), nil
+		case '\\':
+			if err := p.parseEscape(&result); err != nil {
+				return "", err
+			}
+		default:
+			result.WriteByte(c)
+			p.pos++
+		}
+	}
+}
+
+func (p *tomlParser) parseMultilineBasicString() (string, error) {
+	p.pos += 3
+	if strings.HasPrefix(p.data[p.pos:], "\r\n") {
+		p.pos += 2
+		p.line++
+	} else if !p.eof() && p.peek() == '\n' {
+		p.pos++
+		p.line++
+	}
+
+	var result strings.Builder
+	for {
+		if p.eof() {
+			return "", p.errorf("unterminated multiline string")
+		}
+
+		if strings.HasPrefix(p.data[p.pos:], `"""`) {
+			p.pos += 3
+			// up to two extra quotes are allowed right before the delimiter
+			for i := 0; i < 2 && !p.eof() && p.peek() == '"'; i++ {
+				result.WriteByte('"')
+				p.pos++
+			}
+			return result.String(), nil
+		}
+
+		c := p.peek()
+		if c == '\\' {
+			// a line ending backslash trims all the following whitespace
+			rest := strings.TrimLeft(p.data[p.pos+1:], " \t\r")
+			if strings.HasPrefix(rest, "\n") {
+				p.pos++
+				for !p.eof() && strings.ContainsRune(" \t\r\n", rune(p.peek())) {
+					if p.peek() == '\n' {
+						p.line++
+					}
+					p.pos++
+				}
+				continue
+			}
+
+			if err := p.parseEscape(&result); err != nil {
+				return "", err
+			}
+			continue
+		}
+
+		if c == '\n' {
+			p.line++
+		}
+		result.WriteByte(c)
+		p.pos++
+	}
+}
+
+func (p *tomlParser) parseEscape(result *strings.Builder) error {
+	p.pos++
+	if p.eof() {
+		return p.errorf("invalid escape sequence")
+	}
+
+	c := p.peek()
+	p.pos++
+	switch c {
+	case 'b':
+		result.WriteByte('\b')
+	case 't':
+		result.WriteByte('\t')
+	case 'n':
+		result.WriteByte('\n')
+	case 'f':
+		result.WriteByte('\f')
+	case 'r':
+		result.WriteByte('\r')
+	case '"':
+		result.WriteByte('"')
+	case '\\':
+		result.WriteByte('\\')
+	case 'u', 'U':
+		size := 4
+		if c == 'U' {
+			size = 8
+		}
+		if p.pos+size > len(p.data) {
+			return p.errorf("invalid unicode escape")
+		}
+		code, err := strconv.ParseUint(p.data[p.pos:p.pos+size], 16, 32)
+		if err != nil || !utf8.ValidRune(rune(code)) {
+			return p.errorf("invalid unicode escape")
+		}
+		result.WriteRune(rune(code))
+		p.pos += size
+	default:
+		return p.errorf("invalid escape sequence \\%c", c)
+	}
+
+	return nil
+}
+
+func (p *tomlParser) parseLiteralString() (string, error) {
+	p.pos++
+	start := p.pos
+	for {
+		if p.eof() || p.peek() == '\n' {
+			return "", p.errorf("unterminated string")
+		}
+		if p.peek() == '\'' {
+			value := p.data[start:p.pos]
+			p.pos++
+			return value, nil
+		}
+		p.pos++
+	}
+}
+
+func (p *tomlParser) parseMultilineLiteralString() (string, error) {
+	p.pos += 3
+	if strings.HasPrefix(p.data[p.pos:], "\r\n") {
+		p.pos += 2
+		p.line++
+	} else if !p.eof() && p.peek() == '\n' {
+		p.pos++
+		p.line++
+	}
+
+	end := strings.Index(p.data[p.pos:], `'''`)
+	if end < 0 {
+		return "", p.errorf("unterminated multiline string")
+	}
+
+	value := p.data[p.pos : p.pos+end]
+	p.pos += end + 3
+	for i := 0; i < 2 && !p.eof() && p.peek() == '\''; i++ {
+		value += "'"
+		p.pos++
+	}
+	p.line += strings.Count(value, "\n")
+
+	return value, nil
+}
+
+func (p *tomlParser) parseArray() (interface{}, error) {
+	p.pos++
+	result := make([]interface{}, 0)
+	for {
+		p.skipBlank(true)
+		if p.eof() {
+			return nil, p.errorf("unterminated array")
+		}
+		if p.peek() == ']' {
+			p.pos++
+			return result, nil
+		}
+
+		value, err := p.parseValue()
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, value)
+
+		p.skipBlank(true)
+		if p.eof() {
+			return nil, p.errorf("unterminated array")
+		}
+		switch p.peek() {
+		case ',':
+			p.pos++
+		case ']':
+			p.pos++
+			return result, nil
+		default:
+			return nil, p.errorf("expected ',' or ']' in array")
+		}
+	}
+}
+
+func (p *tomlParser) parseInlineTable() (interface{}, error) {
+	p.pos++
+	result := make(map[string]interface{})
+	p.skipBlank(false)
+	if !p.eof() && p.peek() == '}' {
+		p.pos++
+		return result, nil
+	}
+
+	for {
+		path, err := p.parseKey()
+		if err != nil {
+			return nil, err
+		}
+
+		p.skipBlank(false)
+		if p.eof() || p.peek() != '=' {
+			return nil, p.errorf("expected '=' in inline table")
+		}
+		p.pos++
+		p.skipBlank(false)
+
+		value, err := p.parseValue()
+		if err != nil {
+			return nil, err
+		}
+		if err := tomlSet(result, path, value); err != nil {
+			return nil, p.errorf("%v", err)
+		}
+
+		p.skipBlank(false)
+		if p.eof() {
+			return nil, p.errorf("unterminated inline table")
+		}
+		switch p.peek() {
+		case ',':
+			p.pos++
+		case '}':
+			p.pos++
+			return result, nil
+		default:
+			return nil, p.errorf("expected ',' or '}' in inline table")
+		}
+	}
+}
+
+func (p *tomlParser) parseScalar() (interface{}, error) {
+	start := p.pos
+	for !p.eof() && !strings.ContainsRune(" \t\r\n,]}#", rune(p.peek())) {
+		p.pos++
+	}
+
+	// local date times can use a space instead of the T separator
+	if p.pos-start == 10 && p.pos+1 < len(p.data) && p.peek() == ' ' && p.data[p.pos+1] >= '0' && p.data[p.pos+1] <= '9' {
+		p.pos++
+		for !p.eof() && !strings.ContainsRune(" \t\r\n,]}#", rune(p.peek())) {
+			p.pos++
+		}
+	}
+
+	token := p.data[start:p.pos]
+	if token == "" {
+		return nil, p.errorf("missing value")
+	}
+
+	switch token {
+	case "true":
+		return true, nil
+	case "false":
+		return false, nil
+	case "inf", "+inf":
+		return math.Inf(1), nil
+	case "-inf":
+		return math.Inf(-1), nil
+	case "nan", "+nan", "-nan":
+		return math.NaN(), nil
+	}
+
+	number := strings.ReplaceAll(token, "_", "")
+	if strings.HasPrefix(number, "0x") || strings.HasPrefix(number, "0o") || strings.HasPrefix(number, "0b") {
+		value, err := strconv.ParseInt(number, 0, 64)
+		if err != nil {
+			return nil, p.errorf("invalid number %v", token)
+		}
+		return value, nil
+	}
+	if value, err := strconv.ParseInt(number, 10, 64); err == nil {
+		return value, nil
+	}
+	if value, err := strconv.ParseFloat(number, 64); err == nil {
+		return value, nil
+	}
+
+	if token[0] >= '0' && token[0] <= '9' && (strings.Contains(token, "-") || strings.Contains(token, ":")) {
+		return token, nil
+	}
+
+	return nil, p.errorf("invalid value %v", token)
+}
+
+func tomlTable(root map[string]interface{}, path []string) (map[string]interface{}, error) {
+	current := root
+	for _, part := range path {
+		switch existing := current[part].(type) {
+		case nil:
+			table := make(map[string]interface{})
+			current[part] = table
+			current = table
+		case map[string]interface{}:
+			current = existing
+		case []interface{}:
+			if len(existing) == 0 {
+				return nil, fmt.Errorf("key %v is not a table", part)
+			}
+			table, ok := existing[len(existing)-1].(map[string]interface{})
+			if !ok {
+				return nil, fmt.Errorf("key %v is not a table", part)
+			}
+			current = table
+		default:
+			return nil, fmt.Errorf("key %v is already defined", part)
+		}
+	}
+
+	return current, nil
+}
+
+func tomlAppendTable(root map[string]interface{}, path []string) (map[string]interface{}, error) {
+	parent, err := tomlTable(root, path[:len(path)-1])
+	if err != nil {
+		return nil, err
+	}
+
+	key := path[len(path)-1]
+	table := make(map[string]interface{})
+	switch existing := parent[key].(type) {
+	case nil:
+		parent[key] = []interface{}{table}
+	case []interface{}:
+		parent[key] = append(existing, table)
+	default:
+		return nil, fmt.Errorf("key %v is not an array of tables", key)
+	}
+
+	return table, nil
+}
+
+func tomlSet(table map[string]interface{}, path []string, value interface{}) error {
+	parent, err := tomlTable(table, path[:len(path)-1])
+	if err != nil {
+		return err
+	}
+
+	key := path[len(path)-1]
+	if _, exists := parent[key]; exists {
+		return fmt.Errorf("key %v is already defined", strings.Join(path, "."))
+	}
+	parent[key] = value
+
+	return nil
+}
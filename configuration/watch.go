@@ -0,0 +1,173 @@
+package configuration
+
+import (
+	"reflect"
+	"strings"
+)
+
+// ChangeHandler is called with the previous and the new effective value of a key,
+// a nil value means the key was not set
+type ChangeHandler func(oldValue interface{}, newValue interface{})
+
+// ChangeNotifier is implemented by providers that report their own changes, for example when
+// a file is reloaded, a key is written to the provider directly or a vault key expires
+type ChangeNotifier interface {
+	OnChange(handler func(keys []string))
+}
+
+// KeysProvider is implemented by providers that can list the keys they hold
+type KeysProvider interface {
+	Keys() []string
+}
+
+type configurationWatcher struct {
+	id      int
+	key     string
+	prefix  bool
+	handler ChangeHandler
+}
+
+func (w *configurationWatcher) matches(key string) bool {
+	if w.prefix {
+		return strings.HasPrefix(key, w.key)
+	}
+
+	return key == w.key
+}
+
+// Watch Subscribes to the changes of the effective value of a key, a key ending with *
+// watches every key starting with that prefix, for example LOG_*.
+// Changes made through the service and the changes reported by ChangeNotifier providers are
+// seen, keys expired by the redis server are only seen on the next reported change.
+// Handlers run synchronously on the goroutine that caused the change, the returned
+// function cancels the subscription
+func (c *ConfigurationService) Watch(key string, handler ChangeHandler) func() {
+	if key == "" || handler == nil {
+		return func() {}
+	}
+
+	watcher := &configurationWatcher{key: key, handler: handler}
+	if strings.HasSuffix(key, "*") {
+		watcher.prefix = true
+		watcher.key = strings.TrimSuffix(key, "*")
+	}
+
+	keys := []string{watcher.key}
+	if watcher.prefix {
+		keys = c.keysWithPrefix(watcher.key)
+	}
+	values := make(map[string]interface{}, len(keys))
+	for _, watchedKey := range keys {
+		values[watchedKey] = c.Get(watchedKey)
+	}
+
+	c.watchMutex.Lock()
+	c.watcherSequence++
+	watcher.id = c.watcherSequence
+	c.watchers = append(c.watchers, watcher)
+	if c.watchedValues == nil {
+		c.watchedValues = make(map[string]interface{})
+	}
+	for watchedKey, value := range values {
+		if _, tracked := c.watchedValues[watchedKey]; !tracked {
+			c.watchedValues[watchedKey] = value
+		}
+	}
+	c.watchMutex.Unlock()
+
+	return func() {
+		c.watchMutex.Lock()
+		defer c.watchMutex.Unlock()
+		for i, item := range c.watchers {
+			if item.id == watcher.id {
+				c.watchers = append(c.watchers[:i], c.watchers[i+1:]...)
+				break
+			}
+		}
+	}
+}
+
+// notifyChanged re-evaluates the watched keys and the given keys, calling the handlers of
+// every key whose effective value changed
+func (c *ConfigurationService) notifyChanged(keys ...string) {
+	c.watchMutex.Lock()
+	if len(c.watchers) == 0 {
+		c.watchMutex.Unlock()
+		return
+	}
+
+	candidates := make(map[string]bool)
+	for key := range c.watchedValues {
+		candidates[key] = true
+	}
+	for _, key := range keys {
+		for _, watcher := range c.watchers {
+			if watcher.matches(key) {
+				candidates[key] = true
+				break
+			}
+		}
+	}
+	c.watchMutex.Unlock()
+
+	newValues := make(map[string]interface{}, len(candidates))
+	for key := range candidates {
+		newValues[key] = c.Get(key)
+	}
+
+	type pendingCall struct {
+		handler  ChangeHandler
+		oldValue interface{}
+		newValue interface{}
+	}
+
+	calls := make([]pendingCall, 0)
+	c.watchMutex.Lock()
+	for key, newValue := range newValues {
+		oldValue := c.watchedValues[key]
+		if reflect.DeepEqual(oldValue, newValue) {
+			continue
+		}
+
+		c.watchedValues[key] = newValue
+		for _, watcher := range c.watchers {
+			if watcher.matches(key) {
+				calls = append(calls, pendingCall{watcher.handler, oldValue, newValue})
+			}
+		}
+	}
+	c.watchMutex.Unlock()
+
+	for _, call := range calls {
+		call.handler(call.oldValue, call.newValue)
+	}
+}
+
+// subscribeToProvider forwards the changes of providers that report them
+func (c *ConfigurationService) subscribeToProvider(provider ConfigurationProvider) {
+	if notifier, ok := provider.(ChangeNotifier); ok {
+		notifier.OnChange(func(keys []string) {
+			c.notifyChanged(keys...)
+		})
+	}
+}
+
+func (c *ConfigurationService) keysWithPrefix(prefix string) []string {
+	result := make([]string, 0)
+	seen := make(map[string]bool)
+	for _, provider := range c.providers() {
+		keysProvider, ok := provider.(KeysProvider)
+		if !ok {
+			continue
+		}
+
+		for _, key := range keysProvider.Keys() {
+			if strings.HasPrefix(key, prefix) && !seen[key] {
+				seen[key] = true
+				result = append(result, key)
+			}
+		}
+	}
+
+	return result
+}
@@ -0,0 +1,189 @@
+package configuration
+
+import (
+	"os"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type recordedChange struct {
+	oldValue interface{}
+	newValue interface{}
+}
+
+type changeRecorder struct {
+	mutex   sync.Mutex
+	changes []recordedChange
+}
+
+func (r *changeRecorder) handler(oldValue interface{}, newValue interface{}) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	r.changes = append(r.changes, recordedChange{oldValue, newValue})
+}
+
+func (r *changeRecorder) recorded() []recordedChange {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	result := make([]recordedChange, len(r.changes))
+	copy(result, r.changes)
+	return result
+}
+
+func TestWatch_NotifiesKeyChanges(t *testing.T) {
+	// Arrange
+	config := New().RegisterDefaults()
+	config.UpsertKey("WATCH_LEVEL", "info")
+	recorder := &changeRecorder{}
+	cancel := config.Watch("WATCH_LEVEL", recorder.handler)
+
+	// Act
+	config.UpsertKey("WATCH_LEVEL", "debug")
+	config.UpsertKey("WATCH_LEVEL", "debug")
+	config.UpsertKey("WATCH_OTHER", "value")
+	config.Clear("WATCH_LEVEL")
+	cancel()
+	config.UpsertKey("WATCH_LEVEL", "trace")
+
+	// Assert
+	assert.Equal(t, []recordedChange{
+		{"info", "debug"},
+		{"debug", nil},
+	}, recorder.recorded())
+}
+
+func TestWatch_PrefixNotifiesEveryMatchingKey(t *testing.T) {
+	// Arrange
+	config := New().RegisterDefaults()
+	config.UpsertKey("FEATURE_A", "on")
+	recorder := &changeRecorder{}
+	config.Watch("FEATURE_*", recorder.handler)
+
+	// Act
+	config.UpsertKey("FEATURE_A", "off")
+	config.UpsertKeys(map[string]interface{}{"FEATURE_B": "on", "OTHER": "on"})
+
+	// Assert
+	assert.ElementsMatch(t, []recordedChange{
+		{"on", "off"},
+		{nil, "on"},
+	}, recorder.recorded())
+}
+
+func TestWatch_NotifiesWhenProviderRegistrationChangesValue(t *testing.T) {
+	// Arrange
+	config := New().RegisterDefaults()
+	recorder := &changeRecorder{}
+	config.Watch("WATCH_REGISTERED", recorder.handler)
+
+	// Act
+	config.RegisterProviderWithPriority(newTestFileProvider(t, "settings.env", "WATCH_REGISTERED=file\n"), PriorityHighest)
+
+	// Assert
+	assert.Equal(t, []recordedChange{{nil, "file"}}, recorder.recorded())
+}
+
+func TestWatch_FileReloadNotifiesChanges(t *testing.T) {
+	// Arrange
+	path := writeTestFile(t, "settings.yaml", "logging:\n  level: info\n")
+	provider := NewFileConfigurationProvider()
+	provider.AddFile(path)
+	config := New()
+	config.RegisterProvider(provider)
+	recorder := &changeRecorder{}
+	config.Watch("LOGGING__LEVEL", recorder.handler)
+
+	// Act
+	os.WriteFile(path, []byte("logging:\n  level: debug\n"), 0o600)
+	err := provider.Reload()
+
+	// Assert
+	assert.Nil(t, err)
+	assert.Equal(t, []recordedChange{{"info", "debug"}}, recorder.recorded())
+}
+
+func TestFileProvider_WatchFilesReloadsChangedFiles(t *testing.T) {
+	// Arrange
+	path := writeTestFile(t, "settings.json", `{"level": "info"}`)
+	provider := NewFileConfigurationProvider()
+	provider.AddFile(path)
+	changed := make(chan []string, 10)
+	provider.OnChange(func(keys []string) { changed <- keys })
+
+	// Act
+	provider.WatchFiles(10*time.Millisecond, 20*time.Millisecond)
+	defer provider.StopWatching()
+	os.WriteFile(path, []byte(`{"level": "debug", "extra": true}`), 0o600)
+	os.Chtimes(path, time.Now().Add(time.Second), time.Now().Add(time.Second))
+
+	// Assert
+	select {
+	case keys := <-changed:
+		assert.ElementsMatch(t, []string{"level", "extra"}, keys)
+		assert.Equal(t, "debug", provider.Get("level"))
+	case <-time.After(2 * time.Second):
+		t.Fatal("file change was not detected")
+	}
+}
+
+func TestFileProvider_WatchFilesKeepsValuesWhenReloadFails(t *testing.T) {
+	// Arrange
+	path := writeTestFile(t, "settings.json", `{"level": "info"}`)
+	provider := NewFileConfigurationProvider()
+	provider.AddFile(path)
+
+	// Act
+	os.WriteFile(path, []byte(`{"level": `), 0o600)
+	err := provider.Reload()
+
+	// Assert
+	assert.NotNil(t, err)
+	assert.Equal(t, "info", provider.Get("level"))
+}
+
+func TestWatch_NotifiesDirectVaultWritesAndExpiry(t *testing.T) {
+	// Arrange
+	config := New().RegisterDefaults()
+	recorder := &changeRecorder{}
+	config.Watch("WATCH_VAULT_*", recorder.handler)
+
+	// Act
+	config.Vault().UpsertKey("WATCH_VAULT_LEVEL", "info")
+	config.Vault().UpsertKeys(map[string]interface{}{"WATCH_VAULT_LEVEL": "debug"})
+	config.Vault().Clear("WATCH_VAULT_LEVEL")
+	config.Vault().UpsertKeyWithExpiry("WATCH_VAULT_TOKEN", "abc", 20*time.Millisecond)
+	assert.Eventually(t, func() bool { return len(recorder.recorded()) == 5 }, time.Second, 5*time.Millisecond)
+
+	// Assert
+	assert.Equal(t, []recordedChange{
+		{nil, "info"},
+		{"info", "debug"},
+		{"debug", nil},
+		{nil, "abc"},
+		{"abc", nil},
+	}, recorder.recorded())
+}
+
+func TestWatch_NotifiesDirectRedisWrites(t *testing.T) {
+	// Arrange
+	server := newFakeRedisServer(t, "")
+	provider := NewRedisConfigurationProvider(server.address())
+	defer provider.Close()
+	config := New()
+	config.RegisterProvider(provider)
+	recorder := &changeRecorder{}
+	config.Watch("WATCH_REDIS", recorder.handler)
+
+	// Act
+	provider.UpsertKey("WATCH_REDIS", "on")
+	provider.Clear("WATCH_REDIS")
+
+	// Assert
+	assert.Equal(t, []recordedChange{
+		{nil, "on"},
+		{"on", nil},
+	}, recorder.recorded())
+}
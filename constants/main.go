@@ -3,8 +3,14 @@ package constants
 const (
 	DEBUG_ENVIRONMENT = "CJ_ENABLE_DEBUG"
 	TRACE_ENVIRONMENT = "CJ_ENABLE_TRACE"
+	LOG_LEVEL         = "CJ_LOG_LEVEL"
 	ENVIRONMENT       = "CJ_ENVIRONMENT"
 	ID_SIZE           = 45
+
+	CONFIG_MASTER_KEY_ENVIRONMENT       = "CJ_CONFIG_MASTER_KEY"
+	CONFIG_MASTER_KEY_FILE_ENVIRONMENT  = "CJ_CONFIG_MASTER_KEY_FILE"
+	CONFIG_PRIVATE_KEY_ENVIRONMENT      = "CJ_CONFIG_PRIVATE_KEY"
+	CONFIG_PRIVATE_KEY_FILE_ENVIRONMENT = "CJ_CONFIG_PRIVATE_KEY_FILE"
 )
 
 func AlphaCharacters() []string {
@@ -1,7 +1,7 @@
 package execution_context
 
 import (
-	"os"
+	"context"
 	"strings"
 
 	cryptorand "github.com/cjlapao/common-go-cryptorand"
@@ -12,6 +12,7 @@ import (
 	"github.com/cjlapao/common-go/configuration"
 	"github.com/cjlapao/common-go/constants"
 	"github.com/cjlapao/common-go/helper/reflect_helper"
+	"github.com/cjlapao/common-go/log"
 	"github.com/cjlapao/common-go/service_provider"
 )
 
@@ -57,7 +58,7 @@ func InitNewContext(init func() error) (*Context, error) {
 	contextService.TokenCache = jwt_token_cache.New()
 	contextService.CorrelationId = id
 	contextService.Services = service_provider.Get()
-	os.Setenv("CORRELATION_ID", contextService.CorrelationId)
+	log.SetDefaultCorrelationId(contextService.CorrelationId)
 
 	environment := contextService.Configuration.GetString(constants.ENVIRONMENT)
 	debug := contextService.Configuration.GetBool(constants.DEBUG_ENVIRONMENT)
@@ -119,16 +120,22 @@ func (c *Context) WithAuthorization(options authorization_context.AuthorizationO
 func (c *Context) Refresh() *Context {
 	id, _ := cryptorand.GetRandomString(constants.ID_SIZE)
 	c.CorrelationId = id
-	os.Setenv("CORRELATION_ID", c.CorrelationId)
+	log.SetDefaultCorrelationId(c.CorrelationId)
 	return c
 }
 
 func (c *Context) SetCorrelationId(correlationId string) *Context {
 	c.CorrelationId = correlationId
-	os.Setenv("CORRELATION_ID", c.CorrelationId)
+	log.SetDefaultCorrelationId(c.CorrelationId)
 	return c
 }
 
+// WithCorrelationId Returns a copy of the context carrying the correlation id of the execution
+// context, loggers created with log.Get().Ctx(ctx) write it instead of the default one
+func (c *Context) WithCorrelationId(ctx context.Context) context.Context {
+	return log.ContextWithCorrelationId(ctx, c.CorrelationId)
+}
+
 func Get() *Context {
 	if contextService != nil {
 		return contextService
@@ -1,10 +1,12 @@
 package execution_context
 
 import (
+	"context"
 	"errors"
 	"os"
 	"testing"
 
+	"github.com/cjlapao/common-go/log"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -198,6 +200,21 @@ func TestGetContextShouldReturnExistingContext(t *testing.T) {
 	assert.Truef(t, ctx.IsDevelopment, "Development should be true")
 }
 
+func TestSetCorrelationIdShouldNotChangeEnvironment(t *testing.T) {
+	// Arrange
+	t.Setenv("CORRELATION_ID", "")
+	ctx, _ := New()
+
+	// Act
+	ctx.SetCorrelationId("request-1")
+	requestCtx := ctx.WithCorrelationId(context.Background())
+
+	// Assert
+	assert.Equal(t, "", os.Getenv("CORRELATION_ID"))
+	assert.Equal(t, "request-1", log.DefaultCorrelationId())
+	assert.Equal(t, "request-1", log.CorrelationIdFromContext(requestCtx))
+}
+
 func contextInitiation() error {
 	os.Setenv("foo", "bar")
 
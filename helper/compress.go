@@ -145,3 +145,36 @@ func Untar(src string, dst string) error {
 		return errors.New("File does not exist")
 	}
 }
+
+// Gzip compresses the src file into the dst file, the source file is not removed
+func Gzip(src string, dst string) error {
+	if !FileExists(src) {
+		return errors.New("File does not exist")
+	}
+
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	out, err := os.OpenFile(dst, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0o644)
+	if err != nil {
+		return err
+	}
+
+	gzw := gzip.NewWriter(out)
+	gzw.Name = filepath.Base(src)
+	if _, err := io.Copy(gzw, in); err != nil {
+		gzw.Close()
+		out.Close()
+		return err
+	}
+
+	if err := gzw.Close(); err != nil {
+		out.Close()
+		return err
+	}
+
+	return out.Close()
+}
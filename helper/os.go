@@ -37,6 +37,9 @@ func GetCommandAt(index int) string {
 }
 
 // GetFlagValue Gets a value of a flag from the command line arguments
+//
+// Deprecated: use configuration.FlagConfigurationProvider, it reads --name=value flags with
+// the same rules as the other configuration keys
 func GetFlagValue(flag string, defaultValue string) string {
 	result := defaultValue
 	args := os.Args
@@ -79,6 +82,9 @@ func GetFlagValue(flag string, defaultValue string) string {
 }
 
 // GetFlagSwitch Gets a switch value of a flag from the command line arguments
+//
+// Deprecated: use configuration.FlagConfigurationProvider, a switch like --verbose is read
+// as "true"
 func GetFlagSwitch(flag string, defaultValue bool) bool {
 	result := defaultValue
 	args := os.Args
@@ -100,6 +106,9 @@ func GetFlagSwitch(flag string, defaultValue bool) bool {
 }
 
 // GetFlagArrayValue Gets a array of values of a flag from the command line arguments
+//
+// Deprecated: use configuration.FlagConfigurationProvider, repeated flags are joined with
+// commas
 func GetFlagArrayValue(flag string) []string {
 	var result []string
 	args := os.Args
@@ -0,0 +1,432 @@
+package log
+
+import (
+	"sync"
+	"time"
+
+	strcolor "github.com/cjlapao/common-go/strcolor"
+)
+
+// OverflowPolicy defines what the asynchronous logger does when its queue is full
+type OverflowPolicy int
+
+const (
+	// OverflowBlock waits until the queue has room for the message
+	OverflowBlock OverflowPolicy = iota
+	// OverflowDropOldest discards the oldest queued message to make room for the new one
+	OverflowDropOldest
+	// OverflowDropNewest discards the new message
+	OverflowDropNewest
+)
+
+const defaultAsyncQueueSize = 1024
+
+// AsyncOptions configures the asynchronous logger, the queue size defaults to 1024 messages
+type AsyncOptions struct {
+	QueueSize int
+	Policy    OverflowPolicy
+}
+
+// AsyncStats are the counters of the asynchronous logger
+type AsyncStats struct {
+	Enqueued uint64
+	Written  uint64
+	Dropped  uint64
+	Queued   int
+}
+
+// asyncCall is a message waiting to be written to the sink
+type asyncCall struct {
+	fields        map[string]interface{}
+	correlationId string
+	caller        string
+	time          time.Time
+	write         func(sink Log)
+}
+
+// asyncQueue is shared by an asynchronous logger and the loggers created from it
+type asyncQueue struct {
+	mutex     sync.Mutex
+	notEmpty  *sync.Cond
+	notFull   *sync.Cond
+	progress  *sync.Cond
+	calls     []asyncCall
+	size      int
+	policy    OverflowPolicy
+	closed    bool
+	stopped   chan struct{}
+	accepted  uint64
+	completed uint64
+	written   uint64
+	dropped   uint64
+}
+
+// AsyncLogger writes the messages to the sink from a background goroutine so a slow sink
+// does not stall the callers. Messages that end the process flush the queue first
+type AsyncLogger struct {
+	sink          Log
+	queue         *asyncQueue
+	fields        map[string]interface{}
+	correlationId string
+}
+
+// NewAsyncLogger Creates an asynchronous logger writing to the sink
+func NewAsyncLogger(sink Log, options AsyncOptions) *AsyncLogger {
+	if options.QueueSize <= 0 {
+		options.QueueSize = defaultAsyncQueueSize
+	}
+
+	queue := &asyncQueue{
+		calls:   make([]asyncCall, 0, options.QueueSize),
+		size:    options.QueueSize,
+		policy:  options.Policy,
+		stopped: make(chan struct{}),
+	}
+	queue.notEmpty = sync.NewCond(&queue.mutex)
+	queue.notFull = sync.NewCond(&queue.mutex)
+	queue.progress = sync.NewCond(&queue.mutex)
+
+	logger := &AsyncLogger{sink: sink, queue: queue}
+	go logger.run()
+
+	return logger
+}
+
+// Sink Returns the sink the messages are written to
+func (l *AsyncLogger) Sink() Log {
+	return l.sink
+}
+
+// Stats Returns the counters of the logger
+func (l *AsyncLogger) Stats() AsyncStats {
+	l.queue.mutex.Lock()
+	defer l.queue.mutex.Unlock()
+
+	return AsyncStats{
+		Enqueued: l.queue.accepted,
+		Written:  l.queue.written,
+		Dropped:  l.queue.dropped,
+		Queued:   len(l.queue.calls),
+	}
+}
+
+// Flush Waits until the messages queued before the call are written or dropped
+func (l *AsyncLogger) Flush() {
+	queue := l.queue
+	queue.mutex.Lock()
+	defer queue.mutex.Unlock()
+
+	target := queue.accepted
+	for queue.completed < target {
+		queue.progress.Wait()
+	}
+}
+
+// Close Writes the queued messages, stops the background goroutine and closes the sink if
+// it can be closed. Messages logged after closing are written synchronously
+func (l *AsyncLogger) Close() error {
+	queue := l.queue
+	queue.mutex.Lock()
+	if queue.closed {
+		queue.mutex.Unlock()
+		return nil
+	}
+	queue.closed = true
+	queue.notEmpty.Broadcast()
+	queue.notFull.Broadcast()
+	queue.mutex.Unlock()
+
+	<-queue.stopped
+	if closer, ok := l.sink.(interface{ Close() error }); ok {
+		return closer.Close()
+	}
+
+	return nil
+}
+
+// WithFields Returns a logger sharing the same queue that also writes the fields
+func (l *AsyncLogger) WithFields(fields map[string]interface{}) Log {
+	return &AsyncLogger{
+		sink:          l.sink,
+		queue:         l.queue,
+		fields:        mergeFields(l.fields, fields),
+		correlationId: l.correlationId,
+	}
+}
+
+// WithCorrelationId Returns a logger sharing the same queue that writes the correlation id
+func (l *AsyncLogger) WithCorrelationId(correlationId string) Log {
+	return &AsyncLogger{
+		sink:          l.sink,
+		queue:         l.queue,
+		fields:        l.fields,
+		correlationId: correlationId,
+	}
+}
+
+func (l *AsyncLogger) UseTimestamp(value bool) {
+	l.sink.UseTimestamp(value)
+}
+
+func (l *AsyncLogger) UseCorrelationId(value bool) {
+	l.sink.UseCorrelationId(value)
+}
+
+// Log Log information message
+func (l *AsyncLogger) Log(format string, level Level, words ...string) {
+	words = copyWords(words)
+	l.enqueue(func(sink Log) { sink.Log(format, level, words...) })
+}
+
+// LogHighlight Log information message
+func (l *AsyncLogger) LogHighlight(format string, level Level, highlightColor strcolor.ColorCode, words ...string) {
+	words = copyWords(words)
+	l.enqueue(func(sink Log) { sink.LogHighlight(format, level, highlightColor, words...) })
+}
+
+// Info log information message
+func (l *AsyncLogger) Info(format string, words ...string) {
+	words = copyWords(words)
+	l.enqueue(func(sink Log) { sink.Info(format, words...) })
+}
+
+// Success log message
+func (l *AsyncLogger) Success(format string, words ...string) {
+	words = copyWords(words)
+	l.enqueue(func(sink Log) { sink.Success(format, words...) })
+}
+
+// TaskSuccess log message, a completed task flushes the queue before writing the message
+func (l *AsyncLogger) TaskSuccess(format string, isComplete bool, words ...string) {
+	if isComplete {
+		l.Flush()
+		l.target("", time.Time{}).TaskSuccess(format, isComplete, words...)
+		return
+	}
+
+	words = copyWords(words)
+	l.enqueue(func(sink Log) { sink.TaskSuccess(format, isComplete, words...) })
+}
+
+// Warn log message
+func (l *AsyncLogger) Warn(format string, words ...string) {
+	words = copyWords(words)
+	l.enqueue(func(sink Log) { sink.Warn(format, words...) })
+}
+
+// TaskWarn log message
+func (l *AsyncLogger) TaskWarn(format string, words ...string) {
+	words = copyWords(words)
+	l.enqueue(func(sink Log) { sink.TaskWarn(format, words...) })
+}
+
+// Command log message
+func (l *AsyncLogger) Command(format string, words ...string) {
+	words = copyWords(words)
+	l.enqueue(func(sink Log) { sink.Command(format, words...) })
+}
+
+// Disabled log message
+func (l *AsyncLogger) Disabled(format string, words ...string) {
+	words = copyWords(words)
+	l.enqueue(func(sink Log) { sink.Disabled(format, words...) })
+}
+
+// Notice log message
+func (l *AsyncLogger) Notice(format string, words ...string) {
+	words = copyWords(words)
+	l.enqueue(func(sink Log) { sink.Notice(format, words...) })
+}
+
+// Debug log message
+func (l *AsyncLogger) Debug(format string, words ...string) {
+	words = copyWords(words)
+	l.enqueue(func(sink Log) { sink.Debug(format, words...) })
+}
+
+// Trace log message
+func (l *AsyncLogger) Trace(format string, words ...string) {
+	words = copyWords(words)
+	l.enqueue(func(sink Log) { sink.Trace(format, words...) })
+}
+
+// Error log message
+func (l *AsyncLogger) Error(format string, words ...string) {
+	words = copyWords(words)
+	l.enqueue(func(sink Log) { sink.Error(format, words...) })
+}
+
+// Exception log message
+func (l *AsyncLogger) Exception(err error, format string, words ...string) {
+	words = copyWords(words)
+	l.enqueue(func(sink Log) { sink.Exception(err, format, words...) })
+}
+
+// LogError log message
+func (l *AsyncLogger) LogError(message error) {
+	l.enqueue(func(sink Log) { sink.LogError(message) })
+}
+
+// TaskError log message, a completed task flushes the queue before writing the message
+func (l *AsyncLogger) TaskError(format string, isComplete bool, words ...string) {
+	if isComplete {
+		l.Flush()
+		l.target("", time.Time{}).TaskError(format, isComplete, words...)
+		return
+	}
+
+	words = copyWords(words)
+	l.enqueue(func(sink Log) { sink.TaskError(format, isComplete, words...) })
+}
+
+// Fatal log message, the queue is flushed before writing the message
+func (l *AsyncLogger) Fatal(format string, words ...string) {
+	l.Flush()
+	l.target("", time.Time{}).Fatal(format, words...)
+}
+
+// FatalError log message, the queue is flushed before writing the message
+func (l *AsyncLogger) FatalError(e error, format string, words ...string) {
+	l.Flush()
+	l.target("", time.Time{}).FatalError(e, format, words...)
+}
+
+func (l *AsyncLogger) writeTaskSuccess(format string, isComplete bool, words ...string) {
+	if isComplete {
+		l.Flush()
+		sinkTaskSuccess(l.target("", time.Time{}), format, isComplete, words...)
+		return
+	}
+
+	words = copyWords(words)
+	l.enqueue(func(sink Log) { sinkTaskSuccess(sink, format, isComplete, words...) })
+}
+
+func (l *AsyncLogger) writeTaskError(format string, isComplete bool, words ...string) {
+	if isComplete {
+		l.Flush()
+		sinkTaskError(l.target("", time.Time{}), format, isComplete, words...)
+		return
+	}
+
+	words = copyWords(words)
+	l.enqueue(func(sink Log) { sinkTaskError(sink, format, isComplete, words...) })
+}
+
+func (l *AsyncLogger) writeFatal(format string, words ...string) {
+	l.Flush()
+	sinkFatal(l.target("", time.Time{}), format, words...)
+}
+
+func (l *AsyncLogger) writeFatalError(e error, format string, words ...string) {
+	l.Flush()
+	sinkFatalError(l.target("", time.Time{}), e, format, words...)
+}
+
+// target returns the sink decorated with the fields, the correlation id, the caller and the
+// time of the call
+func (l *AsyncLogger) target(caller string, at time.Time) Log {
+	sink := decorateSink(l.sink, l.fields, l.correlationId)
+	if caller == "" && at.IsZero() {
+		return sink
+	}
+	if logger, ok := sink.(callerLogger); ok {
+		return logger.withCaller(caller, at)
+	}
+
+	return sink
+}
+
+// enqueue queues the write with the caller, the time and the correlation id of the call so
+// the entry does not depend on when the background goroutine writes it
+func (l *AsyncLogger) enqueue(write func(sink Log)) {
+	correlationId := l.correlationId
+	if correlationId == "" {
+		correlationId = DefaultCorrelationId()
+	}
+
+	call := asyncCall{
+		fields:        l.fields,
+		correlationId: correlationId,
+		caller:        callerLocation(),
+		time:          time.Now(),
+		write:         write,
+	}
+
+	queue := l.queue
+	queue.mutex.Lock()
+	if queue.closed {
+		queue.mutex.Unlock()
+		write(l.target(call.caller, call.time))
+		return
+	}
+
+	for len(queue.calls) >= queue.size && !queue.closed {
+		switch queue.policy {
+		case OverflowDropNewest:
+			queue.dropped++
+			queue.mutex.Unlock()
+			return
+		case OverflowDropOldest:
+			queue.calls = queue.calls[1:]
+			queue.dropped++
+			queue.completed++
+			queue.progress.Broadcast()
+		default:
+			queue.notFull.Wait()
+		}
+	}
+
+	if queue.closed {
+		queue.mutex.Unlock()
+		write(l.target(call.caller, call.time))
+		return
+	}
+
+	queue.calls = append(queue.calls, call)
+	queue.accepted++
+	queue.notEmpty.Signal()
+	queue.mutex.Unlock()
+}
+
+// run writes the queued messages until the logger is closed and the queue is empty
+func (l *AsyncLogger) run() {
+	queue := l.queue
+	defer close(queue.stopped)
+
+	for {
+		queue.mutex.Lock()
+		for len(queue.calls) == 0 && !queue.closed {
+			queue.notEmpty.Wait()
+		}
+		if len(queue.calls) == 0 {
+			queue.mutex.Unlock()
+			return
+		}
+
+		call := queue.calls[0]
+		queue.calls[0] = asyncCall{}
+		queue.calls = queue.calls[1:]
+		queue.notFull.Signal()
+		queue.mutex.Unlock()
+
+		target := (&AsyncLogger{sink: l.sink, fields: call.fields, correlationId: call.correlationId}).target(call.caller, call.time)
+		call.write(target)
+
+		queue.mutex.Lock()
+		queue.written++
+		queue.completed++
+		queue.progress.Broadcast()
+		queue.mutex.Unlock()
+	}
+}
+
+func copyWords(words []string) []string {
+	if len(words) == 0 {
+		return nil
+	}
+
+	result := make([]string, len(words))
+	copy(result, words)
+	return result
+}
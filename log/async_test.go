@@ -0,0 +1,197 @@
+package log
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// blockingWriter holds the writes until it is released
+type blockingWriter struct {
+	mutex   sync.Mutex
+	buffer  bytes.Buffer
+	release chan struct{}
+}
+
+func (w *blockingWriter) Write(p []byte) (int, error) {
+	<-w.release
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+	return w.buffer.Write(p)
+}
+
+func (w *blockingWriter) String() string {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+	return w.buffer.String()
+}
+
+func TestAsyncLogger_WritesInOrderAndFlushes(t *testing.T) {
+	// Arrange
+	var buffer bytes.Buffer
+	logger := &Logger{LogLevel: Info}
+	jsonLogger := NewJsonLogger(&buffer)
+	asyncLogger := logger.AddAsyncLogger(jsonLogger, AsyncOptions{})
+
+	// Act
+	for i := 0; i < 100; i++ {
+		logger.With("index", i).Info("message %s", fmt.Sprint(i))
+	}
+	logger.Flush()
+
+	// Assert
+	lines := decodeJsonLines(t, &buffer)
+	assert.Len(t, lines, 100)
+	assert.Equal(t, "message 99", lines[99]["message"])
+	assert.Equal(t, float64(99), lines[99]["index"])
+	assert.Regexp(t, `^log/async_test\.go:\d+$`, lines[0]["caller"])
+	assert.Equal(t, AsyncStats{Enqueued: 100, Written: 100}, asyncLogger.Stats())
+}
+
+func TestAsyncLogger_KeepsTheTimeAndCorrelationIdOfTheCall(t *testing.T) {
+	// Arrange
+	writer := &blockingWriter{release: make(chan struct{})}
+	jsonLogger := NewJsonLogger(writer)
+	jsonLogger.UseCorrelationId(true)
+	asyncLogger := NewAsyncLogger(jsonLogger, AsyncOptions{QueueSize: 10})
+	SetDefaultCorrelationId("first")
+	defer SetDefaultCorrelationId("")
+
+	// Act
+	asyncLogger.Info("one")
+	asyncLogger.Info("two")
+	calledAt := time.Now()
+	SetDefaultCorrelationId("second")
+	time.Sleep(50 * time.Millisecond)
+	releasedAt := time.Now()
+	close(writer.release)
+	asyncLogger.Close()
+
+	// Assert
+	lines := decodeJsonLines(t, bytes.NewBufferString(writer.String()))
+	assert.Len(t, lines, 2)
+	assert.Equal(t, "first", lines[1]["correlation_id"])
+	timestamp, err := time.Parse(time.RFC3339Nano, lines[1]["timestamp"].(string))
+	assert.Nil(t, err)
+	assert.False(t, timestamp.After(calledAt))
+	assert.True(t, timestamp.Before(releasedAt))
+}
+
+func TestAsyncLogger_DropNewestWhenFull(t *testing.T) {
+	// Arrange
+	writer := &blockingWriter{release: make(chan struct{})}
+	asyncLogger := NewAsyncLogger(NewJsonLogger(writer), AsyncOptions{QueueSize: 2, Policy: OverflowDropNewest})
+	asyncLogger.Info("first")
+	waitUntil(t, func() bool { return asyncLogger.Stats().Queued == 0 })
+
+	// Act
+	asyncLogger.Info("second")
+	asyncLogger.Info("third")
+	asyncLogger.Info("fourth")
+	close(writer.release)
+	asyncLogger.Close()
+
+	// Assert
+	stats := asyncLogger.Stats()
+	assert.Equal(t, uint64(3), stats.Enqueued)
+	assert.Equal(t, uint64(3), stats.Written)
+	assert.Equal(t, uint64(1), stats.Dropped)
+	assert.NotContains(t, writer.String(), "fourth")
+}
+
+func TestAsyncLogger_DropOldestWhenFull(t *testing.T) {
+	// Arrange
+	writer := &blockingWriter{release: make(chan struct{})}
+	asyncLogger := NewAsyncLogger(NewJsonLogger(writer), AsyncOptions{QueueSize: 2, Policy: OverflowDropOldest})
+	asyncLogger.Info("first")
+	waitUntil(t, func() bool { return asyncLogger.Stats().Queued == 0 })
+
+	// Act
+	asyncLogger.Info("second")
+	asyncLogger.Info("third")
+	asyncLogger.Info("fourth")
+	close(writer.release)
+	asyncLogger.Flush()
+
+	// Assert
+	stats := asyncLogger.Stats()
+	assert.Equal(t, uint64(4), stats.Enqueued)
+	assert.Equal(t, uint64(3), stats.Written)
+	assert.Equal(t, uint64(1), stats.Dropped)
+	assert.NotContains(t, writer.String(), "second")
+	assert.Contains(t, writer.String(), "fourth")
+}
+
+func TestAsyncLogger_BlockWaitsForRoom(t *testing.T) {
+	// Arrange
+	writer := &blockingWriter{release: make(chan struct{})}
+	asyncLogger := NewAsyncLogger(NewJsonLogger(writer), AsyncOptions{QueueSize: 1})
+	done := make(chan struct{})
+
+	// Act
+	go func() {
+		for i := 0; i < 5; i++ {
+			asyncLogger.Info("message")
+		}
+		close(done)
+	}()
+	waitUntil(t, func() bool { return asyncLogger.Stats().Queued == 1 })
+	close(writer.release)
+	<-done
+	asyncLogger.Close()
+
+	// Assert
+	assert.Equal(t, AsyncStats{Enqueued: 5, Written: 5}, asyncLogger.Stats())
+}
+
+func TestAsyncLogger_FatalFlushesBeforeExiting(t *testing.T) {
+	// Arrange
+	var buffer bytes.Buffer
+	exitCode := -1
+	exitProcess = func(code int) { exitCode = code }
+	defer func() { exitProcess = os.Exit }()
+	logger := &Logger{LogLevel: Info}
+	logger.AddAsyncLogger(NewJsonLogger(&buffer), AsyncOptions{})
+
+	// Act
+	logger.Info("before")
+	logger.Fatal("fatal")
+
+	// Assert
+	lines := decodeJsonLines(t, &buffer)
+	assert.Equal(t, 1, exitCode)
+	assert.Len(t, lines, 2)
+	assert.Equal(t, "before", lines[0]["message"])
+	assert.Equal(t, "fatal", lines[1]["message"])
+}
+
+func TestAsyncLogger_WritesSynchronouslyAfterClose(t *testing.T) {
+	// Arrange
+	var buffer bytes.Buffer
+	asyncLogger := NewAsyncLogger(NewJsonLogger(&buffer), AsyncOptions{})
+	asyncLogger.Info("queued")
+	asyncLogger.Close()
+
+	// Act
+	asyncLogger.Info("after close")
+
+	// Assert
+	lines := decodeJsonLines(t, &buffer)
+	assert.Len(t, lines, 2)
+	assert.Equal(t, "after close", lines[1]["message"])
+}
+
+func waitUntil(t *testing.T, condition func() bool) {
+	deadline := time.Now().Add(2 * time.Second)
+	for !condition() {
+		if time.Now().After(deadline) {
+			t.Fatal("timed out waiting for the condition")
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
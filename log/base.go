@@ -0,0 +1,240 @@
+package log
+
+import (
+	"io"
+	"sync"
+	"time"
+
+	strcolor "github.com/cjlapao/common-go/strcolor"
+)
+
+// entryOutput is shared by a sink and the loggers created from it with WithFields
+type entryOutput struct {
+	mutex            sync.Mutex
+	writer           io.Writer
+	useTimestamp     bool
+	useCorrelationId bool
+	encode           func(entry Entry, useTimestamp bool) []byte
+	handle           func(entry Entry)
+}
+
+// baseLogger implements the Log methods for the sinks that write encoded entries
+type baseLogger struct {
+	output        *entryOutput
+	fields        map[string]interface{}
+	correlationId string
+	caller        string
+	time          time.Time
+}
+
+// callerLogger is implemented by the sinks that write the caller, the returned logger writes
+// the caller and the time that were captured before the call was handed to another goroutine
+type callerLogger interface {
+	withCaller(caller string, at time.Time) Log
+}
+
+func newBaseLogger(writer io.Writer, encode func(entry Entry, useTimestamp bool) []byte) baseLogger {
+	return baseLogger{
+		output: &entryOutput{
+			writer:       writer,
+			useTimestamp: true,
+			encode:       encode,
+		},
+		fields: make(map[string]interface{}),
+	}
+}
+
+// withFields returns a base logger sharing the output with the merged fields
+func (l *baseLogger) withFields(fields map[string]interface{}) baseLogger {
+	l.output.mutex.Lock()
+	defer l.output.mutex.Unlock()
+
+	return baseLogger{
+		output:        l.output,
+		fields:        mergeFields(l.fields, fields),
+		correlationId: l.correlationId,
+		caller:        l.caller,
+	}
+}
+
+// withCorrelationId returns a base logger sharing the output with the correlation id
+func (l *baseLogger) withCorrelationId(correlationId string) baseLogger {
+	l.output.mutex.Lock()
+	defer l.output.mutex.Unlock()
+
+	return baseLogger{
+		output:        l.output,
+		fields:        l.fields,
+		correlationId: correlationId,
+		caller:        l.caller,
+	}
+}
+
+// withCallerLocation returns a base logger sharing the output that writes the caller and the
+// time of the call
+func (l *baseLogger) withCallerLocation(caller string, at time.Time) baseLogger {
+	l.output.mutex.Lock()
+	defer l.output.mutex.Unlock()
+
+	return baseLogger{
+		output:        l.output,
+		fields:        l.fields,
+		correlationId: l.correlationId,
+		caller:        caller,
+		time:          at,
+	}
+}
+
+func (l *baseLogger) setField(key string, value interface{}) {
+	l.output.mutex.Lock()
+	defer l.output.mutex.Unlock()
+	l.fields[key] = value
+}
+
+func (l *baseLogger) UseTimestamp(value bool) {
+	l.output.mutex.Lock()
+	defer l.output.mutex.Unlock()
+	l.output.useTimestamp = value
+}
+
+func (l *baseLogger) UseCorrelationId(value bool) {
+	l.output.mutex.Lock()
+	defer l.output.mutex.Unlock()
+	l.output.useCorrelationId = value
+}
+
+// Log Log information message
+func (l *baseLogger) Log(format string, level Level, words ...string) {
+	l.write(level, "", nil, format, words...)
+}
+
+// LogHighlight Log information message, the highlight is ignored as the output has no colors
+func (l *baseLogger) LogHighlight(format string, level Level, highlightColor strcolor.ColorCode, words ...string) {
+	l.write(level, "", nil, format, words...)
+}
+
+// Info log information message
+func (l *baseLogger) Info(format string, words ...string) {
+	l.write(Info, "", nil, format, words...)
+}
+
+// Success log message
+func (l *baseLogger) Success(format string, words ...string) {
+	l.write(Info, KindSuccess, nil, format, words...)
+}
+
+// TaskSuccess log message
+func (l *baseLogger) TaskSuccess(format string, isComplete bool, words ...string) {
+	l.write(Info, KindSuccess, nil, format, words...)
+}
+
+// Warn log message
+func (l *baseLogger) Warn(format string, words ...string) {
+	l.write(Warning, "", nil, format, words...)
+}
+
+// TaskWarn log message
+func (l *baseLogger) TaskWarn(format string, words ...string) {
+	l.write(Warning, "", nil, format, words...)
+}
+
+// Command log message
+func (l *baseLogger) Command(format string, words ...string) {
+	l.write(Info, KindCommand, nil, format, words...)
+}
+
+// Disabled log message
+func (l *baseLogger) Disabled(format string, words ...string) {
+	l.write(Info, KindDisabled, nil, format, words...)
+}
+
+// Notice log message
+func (l *baseLogger) Notice(format string, words ...string) {
+	l.write(Info, KindNotice, nil, format, words...)
+}
+
+// Debug log message
+func (l *baseLogger) Debug(format string, words ...string) {
+	l.write(Debug, "", nil, format, words...)
+}
+
+// Trace log message
+func (l *baseLogger) Trace(format string, words ...string) {
+	l.write(Trace, "", nil, format, words...)
+}
+
+// Error log message
+func (l *baseLogger) Error(format string, words ...string) {
+	l.write(Error, "", nil, format, words...)
+}
+
+// Exception log message
+func (l *baseLogger) Exception(err error, format string, words ...string) {
+	l.write(Error, "", err, exceptionFormat(err, format), words...)
+}
+
+// LogError log message
+func (l *baseLogger) LogError(message error) {
+	if message != nil {
+		l.write(Error, "", message, exceptionFormat(message, ""))
+	}
+}
+
+// TaskError log message
+func (l *baseLogger) TaskError(format string, isComplete bool, words ...string) {
+	l.write(Error, "", nil, format, words...)
+}
+
+// Fatal log message, the stack of the caller is written with the message
+func (l *baseLogger) Fatal(format string, words ...string) {
+	l.writeWithStack(Error, "", nil, callerStack(), format, words...)
+}
+
+// FatalError log message, the stack of the caller is written with the message
+func (l *baseLogger) FatalError(e error, format string, words ...string) {
+	l.writeWithStack(Error, "", e, callerStack(), exceptionFormat(e, format), words...)
+}
+
+func (l *baseLogger) write(level Level, kind string, err error, format string, words ...string) {
+	l.writeWithStack(level, kind, err, nil, format, words...)
+}
+
+func (l *baseLogger) writeWithStack(level Level, kind string, err error, stack []string, format string, words ...string) {
+	caller := l.caller
+	if caller == "" {
+		caller = callerLocation()
+	}
+
+	at := l.time
+	if at.IsZero() {
+		at = time.Now()
+	}
+
+	entry := Entry{
+		Time:    at,
+		Level:   level,
+		Kind:    kind,
+		Message: formatMessage(format, words...),
+		Caller:  caller,
+		Error:   err,
+		Stack:   stack,
+	}
+
+	l.output.mutex.Lock()
+	defer l.output.mutex.Unlock()
+
+	if l.output.useCorrelationId {
+		entry.CorrelationId = l.correlationId
+		if entry.CorrelationId == "" {
+			entry.CorrelationId = DefaultCorrelationId()
+		}
+	}
+	entry.Fields = l.fields
+
+	if l.output.handle != nil {
+		l.output.handle(entry)
+		return
+	}
+
+	l.output.writer.Write(l.output.encode(entry, l.output.useTimestamp))
+}
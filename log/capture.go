@@ -0,0 +1,132 @@
+package log
+
+import (
+	"strings"
+	"sync"
+	"time"
+)
+
+// captureStore is shared by a capture logger and the loggers created from it
+type captureStore struct {
+	mutex   sync.Mutex
+	entries []Entry
+}
+
+// CaptureLogger keeps the entries in memory so tests can assert on them
+type CaptureLogger struct {
+	baseLogger
+	store *captureStore
+}
+
+// NewCaptureLogger Creates an empty capture logger
+func NewCaptureLogger() *CaptureLogger {
+	store := &captureStore{}
+	logger := &CaptureLogger{
+		baseLogger: newBaseLogger(nil, nil),
+		store:      store,
+	}
+	logger.output.useCorrelationId = true
+	logger.output.handle = func(entry Entry) {
+		entry.Fields = mergeFields(nil, entry.Fields)
+		store.mutex.Lock()
+		defer store.mutex.Unlock()
+		store.entries = append(store.entries, entry)
+	}
+
+	return logger
+}
+
+// WithFields Returns a logger sharing the same entries that also records the fields
+func (l *CaptureLogger) WithFields(fields map[string]interface{}) Log {
+	return &CaptureLogger{
+		baseLogger: l.withFields(fields),
+		store:      l.store,
+	}
+}
+
+// WithCorrelationId Returns a logger sharing the same entries that records the correlation id
+func (l *CaptureLogger) WithCorrelationId(correlationId string) Log {
+	return &CaptureLogger{
+		baseLogger: l.withCorrelationId(correlationId),
+		store:      l.store,
+	}
+}
+
+func (l *CaptureLogger) withCaller(caller string, at time.Time) Log {
+	return &CaptureLogger{
+		baseLogger: l.withCallerLocation(caller, at),
+		store:      l.store,
+	}
+}
+
+// TaskSuccess log message
+func (l *CaptureLogger) TaskSuccess(format string, isComplete bool, words ...string) {
+	l.write(Info, KindSuccess, nil, format, words...)
+}
+
+// TaskError log message
+func (l *CaptureLogger) TaskError(format string, isComplete bool, words ...string) {
+	l.write(Error, "", nil, format, words...)
+}
+
+// Fatal log message
+func (l *CaptureLogger) Fatal(format string, words ...string) {
+	l.writeWithStack(Error, "", nil, callerStack(), format, words...)
+}
+
+// FatalError log message
+func (l *CaptureLogger) FatalError(e error, format string, words ...string) {
+	l.writeWithStack(Error, "", e, callerStack(), exceptionFormat(e, format), words...)
+}
+
+// Entries Returns a copy of the recorded entries
+func (l *CaptureLogger) Entries() []Entry {
+	l.store.mutex.Lock()
+	defer l.store.mutex.Unlock()
+
+	result := make([]Entry, len(l.store.entries))
+	copy(result, l.store.entries)
+	return result
+}
+
+// EntriesWithLevel Returns the recorded entries of the level
+func (l *CaptureLogger) EntriesWithLevel(level Level) []Entry {
+	result := make([]Entry, 0)
+	for _, entry := range l.Entries() {
+		if entry.Level == level {
+			result = append(result, entry)
+		}
+	}
+
+	return result
+}
+
+// Find Returns the recorded entries of the level whose message contains the text
+func (l *CaptureLogger) Find(level Level, text string) []Entry {
+	result := make([]Entry, 0)
+	for _, entry := range l.EntriesWithLevel(level) {
+		if strings.Contains(entry.Message, text) {
+			result = append(result, entry)
+		}
+	}
+
+	return result
+}
+
+// Messages Returns the messages of the recorded entries
+func (l *CaptureLogger) Messages() []string {
+	entries := l.Entries()
+	result := make([]string, len(entries))
+	for i, entry := range entries {
+		result[i] = entry.Message
+	}
+
+	return result
+}
+
+// Reset Removes the recorded entries
+func (l *CaptureLogger) Reset() {
+	l.store.mutex.Lock()
+	defer l.store.mutex.Unlock()
+	l.store.entries = nil
+}
@@ -1,9 +1,12 @@
 package log
 
 import (
+	"bytes"
 	"fmt"
+	"io"
 	"os"
 	"strings"
+	"sync"
 	"time"
 
 	strcolor "github.com/cjlapao/common-go/strcolor"
@@ -11,11 +14,38 @@ import (
 	"github.com/fatih/color"
 )
 
-var useTimestamp bool
-var userCorrelationId bool
+// CmdLoggerOptions configures a command line logger, the zero value writes to os.Stdout with
+// colors and RFC3339 timestamps when they are enabled
+type CmdLoggerOptions struct {
+	Writer           io.Writer
+	UseTimestamp     bool
+	TimestampFormat  string
+	UseCorrelationId bool
+	DisableColors    bool
+	LevelPrefix      bool
+}
+
+// cmdOutput is shared by a command line logger and the loggers created from it
+type cmdOutput struct {
+	mutex   sync.Mutex
+	options CmdLoggerOptions
+}
 
 // CmdLogger Command Line Logger implementation
-type CmdLogger struct{}
+type CmdLogger struct {
+	once          sync.Once
+	output        *cmdOutput
+	fields        map[string]interface{}
+	correlationId string
+	time          time.Time
+}
+
+// NewCmdLogger Creates a command line logger with the options
+func NewCmdLogger(options CmdLoggerOptions) *CmdLogger {
+	return &CmdLogger{
+		output: &cmdOutput{options: options},
+	}
+}
 
 // Logger Ansi Colors
 const (
@@ -31,26 +61,75 @@ const (
 )
 
 func (l *CmdLogger) UseTimestamp(value bool) {
-	useTimestamp = value
+	output := l.shared()
+	output.mutex.Lock()
+	defer output.mutex.Unlock()
+	output.options.UseTimestamp = value
 }
 
 func (l *CmdLogger) UseCorrelationId(value bool) {
-	userCorrelationId = value
+	output := l.shared()
+	output.mutex.Lock()
+	defer output.mutex.Unlock()
+	output.options.UseCorrelationId = value
+}
+
+// Options Returns a copy of the options of the logger
+func (l *CmdLogger) Options() CmdLoggerOptions {
+	output := l.shared()
+	output.mutex.Lock()
+	defer output.mutex.Unlock()
+	return output.options
+}
+
+// SetOptions Replaces the options of the logger and of the loggers created from it
+func (l *CmdLogger) SetOptions(options CmdLoggerOptions) {
+	output := l.shared()
+	output.mutex.Lock()
+	defer output.mutex.Unlock()
+	output.options = options
+}
+
+// shared returns the output of the logger, creating it for loggers created with new(CmdLogger)
+func (l *CmdLogger) shared() *cmdOutput {
+	l.once.Do(func() {
+		if l.output == nil {
+			l.output = &cmdOutput{}
+		}
+	})
+
+	return l.output
+}
+
+// WithFields Returns a command line logger that appends the fields as key=value to the messages
+func (l *CmdLogger) WithFields(fields map[string]interface{}) Log {
+	return &CmdLogger{output: l.shared(), fields: mergeFields(l.fields, fields), correlationId: l.correlationId}
+}
+
+// WithCorrelationId Returns a command line logger that writes the correlation id
+func (l *CmdLogger) WithCorrelationId(correlationId string) Log {
+	return &CmdLogger{output: l.shared(), fields: l.fields, correlationId: correlationId}
+}
+
+// withCaller returns a logger sharing the output that writes the timestamp of the call, the
+// command line does not write the caller
+func (l *CmdLogger) withCaller(caller string, at time.Time) Log {
+	return &CmdLogger{output: l.shared(), fields: l.fields, correlationId: l.correlationId, time: at}
 }
 
 // Log Log information message
 func (l *CmdLogger) Log(format string, level Level, words ...string) {
 	switch level {
 	case 0:
-		printMessage(format, "error", false, false, useTimestamp, words...)
+		l.printMessage(l.decorate(format), "error", false, false, words...)
 	case 1:
-		printMessage(format, "warn", false, false, useTimestamp, words...)
+		l.printMessage(l.decorate(format), "warn", false, false, words...)
 	case 2:
-		printMessage(format, "info", false, false, useTimestamp, words...)
+		l.printMessage(l.decorate(format), "info", false, false, words...)
 	case 3:
-		printMessage(format, "debug", false, false, useTimestamp, words...)
+		l.printMessage(l.decorate(format), "debug", false, false, words...)
 	case 4:
-		printMessage(format, "trace", false, false, useTimestamp, words...)
+		l.printMessage(l.decorate(format), "trace", false, false, words...)
 	}
 }
 
@@ -62,126 +141,223 @@ func (l *CmdLogger) LogHighlight(format string, level Level, highlightColor strc
 
 	switch level {
 	case 0:
-		printMessage(format, "error", false, false, useTimestamp, words...)
+		l.printMessage(l.decorate(format), "error", false, false, words...)
 	case 1:
-		printMessage(format, "warn", false, false, useTimestamp, words...)
+		l.printMessage(l.decorate(format), "warn", false, false, words...)
 	case 2:
-		printMessage(format, "info", false, false, useTimestamp, words...)
+		l.printMessage(l.decorate(format), "info", false, false, words...)
 	case 3:
-		printMessage(format, "debug", false, false, useTimestamp, words...)
+		l.printMessage(l.decorate(format), "debug", false, false, words...)
 	case 4:
-		printMessage(format, "trace", false, false, useTimestamp, words...)
+		l.printMessage(l.decorate(format), "trace", false, false, words...)
 	}
 }
 
 // Info log information message
 func (l *CmdLogger) Info(format string, words ...string) {
-	printMessage(format, "info", false, false, useTimestamp, words...)
+	l.printMessage(l.decorate(format), "info", false, false, words...)
 }
 
 // Success log message
 func (l *CmdLogger) Success(format string, words ...string) {
-	printMessage(format, "success", false, false, useTimestamp, words...)
+	l.printMessage(l.decorate(format), "success", false, false, words...)
 }
 
 // TaskSuccess log message
 func (l *CmdLogger) TaskSuccess(format string, isComplete bool, words ...string) {
-	printMessage(format, "success", true, isComplete, useTimestamp, words...)
+	l.writeTaskSuccess(format, isComplete, words...)
+	if isComplete {
+		exitProcess(0)
+	}
 }
 
 // Warn log message
 func (l *CmdLogger) Warn(format string, words ...string) {
-	printMessage(format, "warn", false, false, useTimestamp, words...)
+	l.printMessage(l.decorate(format), "warn", false, false, words...)
 }
 
 // TaskWarn log message
 func (l *CmdLogger) TaskWarn(format string, words ...string) {
-	printMessage(format, "warn", true, false, useTimestamp, words...)
+	l.printMessage(l.decorate(format), "warn", true, false, words...)
 }
 
 // Command log message
 func (l *CmdLogger) Command(format string, words ...string) {
-	printMessage(format, "command", false, false, useTimestamp, words...)
+	l.printMessage(l.decorate(format), "command", false, false, words...)
 }
 
 // Disabled log message
 func (l *CmdLogger) Disabled(format string, words ...string) {
-	printMessage(format, "disabled", false, false, useTimestamp, words...)
+	l.printMessage(l.decorate(format), "disabled", false, false, words...)
 }
 
 // Notice log message
 func (l *CmdLogger) Notice(format string, words ...string) {
-	printMessage(format, "notice", false, false, useTimestamp, words...)
+	l.printMessage(l.decorate(format), "notice", false, false, words...)
 }
 
 // Debug log message
 func (l *CmdLogger) Debug(format string, words ...string) {
-	printMessage(format, "debug", false, false, useTimestamp, words...)
+	l.printMessage(l.decorate(format), "debug", false, false, words...)
 }
 
 // Trace log message
 func (l *CmdLogger) Trace(format string, words ...string) {
-	printMessage(format, "trace", false, false, useTimestamp, words...)
+	l.printMessage(l.decorate(format), "trace", false, false, words...)
 }
 
 // Error log message
 func (l *CmdLogger) Error(format string, words ...string) {
-	printMessage(format, "error", false, false, useTimestamp, words...)
+	l.printMessage(l.decorate(format), "error", false, false, words...)
 }
 
 // Error log message
 func (l *CmdLogger) Exception(err error, format string, words ...string) {
-	if format == "" {
-		format = err.Error()
-	} else {
-		format = format + ", err " + err.Error()
-	}
-	printMessage(format, "error", false, false, useTimestamp, words...)
+	l.printMessage(l.decorate(exceptionTextFormat(err, format))+errorTextFormat(err, nil), "error", false, false, words...)
 }
 
 // LogError log message
 func (l *CmdLogger) LogError(message error) {
 	if message != nil {
-		printMessage(message.Error(), "error", false, false, useTimestamp)
+		l.printMessage(l.decorate(strings.ReplaceAll(message.Error(), "%", "%%"))+errorTextFormat(message, nil), "error", false, false)
 	}
 }
 
-// TaskError log message
+// TaskError log message, a completed task ends the process
 func (l *CmdLogger) TaskError(format string, isComplete bool, words ...string) {
-	printMessage(format, "error", true, isComplete, useTimestamp, words...)
+	l.writeTaskError(format, isComplete, words...)
+	if isComplete {
+		exitProcess(taskErrorExitCode())
+	}
 }
 
-// Fatal log message
+// Fatal log message, the stack of the caller is printed with the message and the process ends
 func (l *CmdLogger) Fatal(format string, words ...string) {
-	printMessage(format, "error", false, true, useTimestamp, words...)
+	l.writeFatal(format, words...)
+	exitProcess(1)
 }
 
-// FatalError log message
+// FatalError log message, the stack of the caller is printed with the message and it panics
+// with the error
 func (l *CmdLogger) FatalError(e error, format string, words ...string) {
-	l.Error(format, words...)
+	l.writeFatalError(e, format, words...)
 	if e != nil {
 		panic(e)
 	}
 }
 
-// printMessage Prints a message in the system
-func printMessage(format string, level string, isTask bool, isComplete bool, useTimestamp bool, words ...string) {
-	agentID := os.Getenv("AGENT_ID")
-	isPipeline := false
-	if len(agentID) != 0 {
-		isPipeline = true
+// writeTaskSuccess prints the task without ending the process, the Logger ends it once every
+// sink wrote the message
+func (l *CmdLogger) writeTaskSuccess(format string, isComplete bool, words ...string) {
+	l.printMessage(l.decorate(format), "success", true, isComplete, words...)
+}
+
+// writeTaskError prints the task without ending the process
+func (l *CmdLogger) writeTaskError(format string, isComplete bool, words ...string) {
+	l.printMessage(l.decorate(format), "error", true, isComplete, words...)
+}
+
+// writeFatal prints the message and the stack of the caller without ending the process
+func (l *CmdLogger) writeFatal(format string, words ...string) {
+	l.printMessage(l.decorate(format)+errorTextFormat(nil, callerStack()), "error", false, true, words...)
+}
+
+// writeFatalError prints the error and the stack of the caller without panicking
+func (l *CmdLogger) writeFatalError(e error, format string, words ...string) {
+	if e == nil {
+		l.Error(format, words...)
+		return
+	}
+
+	l.printMessage(l.decorate(exceptionTextFormat(e, format))+errorTextFormat(e, callerStack()), "error", false, false, words...)
+}
+
+// exceptionTextFormat appends the escaped message of the error to the format
+func exceptionTextFormat(err error, format string) string {
+	message := strings.ReplaceAll(err.Error(), "%", "%%")
+	if format == "" {
+		return message
+	}
+
+	return format + ", err " + message
+}
+
+// errorTextFormat returns the error details and the stack as indented lines escaped to be
+// appended to a format
+func errorTextFormat(err error, stack []string) string {
+	lines := errorText(err, stack)
+	if len(lines) == 0 {
+		return ""
 	}
-	if userCorrelationId {
-		correlationId := os.Getenv("CORRELATION_ID")
+
+	return strings.ReplaceAll("\n\t"+strings.Join(lines, "\n\t"), "%", "%%")
+}
+
+// decorate adds the correlation id and the fields to the format
+func (l *CmdLogger) decorate(format string) string {
+	if l.Options().UseCorrelationId {
+		correlationId := l.correlationId
+		if correlationId == "" {
+			correlationId = DefaultCorrelationId()
+		}
 		if correlationId != "" {
-			format = "[" + correlationId + "] " + format
+			format = "[" + strings.ReplaceAll(correlationId, "%", "%%") + "] " + format
 		}
 	}
 
-	if useTimestamp {
-		format = fmt.Sprint(time.Now().Format(time.RFC3339)) + " " + format
+	return l.formatWithFields(format)
+}
+
+func (l *CmdLogger) formatWithFields(format string) string {
+	if len(l.fields) == 0 {
+		return format
 	}
 
+	return format + " " + strings.ReplaceAll(renderFields(l.fields), "%", "%%")
+}
+
+// isPipelineAgent returns true when running in a pipeline agent, the task results are then
+// reported with the pipeline logging commands
+func isPipelineAgent() bool {
+	return os.Getenv("AGENT_ID") != ""
+}
+
+// printMessage Prints a message in the system
+func (l *CmdLogger) printMessage(format string, level string, isTask bool, isComplete bool, words ...string) {
+	options := l.Options()
+	isPipeline := isPipelineAgent()
+	if options.LevelPrefix {
+		format = "[" + strings.ToUpper(level) + "] " + format
+	}
+	if options.UseTimestamp {
+		timestampFormat := options.TimestampFormat
+		if timestampFormat == "" {
+			timestampFormat = time.RFC3339
+		}
+		at := l.time
+		if at.IsZero() {
+			at = time.Now()
+		}
+		format = strings.ReplaceAll(at.Format(timestampFormat), "%", "%%") + " " + format
+	}
+
+	var buffer bytes.Buffer
+	defer func() {
+		message := buffer.String()
+		if options.DisableColors {
+			message = stripColors(message)
+		}
+
+		writer := options.Writer
+		if writer == nil {
+			writer = os.Stdout
+		}
+
+		l.output.mutex.Lock()
+		io.WriteString(writer, message)
+		l.output.mutex.Unlock()
+	}()
+
 	if !isPipeline {
 		format = format + "\u001b[0m" + "\n"
 	} else {
@@ -192,15 +368,15 @@ func printMessage(format string, level string, isTask bool, isComplete bool, use
 		}
 	}
 
-	successWriter := color.New(SuccessColor).PrintfFunc()
-	warningWriter := color.New(WarningColor).PrintfFunc()
-	errorWriter := color.New(ErrorColor).PrintfFunc()
-	debugWriter := color.New(DebugColor).PrintfFunc()
-	traceWriter := color.New(TraceColor).PrintfFunc()
-	infoWriter := color.New(InfoColor).PrintfFunc()
-	noticeWriter := color.New(NoticeColor).PrintfFunc()
-	commandWriter := color.New(CommandColor).PrintfFunc()
-	disableWriter := color.New(DisabledColor).PrintfFunc()
+	successWriter := color.New(SuccessColor).FprintfFunc()
+	warningWriter := color.New(WarningColor).FprintfFunc()
+	errorWriter := color.New(ErrorColor).FprintfFunc()
+	debugWriter := color.New(DebugColor).FprintfFunc()
+	traceWriter := color.New(TraceColor).FprintfFunc()
+	infoWriter := color.New(InfoColor).FprintfFunc()
+	noticeWriter := color.New(NoticeColor).FprintfFunc()
+	commandWriter := color.New(CommandColor).FprintfFunc()
+	disableWriter := color.New(DisabledColor).FprintfFunc()
 
 	formatedWords := make([]interface{}, len(words))
 	for i := range words {
@@ -280,99 +456,96 @@ func printMessage(format string, level string, isTask bool, isComplete bool, use
 		if isPipeline {
 			format = "\033[" + fmt.Sprint(SuccessColor) + "m" + format
 			format = "##[section]" + format
-			fmt.Printf(format, formatedWords...)
+			fmt.Fprintf(&buffer, format, formatedWords...)
 			if isTask && isComplete {
-				fmt.Printf("\033[" + fmt.Sprint(SuccessColor) + "m" + "##vso[task.complete result=Succeeded;]\n")
+				fmt.Fprintf(&buffer, "\033["+fmt.Sprint(SuccessColor)+"m"+"##vso[task.complete result=Succeeded;]\n")
 			}
 		} else {
-			successWriter(format, formatedWords...)
+			successWriter(&buffer, format, formatedWords...)
 		}
 
 		if isComplete {
 			if isPipeline && isTask {
-				fmt.Printf("\033[" + fmt.Sprint(SuccessColor) + "m" + "##[section] Completed\n")
+				fmt.Fprintf(&buffer, "\033["+fmt.Sprint(SuccessColor)+"m"+"##[section] Completed\n")
 			} else {
-				successWriter("Completed")
+				successWriter(&buffer, "Completed")
 			}
-			os.Exit(0)
 		}
 	case "warn":
 		if isPipeline {
 			if isTask {
 				format = "##vso[task.LogIssue type=warning;]" + format
-				fmt.Printf(format, formatedWords...)
+				fmt.Fprintf(&buffer, format, formatedWords...)
 			} else {
 				format = "\033[" + fmt.Sprint(WarningColor) + "m" + format
-				fmt.Printf(format, formatedWords...)
+				fmt.Fprintf(&buffer, format, formatedWords...)
 			}
 		} else {
-			warningWriter(format, formatedWords...)
+			warningWriter(&buffer, format, formatedWords...)
 		}
 	case "error":
 		if isPipeline {
 			if isTask {
 				format = "##vso[task.LogIssue type=error;]" + format
-				fmt.Printf(format, formatedWords...)
+				fmt.Fprintf(&buffer, format, formatedWords...)
 			} else {
 				format = "\033[" + fmt.Sprint(ErrorColor) + "m" + format
-				fmt.Printf(format, formatedWords...)
+				fmt.Fprintf(&buffer, format, formatedWords...)
 			}
 		} else {
-			errorWriter(format, formatedWords...)
+			errorWriter(&buffer, format, formatedWords...)
 		}
 
 		if isComplete {
 			if isPipeline && isTask {
 				format = "\033[" + fmt.Sprint(ErrorColor) + "m" + format
-				fmt.Printf("##vso[task.complete result=Failed;]\n")
-				os.Exit(0)
+				fmt.Fprintf(&buffer, "##vso[task.complete result=Failed;]\n")
 			} else {
-				errorWriter("Failed\n")
-				os.Exit(1)
+				errorWriter(&buffer, "Failed\n")
 			}
 		}
 	case "debug":
 		if isPipeline {
 			format = "\033[" + fmt.Sprint(DebugColor) + "m" + format
-			fmt.Printf(format, formatedWords...)
+			fmt.Fprintf(&buffer, format, formatedWords...)
 		} else {
-			debugWriter(format, formatedWords...)
+			debugWriter(&buffer, format, formatedWords...)
 		}
 	case "trace":
 		if isPipeline {
 			format = "\033[" + fmt.Sprint(TraceColor) + "m" + format
-			fmt.Printf(format, formatedWords...)
+			fmt.Fprintf(&buffer, format, formatedWords...)
 		} else {
-			traceWriter(format, formatedWords...)
+			traceWriter(&buffer, format, formatedWords...)
 		}
 	case "info":
 		if isPipeline {
 			format = "\033[" + fmt.Sprint(InfoColor) + "m" + format
-			fmt.Printf(format, formatedWords...)
+			fmt.Fprintf(&buffer, format, formatedWords...)
 		} else {
-			infoWriter(format, formatedWords...)
+			infoWriter(&buffer, format, formatedWords...)
 		}
 	case "notice":
 		if isPipeline {
 			format = "\033[" + fmt.Sprint(NoticeColor) + "m" + format
-			fmt.Printf(format, formatedWords...)
+			fmt.Fprintf(&buffer, format, formatedWords...)
 		} else {
-			noticeWriter(format, formatedWords...)
+			noticeWriter(&buffer, format, formatedWords...)
 		}
 	case "command":
 		if isPipeline {
 			format = "\033[" + fmt.Sprint(CommandColor) + "m" + format
 			format = "##[command]" + format
-			fmt.Printf(format, formatedWords...)
+			fmt.Fprintf(&buffer, format, formatedWords...)
 		} else {
-			commandWriter(format, formatedWords...)
+			commandWriter(&buffer, format, formatedWords...)
 		}
 	case "disabled":
 		if isPipeline {
 			format = "\033[" + fmt.Sprint(DisabledColor) + "m" + format
-			fmt.Printf(format, formatedWords...)
+			fmt.Fprintf(&buffer, format, formatedWords...)
 		} else {
-			disableWriter(format, formatedWords...)
+			disableWriter(&buffer, format, formatedWords...)
 		}
 	}
 }
@@ -0,0 +1,170 @@
+package log
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCmdLogger_WritesToTheConfiguredWriter(t *testing.T) {
+	// Arrange
+	var buffer bytes.Buffer
+	logger := NewCmdLogger(CmdLoggerOptions{Writer: &buffer, DisableColors: true, LevelPrefix: true})
+
+	// Act
+	logger.Info("hello %s", "world")
+	logger.Warn("careful")
+
+	// Assert
+	assert.Equal(t, "[INFO] hello world\n[WARN] careful\n", buffer.String())
+}
+
+func TestCmdLogger_UsesTheTimestampFormat(t *testing.T) {
+	// Arrange
+	var buffer bytes.Buffer
+	logger := NewCmdLogger(CmdLoggerOptions{Writer: &buffer, DisableColors: true, UseTimestamp: true, TimestampFormat: "2006-01-02"})
+
+	// Act
+	logger.Info("message")
+
+	// Assert
+	assert.Equal(t, time.Now().Format("2006-01-02")+" message\n", buffer.String())
+}
+
+func TestCmdLogger_OptionsArePerInstance(t *testing.T) {
+	// Arrange
+	var first, second bytes.Buffer
+	firstLogger := NewCmdLogger(CmdLoggerOptions{Writer: &first, DisableColors: true})
+	secondLogger := NewCmdLogger(CmdLoggerOptions{Writer: &second, DisableColors: true})
+
+	// Act
+	firstLogger.UseTimestamp(true)
+	firstLogger.Info("first")
+	secondLogger.Info("second")
+
+	// Assert
+	assert.True(t, firstLogger.Options().UseTimestamp)
+	assert.False(t, secondLogger.Options().UseTimestamp)
+	assert.NotEqual(t, "first\n", first.String())
+	assert.Equal(t, "second\n", second.String())
+}
+
+func TestCmdLogger_FieldLoggersShareTheOptions(t *testing.T) {
+	// Arrange
+	var buffer bytes.Buffer
+	logger := &Logger{LogLevel: Info}
+	cmdLogger := logger.AddCmdLoggerWithOptions(CmdLoggerOptions{Writer: &buffer, DisableColors: true})
+	cmdLogger.UseCorrelationId(true)
+
+	// Act
+	logger.With("user", "bob").Info("hello")
+	logger.Ctx(ContextWithCorrelationId(context.Background(), "abc")).Info("world")
+
+	// Assert
+	assert.Equal(t, "hello user=bob\n[abc] world\n", buffer.String())
+}
+
+func TestLogger_FatalWritesEverySinkBeforeExiting(t *testing.T) {
+	// Arrange
+	var cmdBuffer, jsonBuffer bytes.Buffer
+	exitCodes := make([]int, 0)
+	exitProcess = func(code int) { exitCodes = append(exitCodes, code) }
+	defer func() { exitProcess = os.Exit }()
+	logger := &Logger{LogLevel: Info}
+	logger.AddCmdLoggerWithOptions(CmdLoggerOptions{Writer: &cmdBuffer, DisableColors: true})
+	logger.AddJsonLogger(&jsonBuffer)
+
+	// Act
+	logger.Fatal("broken")
+
+	// Assert
+	assert.Equal(t, []int{1}, exitCodes)
+	assert.True(t, strings.HasPrefix(cmdBuffer.String(), "broken\n"))
+	lines := decodeJsonLines(t, &jsonBuffer)
+	assert.Len(t, lines, 1)
+	assert.Equal(t, "broken", lines[0]["message"])
+}
+
+func TestLogger_CompletedTasksExitOnce(t *testing.T) {
+	// Arrange
+	var buffer bytes.Buffer
+	exitCodes := make([]int, 0)
+	logger := &Logger{LogLevel: Info}
+	logger.SetExitHandler(func(code int) { exitCodes = append(exitCodes, code) })
+	logger.AddCmdLoggerWithOptions(CmdLoggerOptions{Writer: &buffer, DisableColors: true})
+	logger.AddCmdLoggerWithOptions(CmdLoggerOptions{Writer: &buffer, DisableColors: true})
+
+	// Act
+	logger.TaskSuccess("step", false)
+	logger.TaskSuccess("done", true)
+	logger.TaskError("failed", true)
+
+	// Assert
+	assert.Equal(t, []int{0, 1}, exitCodes)
+	assert.Equal(t, 2, strings.Count(buffer.String(), "Completed"))
+	assert.Equal(t, 2, strings.Count(buffer.String(), "Failed"))
+}
+
+func TestCmdLogger_DirectUseEndsTheProcess(t *testing.T) {
+	// Arrange
+	var buffer bytes.Buffer
+	t.Setenv("AGENT_ID", "")
+	exitCodes := make([]int, 0)
+	exitProcess = func(code int) { exitCodes = append(exitCodes, code) }
+	defer func() { exitProcess = os.Exit }()
+	logger := NewCmdLogger(CmdLoggerOptions{Writer: &buffer, DisableColors: true})
+
+	// Act
+	logger.TaskSuccess("step", false)
+	logger.TaskSuccess("done", true)
+	logger.TaskError("failed", true)
+	logger.Fatal("broken")
+
+	// Assert
+	assert.Equal(t, []int{0, 1, 1}, exitCodes)
+	assert.PanicsWithError(t, "boom", func() { logger.FatalError(errors.New("boom"), "") })
+	assert.Contains(t, buffer.String(), "boom")
+}
+
+func TestLogger_WrappedCmdLoggersDoNotEndTheProcess(t *testing.T) {
+	// Arrange
+	var asyncBuffer, sampledBuffer bytes.Buffer
+	exitCodes := make([]int, 0)
+	exitProcess = func(code int) { exitCodes = append(exitCodes, code) }
+	defer func() { exitProcess = os.Exit }()
+	logger := &Logger{LogLevel: Info}
+	logger.AddAsyncLogger(NewCmdLogger(CmdLoggerOptions{Writer: &asyncBuffer, DisableColors: true}), AsyncOptions{})
+	logger.AddSampledLogger(NewCmdLogger(CmdLoggerOptions{Writer: &sampledBuffer, DisableColors: true}), SamplingOptions{})
+
+	// Act
+	logger.With("step", 1).TaskSuccess("done", true)
+	logger.Fatal("broken")
+
+	// Assert
+	assert.Equal(t, []int{0, 1}, exitCodes)
+	assert.PanicsWithError(t, "boom", func() { logger.FatalError(errors.New("boom"), "") })
+	for _, output := range []string{asyncBuffer.String(), sampledBuffer.String()} {
+		assert.Contains(t, output, "done step=1")
+		assert.Contains(t, output, "broken")
+		assert.Contains(t, output, "boom")
+	}
+}
+
+func TestLogger_AddCmdLoggerDoesNotDuplicate(t *testing.T) {
+	// Arrange
+	logger := &Logger{LogLevel: Info}
+
+	// Act
+	logger.AddCmdLogger()
+	logger.AddCmdLoggerWithTimestamp()
+
+	// Assert
+	assert.Len(t, logger.Loggers, 1)
+	assert.True(t, logger.Loggers[0].(*CmdLogger).Options().UseTimestamp)
+}
@@ -0,0 +1,78 @@
+package log
+
+import (
+	"context"
+	"sync/atomic"
+)
+
+type correlationIdKey struct{}
+
+var defaultCorrelationId atomic.Value
+
+// CorrelationIdLogger is implemented by sinks that write the correlation id, the returned
+// logger writes the id with every message and shares the output of the original sink
+type CorrelationIdLogger interface {
+	WithCorrelationId(correlationId string) Log
+}
+
+// ContextWithCorrelationId Returns a copy of the context carrying the correlation id
+func ContextWithCorrelationId(ctx context.Context, correlationId string) context.Context {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	return context.WithValue(ctx, correlationIdKey{}, correlationId)
+}
+
+// CorrelationIdFromContext Returns the correlation id carried by the context or an empty string
+func CorrelationIdFromContext(ctx context.Context) string {
+	if ctx == nil {
+		return ""
+	}
+
+	if correlationId, ok := ctx.Value(correlationIdKey{}).(string); ok {
+		return correlationId
+	}
+
+	return ""
+}
+
+// SetDefaultCorrelationId Sets the correlation id written by the loggers that were not
+// created with Ctx or did not receive a context with a correlation id
+func SetDefaultCorrelationId(correlationId string) {
+	defaultCorrelationId.Store(correlationId)
+}
+
+// DefaultCorrelationId Returns the process wide correlation id
+func DefaultCorrelationId() string {
+	if correlationId, ok := defaultCorrelationId.Load().(string); ok {
+		return correlationId
+	}
+
+	return ""
+}
+
+// Ctx Returns a child logger that writes the correlation id carried by the context, the
+// child logger shares the sinks and the level of the logger it was created from
+func (l *Logger) Ctx(ctx context.Context) *Logger {
+	correlationId := CorrelationIdFromContext(ctx)
+	if correlationId == "" {
+		correlationId = l.correlationId
+	}
+
+	return &Logger{
+		HighlightColor: l.HighlightColor,
+		parent:         l.rootLogger(),
+		fields:         l.fields,
+		correlationId:  correlationId,
+	}
+}
+
+// CorrelationId Returns the correlation id of the logger, falling back to the default one
+func (l *Logger) CorrelationId() string {
+	if l.correlationId != "" {
+		return l.correlationId
+	}
+
+	return DefaultCorrelationId()
+}
@@ -0,0 +1,78 @@
+package log
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCorrelationIdFromContext(t *testing.T) {
+	// Arrange
+	ctx := ContextWithCorrelationId(context.Background(), "abc")
+
+	// Act + Assert
+	assert.Equal(t, "abc", CorrelationIdFromContext(ctx))
+	assert.Equal(t, "", CorrelationIdFromContext(context.Background()))
+	assert.Equal(t, "", CorrelationIdFromContext(nil))
+}
+
+func TestLogger_CtxWritesTheCorrelationIdOfEachContext(t *testing.T) {
+	// Arrange
+	var buffer bytes.Buffer
+	logger := &Logger{LogLevel: Info}
+	jsonLogger := logger.AddJsonLogger(&buffer)
+	jsonLogger.UseCorrelationId(true)
+	var wg sync.WaitGroup
+
+	// Act
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func(id string) {
+			defer wg.Done()
+			ctx := ContextWithCorrelationId(context.Background(), id)
+			logger.Ctx(ctx).With("request", id).Info("handled")
+		}(fmt.Sprintf("request-%d", i))
+	}
+	wg.Wait()
+
+	// Assert
+	lines := decodeJsonLines(t, &buffer)
+	assert.Len(t, lines, 20)
+	for _, line := range lines {
+		assert.Equal(t, line["request"], line["correlation_id"])
+	}
+}
+
+func TestLogger_CtxFallsBackToTheDefaultCorrelationId(t *testing.T) {
+	// Arrange
+	var buffer bytes.Buffer
+	logger := &Logger{LogLevel: Info}
+	logger.AddJsonLogger(&buffer).UseCorrelationId(true)
+	SetDefaultCorrelationId("process")
+	defer SetDefaultCorrelationId("")
+
+	// Act
+	logger.Ctx(context.Background()).Info("first")
+	logger.Ctx(ContextWithCorrelationId(context.Background(), "request")).Info("second")
+
+	// Assert
+	lines := decodeJsonLines(t, &buffer)
+	assert.Equal(t, "process", lines[0]["correlation_id"])
+	assert.Equal(t, "request", lines[1]["correlation_id"])
+}
+
+func TestCmdLogger_DecoratesWithTheCorrelationId(t *testing.T) {
+	// Arrange
+	logger := new(CmdLogger)
+	logger.UseCorrelationId(true)
+
+	// Act
+	format := logger.WithCorrelationId("abc").(*CmdLogger).decorate("message")
+
+	// Assert
+	assert.Equal(t, "[abc] message", format)
+}
@@ -0,0 +1,89 @@
+package log
+
+import (
+	"fmt"
+	"path/filepath"
+	"reflect"
+	"regexp"
+	"runtime"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Entry is a single log record as seen by the structured sinks
+type Entry struct {
+	Time          time.Time
+	Level         Level
+	Kind          string
+	Message       string
+	CorrelationId string
+	Caller        string
+	Error         error
+	Stack         []string
+	Fields        map[string]interface{}
+}
+
+// Entry kinds for the messages that are not plain level messages
+const (
+	KindSuccess  = "success"
+	KindNotice   = "notice"
+	KindCommand  = "command"
+	KindDisabled = "disabled"
+)
+
+var logPackagePath = reflect.TypeOf(Logger{}).PkgPath()
+
+var ansiEscapeRegex = regexp.MustCompile("\x1b\\[[0-9;]*m")
+
+// formatMessage renders the format with the words the same way the command line logger does
+// but without any color codes, the format is always rendered so %% is written as % by every
+// sink
+func formatMessage(format string, words ...string) string {
+	values := make([]interface{}, len(words))
+	for i, word := range words {
+		values[i] = word
+	}
+
+	return stripColors(fmt.Sprintf(format, values...))
+}
+
+func stripColors(value string) string {
+	return ansiEscapeRegex.ReplaceAllString(value, "")
+}
+
+// callerLocation returns the file:line of the first caller outside of the log package
+func callerLocation() string {
+	pcs := make([]uintptr, 32)
+	count := runtime.Callers(2, pcs)
+	frames := runtime.CallersFrames(pcs[:count])
+	for {
+		frame, more := frames.Next()
+		if !isLogPackageFrame(frame) {
+			return shortCallerPath(frame.File) + ":" + strconv.Itoa(frame.Line)
+		}
+		if !more {
+			return ""
+		}
+	}
+}
+
+func isLogPackageFrame(frame runtime.Frame) bool {
+	if strings.HasSuffix(frame.File, "_test.go") {
+		return false
+	}
+
+	name := frame.Function
+	slash := strings.LastIndex(name, "/")
+	dot := strings.Index(name[slash+1:], ".")
+	if dot < 0 {
+		return false
+	}
+
+	return name[:slash+1+dot] == logPackagePath
+}
+
+func shortCallerPath(file string) string {
+	directory, name := filepath.Split(file)
+	return filepath.Join(filepath.Base(directory), name)
+}
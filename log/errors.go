@@ -0,0 +1,182 @@
+package log
+
+import (
+	"fmt"
+	"runtime"
+	"strconv"
+	"strings"
+
+	pkgerrors "github.com/pkg/errors"
+)
+
+const maxStackFrames = 32
+
+// ErrorDetail describes an error with its stack trace, when it has one, and the errors it
+// wraps. Joined errors have one cause for each of the joined errors
+type ErrorDetail struct {
+	Message string        `json:"message"`
+	Type    string        `json:"type"`
+	Stack   []string      `json:"stack,omitempty"`
+	Causes  []ErrorDetail `json:"causes,omitempty"`
+}
+
+// DescribeError Returns the detail of the error unwrapping the %w chains, the errors.Join
+// trees and the pkg/errors causes. Wrappers that do not change the message, like the ones
+// adding a stack trace, are merged with the error they wrap
+func DescribeError(err error) ErrorDetail {
+	detail := ErrorDetail{
+		Message: err.Error(),
+		Type:    fmt.Sprintf("%T", err),
+		Stack:   errorStack(err),
+	}
+
+	for _, cause := range errorCauses(err) {
+		detail.Causes = append(detail.Causes, DescribeError(cause))
+	}
+
+	if len(detail.Causes) == 1 && detail.Causes[0].Message == detail.Message {
+		cause := detail.Causes[0]
+		if len(detail.Stack) == 0 {
+			detail.Stack = cause.Stack
+		}
+		detail.Type = cause.Type
+		detail.Causes = cause.Causes
+	}
+
+	return detail
+}
+
+// HasDetails Returns true if the error has a stack trace or wraps other errors
+func (d ErrorDetail) HasDetails() bool {
+	return len(d.Stack) > 0 || len(d.Causes) > 0
+}
+
+// Lines Returns the detail as indented text lines, one for each error and stack frame
+func (d ErrorDetail) Lines() []string {
+	return d.lines("error", 0)
+}
+
+func (d ErrorDetail) lines(label string, depth int) []string {
+	indent := strings.Repeat("    ", depth)
+	result := []string{indent + label + ": " + d.Message + " (" + d.Type + ")"}
+	for _, frame := range d.Stack {
+		result = append(result, indent+"    at "+frame)
+	}
+
+	if len(d.Causes) == 1 {
+		return append(result, d.Causes[0].lines("caused by", depth)...)
+	}
+	for i, cause := range d.Causes {
+		result = append(result, cause.lines("caused by ["+strconv.Itoa(i+1)+"]", depth+1)...)
+	}
+
+	return result
+}
+
+// errorCauses returns the errors wrapped by the error
+func errorCauses(err error) []error {
+	switch wrapper := err.(type) {
+	case interface{ Unwrap() []error }:
+		result := make([]error, 0)
+		for _, cause := range wrapper.Unwrap() {
+			if cause != nil {
+				result = append(result, cause)
+			}
+		}
+		return result
+	case interface{ Unwrap() error }:
+		if cause := wrapper.Unwrap(); cause != nil {
+			return []error{cause}
+		}
+	case interface{ Cause() error }:
+		if cause := wrapper.Cause(); cause != nil {
+			return []error{cause}
+		}
+	}
+
+	return nil
+}
+
+// errorStack returns the stack trace recorded by pkg/errors
+func errorStack(err error) []string {
+	tracer, ok := err.(interface{ StackTrace() pkgerrors.StackTrace })
+	if !ok {
+		return nil
+	}
+
+	result := make([]string, 0)
+	for _, frame := range tracer.StackTrace() {
+		text, _ := frame.MarshalText()
+		name, location, _ := strings.Cut(string(text), " ")
+		file, line := location, ""
+		if index := strings.LastIndex(location, ":"); index >= 0 {
+			file, line = location[:index], location[index+1:]
+		}
+		result = append(result, formatFrame(shortFunctionName(name), file, line))
+		if len(result) == maxStackFrames {
+			break
+		}
+	}
+
+	return result
+}
+
+// callerStack returns the stack of the first caller outside of the log package
+func callerStack() []string {
+	pcs := make([]uintptr, maxStackFrames+16)
+	count := runtime.Callers(2, pcs)
+	frames := runtime.CallersFrames(pcs[:count])
+
+	result := make([]string, 0)
+	skipping := true
+	for {
+		frame, more := frames.Next()
+		if skipping && isLogPackageFrame(frame) {
+			if !more {
+				break
+			}
+			continue
+		}
+		skipping = false
+		if frame.Function == "runtime.goexit" {
+			break
+		}
+
+		result = append(result, formatFrame(shortFunctionName(frame.Function), frame.File, strconv.Itoa(frame.Line)))
+		if !more || len(result) == maxStackFrames {
+			break
+		}
+	}
+
+	return result
+}
+
+func shortFunctionName(name string) string {
+	if index := strings.LastIndex(name, "/"); index >= 0 {
+		return name[index+1:]
+	}
+
+	return name
+}
+
+func formatFrame(function string, file string, line string) string {
+	return function + " (" + shortCallerPath(file) + ":" + line + ")"
+}
+
+// errorText returns the text lines with the error details and the stack for the text sinks
+func errorText(err error, stack []string) []string {
+	result := make([]string, 0)
+	if err != nil {
+		if detail := DescribeError(err); detail.HasDetails() {
+			result = append(result, detail.Lines()...)
+		}
+	}
+	if len(stack) > 0 {
+		result = append(result, "stack:")
+		for _, frame := range stack {
+			result = append(result, "    at "+frame)
+		}
+	}
+
+	return result
+}
@@ -0,0 +1,176 @@
+package log
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	pkgerrors "github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDescribeError_UnwrapsChainsAndJoinedErrors(t *testing.T) {
+	// Arrange
+	root := errors.New("connection refused")
+	err := fmt.Errorf("query failed: %w", errors.Join(root, errors.New("timeout")))
+
+	// Act
+	detail := DescribeError(err)
+
+	// Assert
+	assert.Equal(t, "query failed: connection refused\ntimeout", detail.Message)
+	assert.Len(t, detail.Causes, 1)
+	assert.Len(t, detail.Causes[0].Causes, 2)
+	assert.Equal(t, "connection refused", detail.Causes[0].Causes[0].Message)
+	assert.Equal(t, "*errors.errorString", detail.Causes[0].Causes[0].Type)
+	assert.True(t, detail.HasDetails())
+	assert.False(t, DescribeError(root).HasDetails())
+}
+
+func TestDescribeError_IncludesPkgErrorsStacks(t *testing.T) {
+	// Arrange
+	err := pkgerrors.Wrap(pkgerrors.New("boom"), "loading config")
+
+	// Act
+	detail := DescribeError(err)
+
+	// Assert
+	assert.Equal(t, "loading config: boom", detail.Message)
+	assert.NotEmpty(t, detail.Stack)
+	assert.Regexp(t, `^log\.TestDescribeError_IncludesPkgErrorsStacks \(log/errors_test\.go:\d+\)$`, detail.Stack[0])
+	assert.Len(t, detail.Causes, 1)
+	assert.Equal(t, "boom", detail.Causes[0].Message)
+	assert.Equal(t, "*errors.fundamental", detail.Causes[0].Type)
+	assert.NotEmpty(t, detail.Causes[0].Stack)
+}
+
+func TestErrorDetail_Lines(t *testing.T) {
+	// Arrange
+	err := fmt.Errorf("save: %w", errors.Join(errors.New("a"), errors.New("b")))
+
+	// Act
+	lines := DescribeError(err).Lines()
+
+	// Assert
+	assert.Equal(t, []string{
+		"error: save: a\nb (*fmt.wrapError)",
+		"caused by: a\nb (*errors.joinError)",
+		"    caused by [1]: a (*errors.errorString)",
+		"    caused by [2]: b (*errors.errorString)",
+	}, lines)
+}
+
+func TestJsonLogger_WritesErrorDetailsAndFatalStack(t *testing.T) {
+	// Arrange
+	var buffer bytes.Buffer
+	exitProcess = func(code int) {}
+	defer func() { exitProcess = os.Exit }()
+	logger := &Logger{LogLevel: Info}
+	logger.AddJsonLogger(&buffer)
+
+	// Act
+	logger.Exception(fmt.Errorf("request: %w", errors.New("denied")), "failed")
+	logger.Exception(errors.New("plain"), "failed")
+	logger.Fatal("stopping")
+
+	// Assert
+	lines := decodeJsonLines(t, &buffer)
+	details := lines[0]["error_details"].(map[string]interface{})
+	assert.Equal(t, "request: denied", details["message"])
+	assert.Equal(t, "denied", details["causes"].([]interface{})[0].(map[string]interface{})["message"])
+	assert.NotContains(t, lines[1], "error_details")
+	stack := lines[2]["stack"].([]interface{})
+	assert.Regexp(t, `^log\.TestJsonLogger_WritesErrorDetailsAndFatalStack \(log/errors_test\.go:\d+\)$`, stack[0])
+}
+
+func TestFileLogger_WritesErrorDetailsAsIndentedLines(t *testing.T) {
+	// Arrange
+	path := filepath.Join(t.TempDir(), "app.log")
+	fileLogger, _ := NewFileLogger(FileLoggerOptions{Path: path})
+	fileLogger.UseTimestamp(false)
+
+	// Act
+	fileLogger.Exception(fmt.Errorf("request: %w", errors.New("denied")), "failed")
+	fileLogger.Close()
+
+	// Assert
+	content, _ := os.ReadFile(path)
+	assert.Equal(t, strings.Join([]string{
+		`[ERROR] failed error="request: denied"`,
+		"\terror: request: denied (*fmt.wrapError)",
+		"\tcaused by: denied (*errors.errorString)",
+		"",
+	}, "\n"), string(content))
+}
+
+func TestLogger_FatalErrorWritesTheErrorBeforePanicking(t *testing.T) {
+	// Arrange
+	logger := &Logger{LogLevel: Info}
+	capture := logger.AddCaptureLogger()
+	err := errors.New("corrupted state")
+
+	// Act
+	assert.PanicsWithError(t, "corrupted state", func() { logger.FatalError(err, "cannot continue") })
+
+	// Assert
+	entries := capture.Entries()
+	assert.Len(t, entries, 1)
+	assert.Equal(t, err, entries[0].Error)
+	assert.Equal(t, "cannot continue", entries[0].Message)
+}
+
+func TestLogger_SinksWithoutFieldSupportKeepErrorDetails(t *testing.T) {
+	// Arrange
+	var buffer bytes.Buffer
+	sink := plainSink{NewCmdLogger(CmdLoggerOptions{Writer: &buffer, DisableColors: true})}
+	logger := &Logger{LogLevel: Info, Loggers: []Log{sink}}
+	err := fmt.Errorf("request: %w", errors.New("denied"))
+
+	// Act
+	logger.With("user", "bob").Exception(err, "")
+	logger.With("user", "bob").LogError(err)
+
+	// Assert
+	lines := strings.Split(buffer.String(), "\n")
+	assert.Equal(t, "request: denied user=bob, err request: denied", lines[0])
+	assert.Equal(t, "\tcaused by: denied (*errors.errorString)", lines[2])
+	assert.Equal(t, "request: denied user=bob, err request: denied", lines[3])
+	assert.Equal(t, "\tcaused by: denied (*errors.errorString)", lines[5])
+}
+
+func TestLogger_FatalErrorHooksSeeTheStack(t *testing.T) {
+	// Arrange
+	logger := &Logger{LogLevel: Info}
+	capture := logger.AddCaptureLogger()
+	stacks := make([][]string, 0)
+	logger.AddHook(func(entry Entry) error {
+		stacks = append(stacks, entry.Stack)
+		return nil
+	})
+
+	// Act
+	assert.Panics(t, func() { logger.FatalError(errors.New("broken"), "") })
+
+	// Assert
+	assert.Len(t, stacks, 1)
+	assert.Regexp(t, `^log\.TestLogger_FatalErrorHooksSeeTheStack\.func2 \(log/errors_test\.go:\d+\)$`, stacks[0][0])
+	entries := capture.Entries()
+	assert.Equal(t, "broken", entries[0].Message)
+	assert.NotEmpty(t, entries[0].Stack)
+}
+
+func TestCmdLogger_PrintsErrorDetails(t *testing.T) {
+	// Arrange
+	var buffer bytes.Buffer
+	logger := NewCmdLogger(CmdLoggerOptions{Writer: &buffer, DisableColors: true})
+
+	// Act
+	logger.Exception(fmt.Errorf("100%% %w", errors.New("broken")), "")
+
+	// Assert
+	assert.Equal(t, "100% broken\n\terror: 100% broken (*fmt.wrapError)\n\tcaused by: broken (*errors.errorString)\n", buffer.String())
+}
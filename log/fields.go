@@ -0,0 +1,276 @@
+package log
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	strcolor "github.com/cjlapao/common-go/strcolor"
+)
+
+// Fields are the key value pairs attached to the log entries
+type Fields map[string]interface{}
+
+// FieldLogger is implemented by sinks that render fields natively, the returned logger
+// writes the fields with every message and shares the output of the original sink.
+// Sinks that do not implement it receive the fields appended to the message as key=value
+type FieldLogger interface {
+	WithFields(fields map[string]interface{}) Log
+}
+
+const badFieldKey = "!BADKEY"
+
+// With Returns a child logger that adds the fields to every message, the arguments are
+// alternating key and value pairs or Fields maps, for example With("user", id, "attempt", 2).
+// The child logger shares the sinks and the level of the logger it was created from
+func (l *Logger) With(keyValues ...interface{}) *Logger {
+	fields := make(map[string]interface{})
+	for i := 0; i < len(keyValues); i++ {
+		switch key := keyValues[i].(type) {
+		case Fields:
+			for name, value := range key {
+				fields[name] = value
+			}
+		case map[string]interface{}:
+			for name, value := range key {
+				fields[name] = value
+			}
+		case string:
+			if i+1 < len(keyValues) {
+				fields[key] = keyValues[i+1]
+				i++
+			} else {
+				fields[badFieldKey] = key
+			}
+		default:
+			fields[badFieldKey] = key
+		}
+	}
+
+	return &Logger{
+		HighlightColor: l.HighlightColor,
+		parent:         l.rootLogger(),
+		fields:         mergeFields(l.fields, fields),
+		correlationId:  l.correlationId,
+	}
+}
+
+// WithField Returns a child logger that adds the field to every message
+func (l *Logger) WithField(key string, value interface{}) *Logger {
+	return l.With(key, value)
+}
+
+// WithError Returns a child logger that adds the error as the error field
+func (l *Logger) WithError(err error) *Logger {
+	return l.With("error", err)
+}
+
+// Fields Returns a copy of the fields of the logger
+func (l *Logger) Fields() Fields {
+	return Fields(mergeFields(nil, l.fields))
+}
+
+// rootLogger returns the logger owning the sinks and the level
+func (l *Logger) rootLogger() *Logger {
+	if l.parent != nil {
+		return l.parent
+	}
+
+	return l
+}
+
+// sinks returns the sinks of the root logger that write messages of the level carrying
+// the fields and the correlation id of this logger
+func (l *Logger) sinks(level Level) []Log {
+	loggers := l.enabledSinks(level)
+	if len(l.fields) == 0 && l.correlationId == "" {
+		return loggers
+	}
+
+	result := make([]Log, len(loggers))
+	for i, logger := range loggers {
+		result[i] = decorateSink(logger, l.fields, l.correlationId)
+	}
+
+	return result
+}
+
+// decorateSink returns the sink writing the correlation id and the fields, sinks without
+// field support receive the fields appended to the format
+func decorateSink(sink Log, fields map[string]interface{}, correlationId string) Log {
+	if correlationLogger, ok := sink.(CorrelationIdLogger); ok && correlationId != "" {
+		sink = correlationLogger.WithCorrelationId(correlationId)
+	}
+	if len(fields) == 0 {
+		return sink
+	}
+	if fieldLogger, ok := sink.(FieldLogger); ok {
+		return fieldLogger.WithFields(fields)
+	}
+
+	return &textFieldLogger{sink: sink, suffix: " " + strings.ReplaceAll(renderFields(fields), "%", "%%")}
+}
+
+// textFieldLogger appends the fields to the format of the sinks without field support
+type textFieldLogger struct {
+	sink   Log
+	suffix string
+}
+
+func (l *textFieldLogger) UseTimestamp(value bool) {
+	l.sink.UseTimestamp(value)
+}
+
+func (l *textFieldLogger) UseCorrelationId(value bool) {
+	l.sink.UseCorrelationId(value)
+}
+
+func (l *textFieldLogger) Log(format string, level Level, words ...string) {
+	l.sink.Log(format+l.suffix, level, words...)
+}
+
+func (l *textFieldLogger) LogHighlight(format string, level Level, highlightColor strcolor.ColorCode, words ...string) {
+	l.sink.LogHighlight(format+l.suffix, level, highlightColor, words...)
+}
+
+func (l *textFieldLogger) Info(format string, words ...string) {
+	l.sink.Info(format+l.suffix, words...)
+}
+
+func (l *textFieldLogger) Success(format string, words ...string) {
+	l.sink.Success(format+l.suffix, words...)
+}
+
+func (l *textFieldLogger) TaskSuccess(format string, isComplete bool, words ...string) {
+	l.sink.TaskSuccess(format+l.suffix, isComplete, words...)
+}
+
+func (l *textFieldLogger) Warn(format string, words ...string) {
+	l.sink.Warn(format+l.suffix, words...)
+}
+
+func (l *textFieldLogger) TaskWarn(format string, words ...string) {
+	l.sink.TaskWarn(format+l.suffix, words...)
+}
+
+func (l *textFieldLogger) Command(format string, words ...string) {
+	l.sink.Command(format+l.suffix, words...)
+}
+
+func (l *textFieldLogger) Disabled(format string, words ...string) {
+	l.sink.Disabled(format+l.suffix, words...)
+}
+
+func (l *textFieldLogger) Notice(format string, words ...string) {
+	l.sink.Notice(format+l.suffix, words...)
+}
+
+func (l *textFieldLogger) Debug(format string, words ...string) {
+	l.sink.Debug(format+l.suffix, words...)
+}
+
+func (l *textFieldLogger) Trace(format string, words ...string) {
+	l.sink.Trace(format+l.suffix, words...)
+}
+
+func (l *textFieldLogger) Error(format string, words ...string) {
+	l.sink.Error(format+l.suffix, words...)
+}
+
+func (l *textFieldLogger) Exception(err error, format string, words ...string) {
+	if format == "" && err != nil {
+		format = exceptionFormat(err, format)
+	}
+	l.sink.Exception(err, format+l.suffix, words...)
+}
+
+func (l *textFieldLogger) LogError(message error) {
+	if message != nil {
+		l.sink.Exception(message, strings.ReplaceAll(message.Error(), "%", "%%")+l.suffix)
+	}
+}
+
+func (l *textFieldLogger) TaskError(format string, isComplete bool, words ...string) {
+	l.sink.TaskError(format+l.suffix, isComplete, words...)
+}
+
+func (l *textFieldLogger) Fatal(format string, words ...string) {
+	l.sink.Fatal(format+l.suffix, words...)
+}
+
+func (l *textFieldLogger) FatalError(e error, format string, words ...string) {
+	l.sink.FatalError(e, format+l.suffix, words...)
+}
+
+func (l *textFieldLogger) writeTaskSuccess(format string, isComplete bool, words ...string) {
+	sinkTaskSuccess(l.sink, format+l.suffix, isComplete, words...)
+}
+
+func (l *textFieldLogger) writeTaskError(format string, isComplete bool, words ...string) {
+	sinkTaskError(l.sink, format+l.suffix, isComplete, words...)
+}
+
+func (l *textFieldLogger) writeFatal(format string, words ...string) {
+	sinkFatal(l.sink, format+l.suffix, words...)
+}
+
+func (l *textFieldLogger) writeFatalError(e error, format string, words ...string) {
+	sinkFatalError(l.sink, e, format+l.suffix, words...)
+}
+
+func mergeFields(base map[string]interface{}, fields map[string]interface{}) map[string]interface{} {
+	result := make(map[string]interface{}, len(base)+len(fields))
+	for key, value := range base {
+		result[key] = value
+	}
+	for key, value := range fields {
+		result[key] = value
+	}
+
+	return result
+}
+
+func sortedFieldKeys(fields map[string]interface{}) []string {
+	keys := make([]string, 0, len(fields))
+	for key := range fields {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	return keys
+}
+
+// renderFields renders the fields as key=value pairs ordered by key, values with spaces
+// or quotes are quoted
+func renderFields(fields map[string]interface{}) string {
+	parts := make([]string, 0, len(fields))
+	for _, key := range sortedFieldKeys(fields) {
+		parts = append(parts, key+"="+renderFieldValue(fields[key]))
+	}
+
+	return strings.Join(parts, " ")
+}
+
+func renderFieldValue(value interface{}) string {
+	text := fieldText(value)
+	if text == "" || strings.ContainsAny(text, " \t\n\"=") {
+		return strconv.Quote(text)
+	}
+
+	return text
+}
+
+// fieldText renders the field value without quoting it
+func fieldText(value interface{}) string {
+	switch item := value.(type) {
+	case nil:
+		return "<nil>"
+	case error:
+		return item.Error()
+	case fmt.Stringer:
+		return item.String()
+	}
+
+	return fmt.Sprintf("%+v", value)
+}
@@ -0,0 +1,85 @@
+package log
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type wrappedSink interface {
+	Log
+}
+
+// plainSink hides the field support of the wrapped sink
+type plainSink struct {
+	wrappedSink
+}
+
+func TestLoggerWith_JsonSinkWritesNativeFields(t *testing.T) {
+	// Arrange
+	var buffer bytes.Buffer
+	logger := &Logger{LogLevel: Info}
+	logger.AddJsonLogger(&buffer)
+	child := logger.With("user", "alice", "attempt", 2).WithField("ok", true)
+
+	// Act
+	child.Info("login")
+	child.WithError(errors.New("denied")).Warn("retry")
+	logger.Info("no fields")
+
+	// Assert
+	lines := decodeJsonLines(t, &buffer)
+	assert.Len(t, lines, 3)
+	assert.Equal(t, "alice", lines[0]["user"])
+	assert.Equal(t, float64(2), lines[0]["attempt"])
+	assert.Equal(t, true, lines[0]["ok"])
+	assert.Equal(t, "denied", lines[1]["error"])
+	assert.Equal(t, "alice", lines[1]["user"])
+	assert.NotContains(t, lines[2], "user")
+	assert.Equal(t, Fields{"user": "alice", "attempt": 2, "ok": true}, child.Fields())
+}
+
+func TestLoggerWith_ChildSharesSinksAndLevel(t *testing.T) {
+	// Arrange
+	var buffer bytes.Buffer
+	logger := &Logger{LogLevel: Info}
+	child := logger.With(Fields{"component": "db"})
+	logger.AddJsonLogger(&buffer)
+
+	// Act
+	child.Debug("filtered")
+	logger.WithDebug()
+	child.Debug("written")
+
+	// Assert
+	lines := decodeJsonLines(t, &buffer)
+	assert.Len(t, lines, 1)
+	assert.Equal(t, "written", lines[0]["message"])
+	assert.Equal(t, "db", lines[0]["component"])
+}
+
+func TestLoggerWith_SinksWithoutFieldSupportReceiveKeyValueText(t *testing.T) {
+	// Arrange
+	var buffer bytes.Buffer
+	logger := &Logger{LogLevel: Info, Loggers: []Log{plainSink{NewJsonLogger(&buffer)}}}
+
+	// Act
+	logger.With("path", "/api v1", "rate", "100%", "odd").Info("request %s", "done")
+
+	// Assert
+	lines := decodeJsonLines(t, &buffer)
+	assert.Equal(t, `request done !BADKEY=odd path="/api v1" rate=100%`, lines[0]["message"])
+}
+
+func TestCmdLogger_FormatsFieldsAsKeyValue(t *testing.T) {
+	// Arrange
+	logger := new(CmdLogger).WithFields(map[string]interface{}{"user": "bob", "err": errors.New("not found")})
+
+	// Act
+	format := logger.(*CmdLogger).formatWithFields("message")
+
+	// Assert
+	assert.Equal(t, `message err="not found" user=bob`, format)
+}
@@ -0,0 +1,378 @@
+package log
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/cjlapao/common-go/helper"
+)
+
+// FileLogFormat defines how the file logger writes the entries
+type FileLogFormat int
+
+const (
+	FileLogFormatText FileLogFormat = iota
+	FileLogFormatJson
+)
+
+const backupTimeFormat = "20060102T150405.000"
+
+// FileLoggerOptions configures the file logger and its rotation, a zero MaxSize or MaxAge
+// disables that rotation and a zero MaxBackups keeps every rotated file
+type FileLoggerOptions struct {
+	Path       string
+	Format     FileLogFormat
+	MaxSize    int64
+	MaxAge     time.Duration
+	MaxBackups int
+	Compress   bool
+}
+
+// RotatingFileWriter is a writer safe for concurrent use that rotates the file when it
+// reaches the maximum size or age. Rotated files are renamed with a timestamp, for example
+// app-20240501T100000.000.log, and optionally compressed with gzip
+type RotatingFileWriter struct {
+	mutex       sync.Mutex
+	options     FileLoggerOptions
+	file        *os.File
+	size        int64
+	openedAt    time.Time
+	maintenance sync.WaitGroup
+	pruneMutex  sync.Mutex
+}
+
+// NewRotatingFileWriter Creates the writer opening or creating the file in append mode
+func NewRotatingFileWriter(options FileLoggerOptions) (*RotatingFileWriter, error) {
+	if options.Path == "" {
+		return nil, errors.New("log file path is empty")
+	}
+
+	writer := &RotatingFileWriter{options: options}
+	if err := writer.open(); err != nil {
+		return nil, err
+	}
+
+	return writer, nil
+}
+
+// Write Writes to the file, rotating it first if the data would exceed the limits
+func (w *RotatingFileWriter) Write(p []byte) (int, error) {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+
+	if w.file == nil {
+		if err := w.open(); err != nil {
+			return 0, err
+		}
+	}
+
+	if w.shouldRotate(int64(len(p))) {
+		if err := w.rotate(); err != nil {
+			return 0, err
+		}
+	}
+
+	n, err := w.file.Write(p)
+	w.size += int64(n)
+	return n, err
+}
+
+// Rotate Rotates the file regardless of its size and age
+func (w *RotatingFileWriter) Rotate() error {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+
+	return w.rotate()
+}
+
+// Close Closes the file and waits for the pending compression of rotated files
+func (w *RotatingFileWriter) Close() error {
+	w.mutex.Lock()
+	var err error
+	if w.file != nil {
+		err = w.file.Close()
+		w.file = nil
+	}
+	w.mutex.Unlock()
+
+	w.maintenance.Wait()
+	return err
+}
+
+// Backups Returns the rotated files ordered from the oldest to the newest
+func (w *RotatingFileWriter) Backups() []string {
+	directory, prefix, extension := w.backupParts()
+	entries, err := os.ReadDir(directory)
+	if err != nil {
+		return []string{}
+	}
+
+	type backup struct {
+		path      string
+		rotatedAt time.Time
+		index     int
+	}
+
+	backups := make([]backup, 0)
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+		if rotatedAt, index, ok := parseBackupName(entry.Name(), prefix, extension); ok {
+			backups = append(backups, backup{filepath.Join(directory, entry.Name()), rotatedAt, index})
+		}
+	}
+	sort.Slice(backups, func(i, j int) bool {
+		if !backups[i].rotatedAt.Equal(backups[j].rotatedAt) {
+			return backups[i].rotatedAt.Before(backups[j].rotatedAt)
+		}
+		return backups[i].index < backups[j].index
+	})
+
+	result := make([]string, len(backups))
+	for i, backup := range backups {
+		result[i] = backup.path
+	}
+
+	return result
+}
+
+// parseBackupName returns the rotation time and the same time suffix of a rotated file name
+func parseBackupName(name string, prefix string, extension string) (time.Time, int, bool) {
+	if !strings.HasPrefix(name, prefix) {
+		return time.Time{}, 0, false
+	}
+	name = strings.TrimSuffix(strings.TrimPrefix(name, prefix), ".gz")
+	if !strings.HasSuffix(name, extension) {
+		return time.Time{}, 0, false
+	}
+	name = strings.TrimSuffix(name, extension)
+
+	index := 0
+	if len(name) > len(backupTimeFormat) {
+		suffix := name[len(backupTimeFormat):]
+		if suffix[0] != '-' {
+			return time.Time{}, 0, false
+		}
+		value, err := strconv.Atoi(suffix[1:])
+		if err != nil || value < 0 {
+			return time.Time{}, 0, false
+		}
+		index = value
+		name = name[:len(backupTimeFormat)]
+	}
+
+	rotatedAt, err := time.ParseInLocation(backupTimeFormat, name, time.Local)
+	if err != nil {
+		return time.Time{}, 0, false
+	}
+
+	return rotatedAt, index, true
+}
+
+func (w *RotatingFileWriter) open() error {
+	if err := os.MkdirAll(filepath.Dir(w.options.Path), 0o755); err != nil {
+		return err
+	}
+
+	file, err := os.OpenFile(w.options.Path, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0o644)
+	if err != nil {
+		return err
+	}
+
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return err
+	}
+
+	w.file = file
+	w.size = info.Size()
+	w.openedAt = w.startedAt(info)
+	return nil
+}
+
+// startedAt returns when the current file was started so a process restarting more often than
+// MaxAge still rotates it. A reopened file was started by the last rotation, or at its last
+// modification when it was never rotated
+func (w *RotatingFileWriter) startedAt(info os.FileInfo) time.Time {
+	if info.Size() == 0 {
+		return time.Now()
+	}
+
+	directory, prefix, extension := w.backupParts()
+	backups := w.Backups()
+	if len(backups) > 0 {
+		name := strings.TrimPrefix(backups[len(backups)-1], directory+string(filepath.Separator))
+		if rotatedAt, _, ok := parseBackupName(name, prefix, extension); ok && rotatedAt.Before(info.ModTime()) {
+			return rotatedAt
+		}
+	}
+
+	return info.ModTime()
+}
+
+func (w *RotatingFileWriter) shouldRotate(length int64) bool {
+	if w.size == 0 {
+		return false
+	}
+	if w.options.MaxSize > 0 && w.size+length > w.options.MaxSize {
+		return true
+	}
+
+	return w.options.MaxAge > 0 && time.Since(w.openedAt) >= w.options.MaxAge
+}
+
+func (w *RotatingFileWriter) rotate() error {
+	if w.file != nil {
+		if err := w.file.Close(); err != nil {
+			return err
+		}
+		w.file = nil
+	}
+
+	directory, prefix, extension := w.backupParts()
+	backup := filepath.Join(directory, prefix+time.Now().Format(backupTimeFormat)+extension)
+	for index := 1; helper.FileExists(backup) || helper.FileExists(backup+".gz"); index++ {
+		backup = filepath.Join(directory, prefix+time.Now().Format(backupTimeFormat)+fmt.Sprintf("-%03d", index)+extension)
+	}
+
+	if err := os.Rename(w.options.Path, backup); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+
+	if err := w.open(); err != nil {
+		return err
+	}
+
+	w.maintenance.Add(1)
+	go func() {
+		defer w.maintenance.Done()
+		w.compressAndPrune(backup)
+	}()
+
+	return nil
+}
+
+func (w *RotatingFileWriter) compressAndPrune(backup string) {
+	w.pruneMutex.Lock()
+	defer w.pruneMutex.Unlock()
+
+	if w.options.Compress && helper.FileExists(backup) {
+		if err := helper.Gzip(backup, backup+".gz"); err == nil {
+			os.Remove(backup)
+		}
+	}
+
+	if w.options.MaxBackups <= 0 {
+		return
+	}
+
+	backups := w.Backups()
+	for len(backups) > w.options.MaxBackups {
+		os.Remove(backups[0])
+		backups = backups[1:]
+	}
+}
+
+// backupParts returns the directory, the name prefix and the extension of the rotated files
+func (w *RotatingFileWriter) backupParts() (string, string, string) {
+	directory, name := filepath.Split(w.options.Path)
+	extension := filepath.Ext(name)
+	return filepath.Clean(directory), strings.TrimSuffix(name, extension) + "-", extension
+}
+
+// FileLogger writes the entries to a rotating file as text lines or as JSON objects
+type FileLogger struct {
+	baseLogger
+	writer *RotatingFileWriter
+}
+
+// NewFileLogger Creates a file logger with the rotation options
+func NewFileLogger(options FileLoggerOptions) (*FileLogger, error) {
+	writer, err := NewRotatingFileWriter(options)
+	if err != nil {
+		return nil, err
+	}
+
+	encode := encodeTextEntry
+	if options.Format == FileLogFormatJson {
+		encode = encodeJsonEntry
+	}
+
+	return &FileLogger{
+		baseLogger: newBaseLogger(writer, encode),
+		writer:     writer,
+	}, nil
+}
+
+// WithFields Returns a logger sharing the same file that also writes the fields
+func (l *FileLogger) WithFields(fields map[string]interface{}) Log {
+	return &FileLogger{
+		baseLogger: l.withFields(fields),
+		writer:     l.writer,
+	}
+}
+
+// WithCorrelationId Returns a logger sharing the same file that writes the correlation id
+func (l *FileLogger) WithCorrelationId(correlationId string) Log {
+	return &FileLogger{
+		baseLogger: l.withCorrelationId(correlationId),
+		writer:     l.writer,
+	}
+}
+
+func (l *FileLogger) withCaller(caller string, at time.Time) Log {
+	return &FileLogger{
+		baseLogger: l.withCallerLocation(caller, at),
+		writer:     l.writer,
+	}
+}
+
+// Rotate Rotates the log file
+func (l *FileLogger) Rotate() error {
+	return l.writer.Rotate()
+}
+
+// Close Closes the log file
+func (l *FileLogger) Close() error {
+	return l.writer.Close()
+}
+
+// encodeTextEntry renders the entry as a single text line with the fields as key=value
+func encodeTextEntry(entry Entry, useTimestamp bool) []byte {
+	var buffer bytes.Buffer
+	if useTimestamp {
+		buffer.WriteString(entry.Time.Format(time.RFC3339))
+		buffer.WriteByte(' ')
+	}
+	buffer.WriteString("[" + strings.ToUpper(entry.Level.String()) + "] ")
+	if entry.CorrelationId != "" {
+		buffer.WriteString("[" + entry.CorrelationId + "] ")
+	}
+	buffer.WriteString(strings.ReplaceAll(entry.Message, "\n", "\\n"))
+
+	fields := entry.Fields
+	if entry.Error != nil {
+		fields = mergeFields(fields, map[string]interface{}{"error": entry.Error})
+	}
+	if len(fields) > 0 {
+		buffer.WriteByte(' ')
+		buffer.WriteString(renderFields(fields))
+	}
+
+	buffer.WriteByte('\n')
+	for _, line := range errorText(entry.Error, entry.Stack) {
+		buffer.WriteString("\t" + line + "\n")
+	}
+
+	return buffer.Bytes()
+}
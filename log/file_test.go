@@ -0,0 +1,163 @@
+package log
+
+import (
+	"compress/gzip"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFileLogger_WritesTextLines(t *testing.T) {
+	// Arrange
+	path := filepath.Join(t.TempDir(), "logs", "app.log")
+	logger := &Logger{LogLevel: Info}
+	fileLogger, err := logger.AddFileLogger(FileLoggerOptions{Path: path})
+	assert.Nil(t, err)
+	fileLogger.UseTimestamp(false)
+
+	// Act
+	logger.With("user", "alice").Info("hello %s", "world")
+	logger.Warn("careful")
+	fileLogger.Close()
+
+	// Assert
+	content, _ := os.ReadFile(path)
+	assert.Equal(t, "[INFO] hello world user=alice\n[WARN] careful\n", string(content))
+}
+
+func TestFileLogger_RotatesBySizeAndKeepsBackups(t *testing.T) {
+	// Arrange
+	directory := t.TempDir()
+	path := filepath.Join(directory, "app.log")
+	os.WriteFile(filepath.Join(directory, "app-server.log"), []byte("other"), 0o644)
+	fileLogger, _ := NewFileLogger(FileLoggerOptions{Path: path, MaxSize: 64, MaxBackups: 2})
+	fileLogger.UseTimestamp(false)
+
+	// Act
+	for i := 0; i < 10; i++ {
+		fileLogger.Info("message number %s", fmt.Sprint(i))
+	}
+	fileLogger.Close()
+
+	// Assert
+	backups := fileLogger.writer.Backups()
+	assert.Len(t, backups, 2)
+	content, _ := os.ReadFile(path)
+	assert.Equal(t, "[INFO] message number 8\n[INFO] message number 9\n", string(content))
+	assert.FileExists(t, filepath.Join(directory, "app-server.log"))
+}
+
+func TestFileLogger_RotatesByAgeAndCompresses(t *testing.T) {
+	// Arrange
+	path := filepath.Join(t.TempDir(), "app.log")
+	fileLogger, _ := NewFileLogger(FileLoggerOptions{Path: path, MaxAge: 20 * time.Millisecond, Compress: true, Format: FileLogFormatJson})
+
+	// Act
+	fileLogger.Info("first")
+	time.Sleep(30 * time.Millisecond)
+	fileLogger.Info("second")
+	fileLogger.Close()
+
+	// Assert
+	backups := fileLogger.writer.Backups()
+	assert.Len(t, backups, 1)
+	assert.True(t, strings.HasSuffix(backups[0], ".log.gz"))
+	file, _ := os.Open(backups[0])
+	defer file.Close()
+	reader, err := gzip.NewReader(file)
+	assert.Nil(t, err)
+	content, _ := io.ReadAll(reader)
+	assert.Contains(t, string(content), `"message":"first"`)
+}
+
+func TestRotatingFileWriter_ReopenedFileKeepsItsAge(t *testing.T) {
+	// Arrange
+	directory := t.TempDir()
+	modified := filepath.Join(directory, "modified.log")
+	rotated := filepath.Join(directory, "rotated.log")
+	os.WriteFile(modified, []byte("old\n"), 0o644)
+	os.Chtimes(modified, time.Now().Add(-2*time.Hour), time.Now().Add(-2*time.Hour))
+	os.WriteFile(rotated, []byte("old\n"), 0o644)
+	lastRotation := time.Now().Add(-2 * time.Hour).Format(backupTimeFormat)
+	os.WriteFile(filepath.Join(directory, "rotated-"+lastRotation+".log"), []byte("older\n"), 0o644)
+
+	for _, path := range []string{modified, rotated} {
+		writer, _ := NewRotatingFileWriter(FileLoggerOptions{Path: path, MaxAge: time.Hour})
+
+		// Act
+		writer.Write([]byte("new\n"))
+		writer.Close()
+
+		// Assert
+		content, _ := os.ReadFile(path)
+		assert.Equal(t, "new\n", string(content), path)
+	}
+}
+
+func TestRotatingFileWriter_BackupsAreOrderedByRotationTime(t *testing.T) {
+	// Arrange
+	directory := t.TempDir()
+	names := []string{
+		"app-20240501T100000.000-010.log.gz",
+		"app-20240501T100000.000-002.log",
+		"app-20240501T100000.000.log.gz",
+		"app-20240501T095959.999.log",
+		"app-20240501T100000.000-x.log",
+		"app-server.log",
+	}
+	for _, name := range names {
+		os.WriteFile(filepath.Join(directory, name), []byte("line\n"), 0o644)
+	}
+	writer, _ := NewRotatingFileWriter(FileLoggerOptions{Path: filepath.Join(directory, "app.log")})
+	defer writer.Close()
+
+	// Act
+	backups := writer.Backups()
+
+	// Assert
+	assert.Equal(t, []string{
+		filepath.Join(directory, "app-20240501T095959.999.log"),
+		filepath.Join(directory, "app-20240501T100000.000.log.gz"),
+		filepath.Join(directory, "app-20240501T100000.000-002.log"),
+		filepath.Join(directory, "app-20240501T100000.000-010.log.gz"),
+	}, backups)
+}
+
+func TestFileLogger_ConcurrentWrites(t *testing.T) {
+	// Arrange
+	path := filepath.Join(t.TempDir(), "app.log")
+	fileLogger, _ := NewFileLogger(FileLoggerOptions{Path: path, MaxSize: 4096})
+	fileLogger.UseTimestamp(false)
+	var wg sync.WaitGroup
+
+	// Act
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 50; j++ {
+				fileLogger.Info("concurrent message")
+			}
+		}()
+	}
+	wg.Wait()
+	fileLogger.Close()
+
+	// Assert
+	lines := 0
+	for _, file := range append(fileLogger.writer.Backups(), path) {
+		content, _ := os.ReadFile(file)
+		for _, line := range strings.Split(strings.TrimSpace(string(content)), "\n") {
+			assert.Equal(t, "[INFO] concurrent message", line)
+			lines++
+		}
+	}
+	assert.Equal(t, 500, lines)
+}
@@ -0,0 +1,75 @@
+package log
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"time"
+)
+
+// Hook is called with every entry before it is written to the sinks, returning ErrDropEntry
+// discards the entry and any other error is reported on stderr. Dropping a fatal or completed
+// task entry does not stop the logger from ending the process
+type Hook func(entry Entry) error
+
+// ErrDropEntry is returned by a hook to discard the entry
+var ErrDropEntry = errors.New("log entry dropped by hook")
+
+// AddHook Adds a hook that runs before the sinks, hooks only run for the entries that at
+// least one sink writes
+func (l *Logger) AddHook(hook Hook) *Logger {
+	if hook == nil {
+		return l
+	}
+
+	root := l.rootLogger()
+	root.hookMutex.Lock()
+	defer root.hookMutex.Unlock()
+	root.hooks = append(root.hooks, hook)
+	return l
+}
+
+// dispatch runs the hooks and returns the sinks that write the entry, it returns no sinks
+// if a hook dropped the entry
+func (l *Logger) dispatch(level Level, kind string, err error, format string, words []string) []Log {
+	return l.dispatchWithStack(level, kind, err, nil, format, words)
+}
+
+// dispatchWithStack runs the hooks with the stack of the fatal messages
+func (l *Logger) dispatchWithStack(level Level, kind string, err error, stack []string, format string, words []string) []Log {
+	sinks := l.sinks(level)
+	if len(sinks) == 0 {
+		return sinks
+	}
+
+	root := l.rootLogger()
+	root.hookMutex.RLock()
+	hooks := root.hooks
+	root.hookMutex.RUnlock()
+	if len(hooks) == 0 {
+		return sinks
+	}
+
+	entry := Entry{
+		Time:          time.Now(),
+		Level:         level,
+		Kind:          kind,
+		Message:       formatMessage(format, words...),
+		CorrelationId: l.CorrelationId(),
+		Caller:        callerLocation(),
+		Error:         err,
+		Stack:         stack,
+		Fields:        l.Fields(),
+	}
+
+	for _, hook := range hooks {
+		if hookErr := hook(entry); hookErr != nil {
+			if errors.Is(hookErr, ErrDropEntry) {
+				return nil
+			}
+			fmt.Fprintf(os.Stderr, "log hook failed: %v\n", hookErr)
+		}
+	}
+
+	return sinks
+}
@@ -0,0 +1,99 @@
+package log
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLogger_HooksSeeEntriesBeforeTheSinks(t *testing.T) {
+	// Arrange
+	logger := &Logger{LogLevel: Info}
+	capture := logger.AddCaptureLogger()
+	seen := make([]Entry, 0)
+	logger.AddHook(func(entry Entry) error {
+		seen = append(seen, entry)
+		return nil
+	})
+
+	// Act
+	logger.Ctx(ContextWithCorrelationId(context.Background(), "abc")).With("user", "bob").Warn("hello %s", "world")
+	logger.Exception(errors.New("boom"), "")
+	logger.Debug("filtered by level")
+
+	// Assert
+	assert.Len(t, seen, 2)
+	assert.Equal(t, Warning, seen[0].Level)
+	assert.Equal(t, "hello world", seen[0].Message)
+	assert.Equal(t, "abc", seen[0].CorrelationId)
+	assert.Equal(t, "bob", seen[0].Fields["user"])
+	assert.Regexp(t, `^log/hooks_test\.go:\d+$`, seen[0].Caller)
+	assert.Equal(t, "boom", seen[1].Message)
+	assert.EqualError(t, seen[1].Error, "boom")
+	assert.Equal(t, []string{"hello world", "boom"}, capture.Messages())
+}
+
+func TestLogger_HookCanDropEntries(t *testing.T) {
+	// Arrange
+	logger := &Logger{LogLevel: Info}
+	capture := logger.AddCaptureLogger()
+	logger.AddHook(func(entry Entry) error {
+		if entry.Fields["health"] == true {
+			return ErrDropEntry
+		}
+		return errors.New("ignored")
+	})
+
+	// Act
+	logger.With("health", true).Info("probe")
+	logger.Info("request")
+
+	// Assert
+	assert.Equal(t, []string{"request"}, capture.Messages())
+}
+
+func TestLogger_DroppedFatalEntriesStillEndTheProcess(t *testing.T) {
+	// Arrange
+	exitCodes := make([]int, 0)
+	logger := &Logger{LogLevel: Info}
+	logger.SetExitHandler(func(code int) { exitCodes = append(exitCodes, code) })
+	capture := logger.AddCaptureLogger()
+	logger.AddHook(func(entry Entry) error { return ErrDropEntry })
+	err := errors.New("corrupted state")
+
+	// Act
+	logger.Fatal("fatal")
+	logger.TaskSuccess("done", true)
+	logger.TaskError("failed", true)
+
+	// Assert
+	assert.PanicsWithError(t, "corrupted state", func() { logger.FatalError(err, "cannot continue") })
+	assert.Equal(t, []int{1, 0, 1}, exitCodes)
+	assert.Empty(t, capture.Entries())
+}
+
+func TestCaptureLogger_RecordsFatalAndCompletedTasks(t *testing.T) {
+	// Arrange
+	exitCodes := make([]int, 0)
+	logger := &Logger{LogLevel: Trace}
+	logger.SetExitHandler(func(code int) { exitCodes = append(exitCodes, code) })
+	capture := logger.AddCaptureLogger()
+
+	// Act
+	logger.With("id", 1).Trace("trace")
+	logger.Fatal("fatal")
+	logger.TaskSuccess("done", true)
+
+	// Assert
+	entries := capture.Entries()
+	assert.Len(t, entries, 3)
+	assert.Equal(t, Trace, entries[0].Level)
+	assert.Equal(t, 1, entries[0].Fields["id"])
+	assert.Len(t, capture.Find(Error, "fat"), 1)
+	assert.Equal(t, KindSuccess, entries[2].Kind)
+	assert.Equal(t, []int{1, 0}, exitCodes)
+	capture.Reset()
+	assert.Empty(t, capture.Entries())
+}
@@ -0,0 +1,174 @@
+package log
+
+import (
+	"bytes"
+	"encoding/binary"
+	"strings"
+	"time"
+)
+
+const defaultJournaldSocket = "/run/systemd/journal/socket"
+
+// journaldReservedFields are written by the logger or have a meaning for the journal, user
+// fields with these names are prefixed with FIELD_ so they cannot replace them
+var journaldReservedFields = map[string]bool{
+	"MESSAGE":            true,
+	"MESSAGE_ID":         true,
+	"PRIORITY":           true,
+	"CODE_FILE":          true,
+	"CODE_LINE":          true,
+	"CODE_FUNC":          true,
+	"ERRNO":              true,
+	"ERROR":              true,
+	"CORRELATION_ID":     true,
+	"DOCUMENTATION":      true,
+	"INVOCATION_ID":      true,
+	"USER_INVOCATION_ID": true,
+	"SYSLOG_FACILITY":    true,
+	"SYSLOG_IDENTIFIER":  true,
+	"SYSLOG_PID":         true,
+	"SYSLOG_TIMESTAMP":   true,
+	"SYSLOG_RAW":         true,
+	"TID":                true,
+}
+
+// JournaldOptions configures the journald logger, the socket defaults to the systemd journal
+// socket and the identifier to the name of the executable
+type JournaldOptions struct {
+	SocketPath  string
+	Identifier  string
+	DialTimeout time.Duration
+}
+
+// JournaldLogger sends the entries to the systemd journal using its native protocol, the
+// fields are written as journal fields with upper case names. Every entry is sent as a single
+// datagram so entries larger than the socket buffer are dropped by the kernel
+type JournaldLogger struct {
+	baseLogger
+	connection *socketWriter
+}
+
+// NewJournaldLogger Creates a journald logger connected to the journal socket
+func NewJournaldLogger(options JournaldOptions) (*JournaldLogger, error) {
+	if options.SocketPath == "" {
+		options.SocketPath = defaultJournaldSocket
+	}
+	if options.Identifier == "" {
+		options.Identifier = defaultAppName()
+	}
+
+	connection, err := newSocketWriter("unixgram", options.SocketPath, options.DialTimeout)
+	if err != nil {
+		return nil, err
+	}
+
+	encode := func(entry Entry, useTimestamp bool) []byte {
+		return encodeJournaldEntry(entry, options.Identifier)
+	}
+
+	return &JournaldLogger{
+		baseLogger: newBaseLogger(connection, encode),
+		connection: connection,
+	}, nil
+}
+
+// WithFields Returns a logger sharing the same connection that also writes the fields
+func (l *JournaldLogger) WithFields(fields map[string]interface{}) Log {
+	return &JournaldLogger{
+		baseLogger: l.withFields(fields),
+		connection: l.connection,
+	}
+}
+
+// WithCorrelationId Returns a logger sharing the same connection that writes the correlation id
+func (l *JournaldLogger) WithCorrelationId(correlationId string) Log {
+	return &JournaldLogger{
+		baseLogger: l.withCorrelationId(correlationId),
+		connection: l.connection,
+	}
+}
+
+func (l *JournaldLogger) withCaller(caller string, at time.Time) Log {
+	return &JournaldLogger{
+		baseLogger: l.withCallerLocation(caller, at),
+		connection: l.connection,
+	}
+}
+
+// Close Closes the connection to the journal
+func (l *JournaldLogger) Close() error {
+	l.output.mutex.Lock()
+	defer l.output.mutex.Unlock()
+	return l.connection.Close()
+}
+
+// encodeJournaldEntry renders the entry in the journal native format, the journal adds its
+// own timestamp
+func encodeJournaldEntry(entry Entry, identifier string) []byte {
+	var buffer bytes.Buffer
+	writeJournaldField(&buffer, "MESSAGE", entry.Message)
+	writeJournaldField(&buffer, "PRIORITY", string(rune('0'+syslogSeverity(entry))))
+	if identifier != "" {
+		writeJournaldField(&buffer, "SYSLOG_IDENTIFIER", identifier)
+	}
+	if entry.CorrelationId != "" {
+		writeJournaldField(&buffer, "CORRELATION_ID", entry.CorrelationId)
+	}
+	if entry.Caller != "" {
+		if index := strings.LastIndex(entry.Caller, ":"); index > 0 {
+			writeJournaldField(&buffer, "CODE_FILE", entry.Caller[:index])
+			writeJournaldField(&buffer, "CODE_LINE", entry.Caller[index+1:])
+		}
+	}
+	if entry.Error != nil {
+		writeJournaldField(&buffer, "ERROR", entry.Error.Error())
+	}
+
+	for _, key := range sortedFieldKeys(entry.Fields) {
+		name := journaldFieldName(key)
+		if name != "" {
+			writeJournaldField(&buffer, name, fieldText(entry.Fields[key]))
+		}
+	}
+
+	return buffer.Bytes()
+}
+
+// writeJournaldField writes the field as NAME=value or, if the value has new lines, as the
+// name followed by the little endian length of the value and the value itself
+func writeJournaldField(buffer *bytes.Buffer, name string, value string) {
+	buffer.WriteString(name)
+	if !strings.Contains(value, "\n") {
+		buffer.WriteString("=" + value + "\n")
+		return
+	}
+
+	buffer.WriteByte('\n')
+	binary.Write(buffer, binary.LittleEndian, uint64(len(value)))
+	buffer.WriteString(value)
+	buffer.WriteByte('\n')
+}
+
+// journaldFieldName converts the name to upper case letters, digits and underscores, names
+// cannot start with an underscore or a digit and the reserved names are prefixed with FIELD_
+func journaldFieldName(name string) string {
+	result := strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z':
+			return r - 'a' + 'A'
+		case r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '_':
+			return r
+		}
+		return '_'
+	}, name)
+
+	result = strings.TrimLeft(result, "_0123456789")
+	if journaldReservedFields[result] {
+		result = "FIELD_" + result
+	}
+	if len(result) > 64 {
+		result = result[:64]
+	}
+
+	return result
+}
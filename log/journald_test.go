@@ -0,0 +1,65 @@
+package log
+
+import (
+	"bytes"
+	"encoding/binary"
+	"net"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestJournaldLogger_SendsNativeProtocolEntries(t *testing.T) {
+	// Arrange
+	path := filepath.Join(t.TempDir(), "journal.sock")
+	listener, err := net.ListenUnixgram("unixgram", &net.UnixAddr{Name: path, Net: "unixgram"})
+	assert.Nil(t, err)
+	defer listener.Close()
+	logger, err := NewJournaldLogger(JournaldOptions{SocketPath: path, Identifier: "api"})
+	assert.Nil(t, err)
+	defer logger.Close()
+
+	// Act
+	logger.WithFields(map[string]interface{}{"request-id": 42, "_hidden": "x"}).Error("failed")
+
+	// Assert
+	datagram := readDatagram(t, listener)
+	assert.Contains(t, datagram, "MESSAGE=failed\n")
+	assert.Contains(t, datagram, "PRIORITY=3\n")
+	assert.Contains(t, datagram, "SYSLOG_IDENTIFIER=api\n")
+	assert.Contains(t, datagram, "CODE_FILE=log/journald_test.go\n")
+	assert.Contains(t, datagram, "REQUEST_ID=42\n")
+	assert.Contains(t, datagram, "HIDDEN=x\n")
+}
+
+func TestEncodeJournaldEntry_UsesBinaryFormatForMultilineValues(t *testing.T) {
+	// Arrange
+	entry := Entry{Level: Debug, Message: "line one\nline two"}
+
+	// Act
+	encoded := encodeJournaldEntry(entry, "")
+
+	// Assert
+	var expected bytes.Buffer
+	expected.WriteString("MESSAGE\n")
+	binary.Write(&expected, binary.LittleEndian, uint64(17))
+	expected.WriteString("line one\nline two\nPRIORITY=7\n")
+	assert.Equal(t, expected.Bytes(), encoded)
+}
+
+func TestEncodeJournaldEntry_PrefixesReservedFieldNames(t *testing.T) {
+	// Arrange
+	entry := Entry{
+		Level:   Info,
+		Message: "hello",
+		Fields:  map[string]interface{}{"message": "spoofed", "priority": 0, "Syslog-Identifier": "other"},
+	}
+
+	// Act
+	encoded := string(encodeJournaldEntry(entry, "api"))
+
+	// Assert
+	assert.Equal(t, "MESSAGE=hello\nPRIORITY=6\nSYSLOG_IDENTIFIER=api\n"+
+		"FIELD_SYSLOG_IDENTIFIER=other\nFIELD_MESSAGE=spoofed\nFIELD_PRIORITY=0\n", encoded)
+}
@@ -0,0 +1,118 @@
+package log
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"time"
+)
+
+// JsonLogger writes one JSON object per line with the timestamp, level, message,
+// correlation id, caller and the fields of the entry
+type JsonLogger struct {
+	baseLogger
+}
+
+// NewJsonLogger Creates a json logger writing to the writer, os.Stdout is used if it is nil
+func NewJsonLogger(writer io.Writer) *JsonLogger {
+	if writer == nil {
+		writer = os.Stdout
+	}
+
+	return &JsonLogger{
+		baseLogger: newBaseLogger(writer, encodeJsonEntry),
+	}
+}
+
+// WithField Adds a field that is written in every entry, for example the service name
+func (l *JsonLogger) WithField(key string, value interface{}) *JsonLogger {
+	l.setField(key, value)
+	return l
+}
+
+// WithFields Returns a logger sharing the same output that also writes the fields
+func (l *JsonLogger) WithFields(fields map[string]interface{}) Log {
+	return &JsonLogger{
+		baseLogger: l.withFields(fields),
+	}
+}
+
+// WithCorrelationId Returns a logger sharing the same output that writes the correlation id
+func (l *JsonLogger) WithCorrelationId(correlationId string) Log {
+	return &JsonLogger{
+		baseLogger: l.withCorrelationId(correlationId),
+	}
+}
+
+func (l *JsonLogger) withCaller(caller string, at time.Time) Log {
+	return &JsonLogger{
+		baseLogger: l.withCallerLocation(caller, at),
+	}
+}
+
+// encodeJsonEntry renders the entry as a single JSON line, the well known keys are written
+// first followed by the fields ordered by name, a field using a key that is already written
+// is prefixed with fields. so it cannot replace it
+func encodeJsonEntry(entry Entry, useTimestamp bool) []byte {
+	var buffer bytes.Buffer
+	buffer.WriteByte('{')
+
+	first := true
+	written := make(map[string]bool)
+	writeField := func(key string, value interface{}) {
+		written[key] = true
+		encoded, err := json.Marshal(value)
+		if err != nil {
+			encoded, _ = json.Marshal(fmt.Sprint(value))
+		}
+		if !first {
+			buffer.WriteByte(',')
+		}
+		first = false
+		encodedKey, _ := json.Marshal(key)
+		buffer.Write(encodedKey)
+		buffer.WriteByte(':')
+		buffer.Write(encoded)
+	}
+
+	if useTimestamp {
+		writeField("timestamp", entry.Time.Format(time.RFC3339Nano))
+	}
+	writeField("level", entry.Level.String())
+	if entry.Kind != "" {
+		writeField("kind", entry.Kind)
+	}
+	writeField("message", entry.Message)
+	if entry.CorrelationId != "" {
+		writeField("correlation_id", entry.CorrelationId)
+	}
+	if entry.Caller != "" {
+		writeField("caller", entry.Caller)
+	}
+	if entry.Error != nil {
+		writeField("error", entry.Error.Error())
+		if detail := DescribeError(entry.Error); detail.HasDetails() {
+			writeField("error_details", detail)
+		}
+	}
+	if len(entry.Stack) > 0 {
+		writeField("stack", entry.Stack)
+	}
+
+	for _, key := range sortedFieldKeys(entry.Fields) {
+		value := entry.Fields[key]
+		if err, ok := value.(error); ok {
+			value = err.Error()
+		}
+		name := key
+		for written[name] {
+			name = "fields." + name
+		}
+		writeField(name, value)
+	}
+
+	buffer.WriteString("}\n")
+	return buffer.Bytes()
+}
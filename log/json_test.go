@@ -0,0 +1,134 @@
+package log
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func decodeJsonLines(t *testing.T, buffer *bytes.Buffer) []map[string]interface{} {
+	result := make([]map[string]interface{}, 0)
+	for _, line := range strings.Split(strings.TrimSpace(buffer.String()), "\n") {
+		item := make(map[string]interface{})
+		assert.Nil(t, json.Unmarshal([]byte(line), &item), line)
+		result = append(result, item)
+	}
+
+	return result
+}
+
+func TestJsonLogger_WritesOneObjectPerLine(t *testing.T) {
+	// Arrange
+	var buffer bytes.Buffer
+	logger := NewJsonLogger(&buffer).WithField("service", "api")
+	SetDefaultCorrelationId("abc-123")
+	defer SetDefaultCorrelationId("")
+	logger.UseCorrelationId(true)
+
+	// Act
+	logger.Info("hello %s", "world")
+	logger.Success("done")
+	logger.Exception(errors.New("boom"), "failed to %s", "connect")
+
+	// Assert
+	lines := decodeJsonLines(t, &buffer)
+	assert.Len(t, lines, 3)
+	assert.Equal(t, "info", lines[0]["level"])
+	assert.Equal(t, "hello world", lines[0]["message"])
+	assert.Equal(t, "api", lines[0]["service"])
+	assert.Equal(t, "abc-123", lines[0]["correlation_id"])
+	assert.NotEmpty(t, lines[0]["timestamp"])
+	assert.Regexp(t, `^log/json_test\.go:\d+$`, lines[0]["caller"])
+	assert.Equal(t, "success", lines[1]["kind"])
+	assert.Equal(t, "error", lines[2]["level"])
+	assert.Equal(t, "failed to connect", lines[2]["message"])
+	assert.Equal(t, "boom", lines[2]["error"])
+	assert.True(t, strings.HasPrefix(buffer.String(), `{"timestamp":`))
+}
+
+func TestJsonLogger_PrefixesReservedFieldNames(t *testing.T) {
+	// Arrange
+	var buffer bytes.Buffer
+	logger := NewJsonLogger(&buffer).WithFields(map[string]interface{}{
+		"level":          "custom",
+		"message":        "replaced",
+		"error":          "none",
+		"fields.message": "taken",
+		"service":        "api",
+	})
+
+	// Act
+	logger.Exception(errors.New("boom"), "disk at 90%%")
+
+	// Assert
+	lines := decodeJsonLines(t, &buffer)
+	assert.Equal(t, "error", lines[0]["level"])
+	assert.Equal(t, "disk at 90%", lines[0]["message"])
+	assert.Equal(t, "boom", lines[0]["error"])
+	assert.Equal(t, "custom", lines[0]["fields.level"])
+	assert.Equal(t, "none", lines[0]["fields.error"])
+	assert.Equal(t, "taken", lines[0]["fields.message"])
+	assert.Equal(t, "replaced", lines[0]["fields.fields.message"])
+	assert.Equal(t, "api", lines[0]["service"])
+	assert.Equal(t, 1, strings.Count(buffer.String(), `"level":`))
+}
+
+func TestJsonLogger_RendersTheFormatLikeTheConsole(t *testing.T) {
+	// Arrange
+	var buffer bytes.Buffer
+	logger := &Logger{LogLevel: Info}
+	logger.AddJsonLogger(&buffer)
+
+	// Act
+	logger.Info("100%%")
+	logger.LogError(errors.New("disk 100% full"))
+	logger.Exception(errors.New("50% done"), "")
+
+	// Assert
+	lines := decodeJsonLines(t, &buffer)
+	assert.Equal(t, "100%", lines[0]["message"])
+	assert.Equal(t, "disk 100% full", lines[1]["message"])
+	assert.Equal(t, "50% done", lines[2]["message"])
+}
+
+func TestJsonLogger_SelectedThroughLoggers(t *testing.T) {
+	// Arrange
+	var buffer bytes.Buffer
+	logger := &Logger{LogLevel: Debug}
+	logger.AddJsonLogger(&buffer)
+
+	// Act
+	logger.Debug("debug message")
+	logger.Trace("filtered")
+	logger.LogHighlight("highlighted %s", Warning, "word")
+
+	// Assert
+	lines := decodeJsonLines(t, &buffer)
+	assert.Len(t, lines, 2)
+	assert.Equal(t, "debug", lines[0]["level"])
+	assert.Regexp(t, `^log/json_test\.go:\d+$`, lines[0]["caller"])
+	assert.Equal(t, "warn", lines[1]["level"])
+	assert.Equal(t, "highlighted word", lines[1]["message"])
+}
+
+func TestJsonLogger_FatalOnlyWrites(t *testing.T) {
+	// Arrange
+	var buffer bytes.Buffer
+	exitCode := -1
+	exitProcess = func(code int) { exitCode = code }
+	defer func() { exitProcess = os.Exit }()
+	logger := NewJsonLogger(&buffer)
+	logger.UseTimestamp(false)
+
+	// Act
+	logger.Fatal("fatal %s", "error")
+
+	// Assert
+	assert.Equal(t, -1, exitCode)
+	assert.True(t, strings.HasPrefix(buffer.String(), `{"level":"error","message":"fatal error"`))
+}
@@ -0,0 +1,115 @@
+package log
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// ParseLevel Parses a level name such as info, warn or trace, or its numeric value
+func ParseLevel(value string) (Level, error) {
+	switch strings.ToLower(strings.TrimSpace(value)) {
+	case "error":
+		return Error, nil
+	case "warn", "warning":
+		return Warning, nil
+	case "info":
+		return Info, nil
+	case "debug":
+		return Debug, nil
+	case "trace":
+		return Trace, nil
+	}
+
+	number, err := strconv.Atoi(strings.TrimSpace(value))
+	if err != nil || number < int(Error) || number > int(Trace) {
+		return Error, fmt.Errorf("invalid log level %q", value)
+	}
+
+	return Level(number), nil
+}
+
+// Level Returns the level of the logger, it is used by the sinks without their own level
+func (l *Logger) Level() Level {
+	root := l.rootLogger()
+	root.levelMutex.RLock()
+	defer root.levelMutex.RUnlock()
+	return root.LogLevel
+}
+
+// SetLevel Changes the level of the logger, it is safe to call while logging
+func (l *Logger) SetLevel(level Level) *Logger {
+	root := l.rootLogger()
+	root.levelMutex.Lock()
+	defer root.levelMutex.Unlock()
+	root.LogLevel = level
+	return l
+}
+
+// SetSinkLevel Sets the minimum level of a sink, for example the console at Info and a file
+// at Trace. It is safe to call while logging
+func (l *Logger) SetSinkLevel(sink Log, level Level) *Logger {
+	root := l.rootLogger()
+	root.levelMutex.Lock()
+	defer root.levelMutex.Unlock()
+	if root.sinkLevels == nil {
+		root.sinkLevels = make(map[Log]Level)
+	}
+	root.sinkLevels[sink] = level
+	return l
+}
+
+// ClearSinkLevel Removes the level of a sink so it uses the level of the logger again
+func (l *Logger) ClearSinkLevel(sink Log) *Logger {
+	root := l.rootLogger()
+	root.levelMutex.Lock()
+	defer root.levelMutex.Unlock()
+	delete(root.sinkLevels, sink)
+	return l
+}
+
+// SinkLevel Returns the effective level of a sink
+func (l *Logger) SinkLevel(sink Log) Level {
+	root := l.rootLogger()
+	root.levelMutex.RLock()
+	defer root.levelMutex.RUnlock()
+	return root.sinkLevel(sink)
+}
+
+// IsEnabled Returns true if any sink writes messages of the level
+func (l *Logger) IsEnabled(level Level) bool {
+	root := l.rootLogger()
+	root.levelMutex.RLock()
+	defer root.levelMutex.RUnlock()
+	for _, sink := range root.Loggers {
+		if root.sinkLevel(sink) >= level {
+			return true
+		}
+	}
+
+	return false
+}
+
+// enabledSinks returns the sinks of the root logger that write messages of the level
+func (l *Logger) enabledSinks(level Level) []Log {
+	root := l.rootLogger()
+	root.levelMutex.RLock()
+	defer root.levelMutex.RUnlock()
+
+	result := make([]Log, 0, len(root.Loggers))
+	for _, sink := range root.Loggers {
+		if root.sinkLevel(sink) >= level {
+			result = append(result, sink)
+		}
+	}
+
+	return result
+}
+
+func (l *Logger) sinkLevel(sink Log) Level {
+	if level, ok := l.sinkLevels[sink]; ok {
+		return level
+	}
+
+	return l.LogLevel
+}
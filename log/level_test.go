@@ -0,0 +1,112 @@
+package log
+
+import (
+	"bytes"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseLevel(t *testing.T) {
+	// Arrange + Act
+	warning, warningErr := ParseLevel(" WARNING ")
+	trace, traceErr := ParseLevel("4")
+	_, invalidErr := ParseLevel("verbose")
+	_, outOfRangeErr := ParseLevel("9")
+
+	// Assert
+	assert.Nil(t, warningErr)
+	assert.Equal(t, Warning, warning)
+	assert.Nil(t, traceErr)
+	assert.Equal(t, Trace, trace)
+	assert.EqualError(t, invalidErr, `invalid log level "verbose"`)
+	assert.NotNil(t, outOfRangeErr)
+}
+
+func TestLogger_SinkLevelsFilterEachSink(t *testing.T) {
+	// Arrange
+	var console, file bytes.Buffer
+	logger := &Logger{LogLevel: Info}
+	consoleLogger := logger.AddCmdLoggerWithOptions(CmdLoggerOptions{Writer: &console, DisableColors: true})
+	fileLogger := logger.AddJsonLogger(&file)
+	logger.SetSinkLevel(fileLogger, Trace)
+
+	// Act
+	logger.Info("info")
+	logger.Debug("debug")
+	logger.Trace("trace")
+
+	// Assert
+	assert.Equal(t, "info\n", console.String())
+	lines := decodeJsonLines(t, &file)
+	assert.Len(t, lines, 3)
+	assert.Equal(t, "trace", lines[2]["level"])
+	assert.Equal(t, Info, logger.SinkLevel(consoleLogger))
+	assert.Equal(t, Trace, logger.SinkLevel(fileLogger))
+	assert.True(t, logger.IsEnabled(Trace))
+}
+
+func TestLogger_LogRespectsTheLevel(t *testing.T) {
+	// Arrange
+	var buffer bytes.Buffer
+	logger := &Logger{LogLevel: Warning}
+	logger.AddJsonLogger(&buffer)
+
+	// Act
+	logger.Log("hidden", Info)
+	logger.LogHighlight("shown %s", Error, "value")
+
+	// Assert
+	lines := decodeJsonLines(t, &buffer)
+	assert.Len(t, lines, 1)
+	assert.Equal(t, "shown value", lines[0]["message"])
+}
+
+func TestLogger_ChildLoggersUseTheLevelOfTheRoot(t *testing.T) {
+	// Arrange
+	var buffer bytes.Buffer
+	logger := &Logger{LogLevel: Info}
+	jsonLogger := logger.AddJsonLogger(&buffer)
+	child := logger.With("user", "bob")
+
+	// Act
+	child.Debug("hidden")
+	logger.SetLevel(Debug)
+	child.Debug("shown")
+	logger.SetSinkLevel(jsonLogger, Error)
+	child.Debug("hidden")
+	logger.ClearSinkLevel(jsonLogger)
+	child.Debug("shown again")
+
+	// Assert
+	lines := decodeJsonLines(t, &buffer)
+	assert.Len(t, lines, 2)
+	assert.Equal(t, "shown again", lines[1]["message"])
+}
+
+func TestLogger_SetLevelWhileLogging(t *testing.T) {
+	// Arrange
+	var buffer bytes.Buffer
+	logger := &Logger{LogLevel: Info}
+	jsonLogger := logger.AddJsonLogger(&buffer)
+	var wg sync.WaitGroup
+
+	// Act
+	for i := 0; i < 10; i++ {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			logger.Debug("message")
+		}()
+		go func(level Level) {
+			defer wg.Done()
+			logger.SetLevel(level)
+			logger.SetSinkLevel(jsonLogger, level)
+		}(Level(i % 5))
+	}
+	wg.Wait()
+
+	// Assert
+	assert.LessOrEqual(t, len(decodeJsonLines(t, &buffer)), 10)
+}
@@ -0,0 +1,80 @@
+// Package logtest provides a capture logger and testify assertions for the log entries
+package logtest
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/cjlapao/common-go/log"
+	"github.com/stretchr/testify/assert"
+)
+
+// New Creates a logger at trace level that only writes to the returned capture logger, Fatal
+// and completed tasks are recorded without ending the test process
+func New() (*log.Logger, *log.CaptureLogger) {
+	capture := log.NewCaptureLogger()
+	logger := &log.Logger{
+		Loggers:  []log.Log{capture},
+		LogLevel: log.Trace,
+	}
+	logger.SetExitHandler(func(code int) {})
+
+	return logger, capture
+}
+
+// AssertLogged Asserts that an entry of the level with a message containing the text was logged
+func AssertLogged(t assert.TestingT, capture *log.CaptureLogger, level log.Level, text string, msgAndArgs ...interface{}) bool {
+	if len(capture.Find(level, text)) > 0 {
+		return true
+	}
+
+	return assert.Fail(t, fmt.Sprintf("No %s entry contains %q, logged entries:\n%s", level, text, describe(capture)), msgAndArgs...)
+}
+
+// AssertNotLogged Asserts that no entry of the level with a message containing the text was logged
+func AssertNotLogged(t assert.TestingT, capture *log.CaptureLogger, level log.Level, text string, msgAndArgs ...interface{}) bool {
+	entries := capture.Find(level, text)
+	if len(entries) == 0 {
+		return true
+	}
+
+	return assert.Fail(t, fmt.Sprintf("Unexpected %s entry %q", level, entries[0].Message), msgAndArgs...)
+}
+
+// AssertLoggedWithField Asserts that an entry with a message containing the text has the field
+func AssertLoggedWithField(t assert.TestingT, capture *log.CaptureLogger, text string, key string, value interface{}, msgAndArgs ...interface{}) bool {
+	for _, entry := range capture.Entries() {
+		if !strings.Contains(entry.Message, text) {
+			continue
+		}
+		if fieldValue, ok := entry.Fields[key]; ok && assert.ObjectsAreEqual(value, fieldValue) {
+			return true
+		}
+	}
+
+	return assert.Fail(t, fmt.Sprintf("No entry containing %q has the field %s=%v, logged entries:\n%s", text, key, value, describe(capture)), msgAndArgs...)
+}
+
+// AssertCount Asserts the number of entries of the level
+func AssertCount(t assert.TestingT, capture *log.CaptureLogger, level log.Level, count int, msgAndArgs ...interface{}) bool {
+	entries := capture.EntriesWithLevel(level)
+	if len(entries) == count {
+		return true
+	}
+
+	return assert.Fail(t, fmt.Sprintf("Expected %d %s entries but found %d, logged entries:\n%s", count, level, len(entries), describe(capture)), msgAndArgs...)
+}
+
+func describe(capture *log.CaptureLogger) string {
+	entries := capture.Entries()
+	if len(entries) == 0 {
+		return "\t<none>"
+	}
+
+	lines := make([]string, len(entries))
+	for i, entry := range entries {
+		lines[i] = fmt.Sprintf("\t[%s] %s", entry.Level, entry.Message)
+	}
+
+	return strings.Join(lines, "\n")
+}
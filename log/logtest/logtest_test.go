@@ -0,0 +1,48 @@
+package logtest
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/cjlapao/common-go/log"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAssertions(t *testing.T) {
+	// Arrange
+	logger, capture := New()
+
+	// Act
+	logger.With("attempt", 2).Warn("retrying %s", "request")
+	logger.Debug("details")
+
+	// Assert
+	AssertLogged(t, capture, log.Warning, "retrying request")
+	AssertNotLogged(t, capture, log.Error, "retrying")
+	AssertLoggedWithField(t, capture, "retrying", "attempt", 2)
+	AssertCount(t, capture, log.Debug, 1)
+}
+
+func TestAssertionsReportFailures(t *testing.T) {
+	// Arrange
+	_, capture := New()
+	recorder := &failureRecorder{}
+
+	// Act
+	logged := AssertLogged(recorder, capture, log.Info, "missing")
+	counted := AssertCount(recorder, capture, log.Info, 1)
+
+	// Assert
+	assert.False(t, logged)
+	assert.False(t, counted)
+	assert.Len(t, recorder.failures, 2)
+	assert.Contains(t, recorder.failures[0], `No info entry contains "missing"`)
+}
+
+type failureRecorder struct {
+	failures []string
+}
+
+func (r *failureRecorder) Errorf(format string, args ...interface{}) {
+	r.failures = append(r.failures, fmt.Sprintf(format, args...))
+}
@@ -2,7 +2,10 @@ package log
 
 import (
 	"fmt"
+	"io"
 	"os"
+	"strings"
+	"sync"
 
 	"github.com/cjlapao/common-go/constants"
 	strcolor "github.com/cjlapao/common-go/strcolor"
@@ -38,6 +41,15 @@ type Logger struct {
 	LogLevel       Level
 	HighlightColor strcolor.ColorCode
 	UseTimestamp   bool
+
+	parent        *Logger
+	fields        map[string]interface{}
+	correlationId string
+	levelMutex    sync.RWMutex
+	sinkLevels    map[Log]Level
+	hookMutex     sync.RWMutex // guards the hooks and the exit handler
+	hooks         []Hook
+	exitHandler   func(code int)
 }
 
 var globalLogger *Logger
@@ -54,6 +66,26 @@ const (
 	Trace
 )
 
+func (l Level) String() string {
+	switch l {
+	case Error:
+		return "error"
+	case Warning:
+		return "warn"
+	case Info:
+		return "info"
+	case Debug:
+		return "debug"
+	case Trace:
+		return "trace"
+	}
+
+	return fmt.Sprintf("level(%d)", int(l))
+}
+
+// exitProcess ends the process after a fatal message or a completed task, it is replaced in tests
+var exitProcess = os.Exit
+
 // LogOptions Definition
 type LoggerOptions int64
 
@@ -91,65 +123,135 @@ func Get() *Logger {
 
 // AddCmdLogger Add a command line logger to the system
 func (l *Logger) AddCmdLogger() {
-	found := false
-	for _, logger := range l.Loggers {
-		xType := fmt.Sprintf("%T", logger)
-		if xType == "CmdLogger" {
-			found = true
-			logger.UseTimestamp(false)
-			break
+	l.addCmdLogger(false)
+}
+
+func (l *Logger) AddCmdLoggerWithTimestamp() {
+	l.addCmdLogger(true)
+}
+
+// AddCmdLoggerWithOptions Add a command line logger with its own options, it is added even
+// if the logger already has one so for example a second logger can write to a buffer
+func (l *Logger) AddCmdLoggerWithOptions(options CmdLoggerOptions) *CmdLogger {
+	root := l.rootLogger()
+	logger := NewCmdLogger(options)
+	root.Loggers = append(root.Loggers, logger)
+	return logger
+}
+
+func (l *Logger) addCmdLogger(useTimestamp bool) {
+	root := l.rootLogger()
+	for _, logger := range root.Loggers {
+		if cmdLogger, ok := logger.(*CmdLogger); ok {
+			cmdLogger.UseTimestamp(useTimestamp)
+			return
 		}
 	}
 
-	if !found {
-		logger := new(CmdLogger)
-		logger.UseTimestamp(false)
-		l.Loggers = append(l.Loggers, logger)
+	logger := new(CmdLogger)
+	logger.UseTimestamp(useTimestamp)
+	root.Loggers = append(root.Loggers, logger)
+}
+
+// AddJsonLogger Add a json logger writing to the writer, os.Stdout is used if it is nil.
+// To use it instead of the command line logger set Loggers to contain only the json logger
+func (l *Logger) AddJsonLogger(writer io.Writer) *JsonLogger {
+	root := l.rootLogger()
+	logger := NewJsonLogger(writer)
+	root.Loggers = append(root.Loggers, logger)
+	return logger
+}
+
+// AddFileLogger Add a file logger with the rotation options
+func (l *Logger) AddFileLogger(options FileLoggerOptions) (*FileLogger, error) {
+	logger, err := NewFileLogger(options)
+	if err != nil {
+		return nil, err
 	}
+
+	root := l.rootLogger()
+	root.Loggers = append(root.Loggers, logger)
+	return logger, nil
 }
 
-func (l *Logger) AddCmdLoggerWithTimestamp() {
-	found := false
-	for _, logger := range l.Loggers {
-		xType := fmt.Sprintf("%T", logger)
-		if xType == "CmdLogger" {
-			found = true
-			logger.UseTimestamp(true)
-			break
-		}
+// AddSyslogLogger Add a syslog logger sending RFC5424 messages to the server
+func (l *Logger) AddSyslogLogger(options SyslogOptions) (*SyslogLogger, error) {
+	logger, err := NewSyslogLogger(options)
+	if err != nil {
+		return nil, err
 	}
 
-	if !found {
-		logger := new(CmdLogger)
-		logger.UseTimestamp(true)
-		l.Loggers = append(l.Loggers, logger)
+	root := l.rootLogger()
+	root.Loggers = append(root.Loggers, logger)
+	return logger, nil
+}
+
+// AddJournaldLogger Add a logger sending the entries to the systemd journal
+func (l *Logger) AddJournaldLogger(options JournaldOptions) (*JournaldLogger, error) {
+	logger, err := NewJournaldLogger(options)
+	if err != nil {
+		return nil, err
+	}
+
+	root := l.rootLogger()
+	root.Loggers = append(root.Loggers, logger)
+	return logger, nil
+}
+
+// AddAsyncLogger Add a sink that is written from a background goroutine
+func (l *Logger) AddAsyncLogger(sink Log, options AsyncOptions) *AsyncLogger {
+	logger := NewAsyncLogger(sink, options)
+	root := l.rootLogger()
+	root.Loggers = append(root.Loggers, logger)
+	return logger
+}
+
+// AddSampledLogger Add a sink that limits the repeated messages it writes
+func (l *Logger) AddSampledLogger(sink Log, options SamplingOptions) *SampledLogger {
+	logger := NewSampledLogger(sink, options)
+	root := l.rootLogger()
+	root.Loggers = append(root.Loggers, logger)
+	return logger
+}
+
+// AddCaptureLogger Add a sink that keeps the entries in memory, it is meant for tests
+func (l *Logger) AddCaptureLogger() *CaptureLogger {
+	logger := NewCaptureLogger()
+	root := l.rootLogger()
+	root.Loggers = append(root.Loggers, logger)
+	return logger
+}
+
+// Flush Waits until the sinks that buffer messages, like the asynchronous ones, write them
+func (l *Logger) Flush() {
+	for _, logger := range l.rootLogger().Loggers {
+		if flusher, ok := logger.(interface{ Flush() }); ok {
+			flusher.Flush()
+		}
 	}
 }
 
 func (l *Logger) WithDebug() *Logger {
-	l.LogLevel = Debug
-	return l
+	return l.SetLevel(Debug)
 }
 
 func (l *Logger) WithTrace() *Logger {
-	l.LogLevel = Trace
-	return l
+	return l.SetLevel(Trace)
 }
 
 func (l *Logger) WithWarning() *Logger {
-	l.LogLevel = Warning
-	return l
+	return l.SetLevel(Warning)
 }
 
 func (l *Logger) WithTimestamp() *Logger {
-	for _, logger := range l.Loggers {
+	for _, logger := range l.rootLogger().Loggers {
 		logger.UseTimestamp(true)
 	}
 	return l
 }
 
 func (l *Logger) WithCorrelationId() *Logger {
-	for _, logger := range l.Loggers {
+	for _, logger := range l.rootLogger().Loggers {
 		logger.UseCorrelationId(true)
 	}
 	return l
@@ -157,162 +259,230 @@ func (l *Logger) WithCorrelationId() *Logger {
 
 // Log Log information message
 func (l *Logger) Log(format string, level Level, words ...string) {
-	for _, logger := range l.Loggers {
+	for _, logger := range l.dispatch(level, "", nil, format, words) {
 		logger.Log(format, level, words...)
 	}
 }
 
 // LogHighlight Log information message
 func (l *Logger) LogHighlight(format string, level Level, words ...string) {
-	for _, logger := range l.Loggers {
+	for _, logger := range l.dispatch(level, "", nil, format, words) {
 		logger.LogHighlight(format, level, l.HighlightColor, words...)
 	}
 }
 
 // Info log information message
 func (l *Logger) Info(format string, words ...string) {
-	if l.LogLevel >= Info {
-		for _, logger := range l.Loggers {
-			logger.Info(format, words...)
-		}
+	for _, logger := range l.dispatch(Info, "", nil, format, words) {
+		logger.Info(format, words...)
 	}
 }
 
 // Success log message
 func (l *Logger) Success(format string, words ...string) {
-	if l.LogLevel >= Info {
-		for _, logger := range l.Loggers {
-			logger.Success(format, words...)
-		}
+	for _, logger := range l.dispatch(Info, KindSuccess, nil, format, words) {
+		logger.Success(format, words...)
 	}
 }
 
-// TaskSuccess log message
+// TaskSuccess log message, a completed task ends the process once every sink wrote it
 func (l *Logger) TaskSuccess(format string, isComplete bool, words ...string) {
-	if l.LogLevel >= Info {
-		for _, logger := range l.Loggers {
-			logger.TaskSuccess(format, isComplete, words...)
-		}
+	for _, logger := range l.dispatch(Info, KindSuccess, nil, format, words) {
+		sinkTaskSuccess(logger, format, isComplete, words...)
+	}
+	if isComplete {
+		l.exit(0)
 	}
 }
 
 // Warn log message
 func (l *Logger) Warn(format string, words ...string) {
-	if l.LogLevel >= Warning {
-		for _, logger := range l.Loggers {
-			logger.Warn(format, words...)
-		}
+	for _, logger := range l.dispatch(Warning, "", nil, format, words) {
+		logger.Warn(format, words...)
 	}
 }
 
 // TaskWarn log message
 func (l *Logger) TaskWarn(format string, words ...string) {
-	if l.LogLevel >= Warning {
-		for _, logger := range l.Loggers {
-			logger.TaskWarn(format, words...)
-		}
+	for _, logger := range l.dispatch(Warning, "", nil, format, words) {
+		logger.TaskWarn(format, words...)
 	}
 }
 
 // Command log message
 func (l *Logger) Command(format string, words ...string) {
-	if l.LogLevel >= Info {
-		for _, logger := range l.Loggers {
-			logger.Command(format, words...)
-		}
+	for _, logger := range l.dispatch(Info, KindCommand, nil, format, words) {
+		logger.Command(format, words...)
 	}
 }
 
 // Disabled log message
 func (l *Logger) Disabled(format string, words ...string) {
-	if l.LogLevel >= Info {
-		for _, logger := range l.Loggers {
-			logger.Disabled(format, words...)
-		}
+	for _, logger := range l.dispatch(Info, KindDisabled, nil, format, words) {
+		logger.Disabled(format, words...)
 	}
 }
 
 // Notice log message
 func (l *Logger) Notice(format string, words ...string) {
-	if l.LogLevel >= Info {
-		for _, logger := range l.Loggers {
-			logger.Notice(format, words...)
-		}
+	for _, logger := range l.dispatch(Info, KindNotice, nil, format, words) {
+		logger.Notice(format, words...)
 	}
 }
 
 // Debug log message
 func (l *Logger) Debug(format string, words ...string) {
-	if l.LogLevel >= Debug {
-		for _, logger := range l.Loggers {
-			logger.Debug(format, words...)
-		}
+	for _, logger := range l.dispatch(Debug, "", nil, format, words) {
+		logger.Debug(format, words...)
 	}
 }
 
 // Trace log message
 func (l *Logger) Trace(format string, words ...string) {
-	if l.LogLevel >= Trace {
-		for _, logger := range l.Loggers {
-			logger.Debug(format, words...)
-		}
+	for _, logger := range l.dispatch(Trace, "", nil, format, words) {
+		logger.Trace(format, words...)
 	}
 }
 
 // Error log message
 func (l *Logger) Error(format string, words ...string) {
-	if l.LogLevel >= Error {
-		for _, logger := range l.Loggers {
-			logger.Error(format, words...)
-		}
+	for _, logger := range l.dispatch(Error, "", nil, format, words) {
+		logger.Error(format, words...)
 	}
 }
 
 // LogError log message
 func (l *Logger) LogError(message error) {
-	if l.LogLevel >= Error {
-		if message != nil {
-			for _, logger := range l.Loggers {
-				logger.Error(message.Error())
-			}
+	if message != nil {
+		for _, logger := range l.dispatch(Error, "", message, exceptionFormat(message, ""), nil) {
+			logger.LogError(message)
 		}
 	}
 }
 
 // Exception log message
 func (l *Logger) Exception(err error, format string, words ...string) {
-	if l.LogLevel >= Error {
-		for _, logger := range l.Loggers {
-			logger.Exception(err, format, words...)
-		}
+	for _, logger := range l.dispatch(Error, "", err, exceptionFormat(err, format), words) {
+		logger.Exception(err, format, words...)
 	}
 }
 
-// TaskError log message
+// TaskError log message, a completed task ends the process once every sink wrote it
 func (l *Logger) TaskError(format string, isComplete bool, words ...string) {
-	if l.LogLevel >= Error {
-		for _, logger := range l.Loggers {
-			logger.TaskError(format, isComplete, words...)
-		}
+	for _, logger := range l.dispatch(Error, "", nil, format, words) {
+		sinkTaskError(logger, format, isComplete, words...)
+	}
+	if isComplete {
+		l.exit(taskErrorExitCode())
 	}
 }
 
-// Fatal log message
+// Fatal log message, the process ends once every sink wrote it
 func (l *Logger) Fatal(format string, words ...string) {
-	if l.LogLevel >= Error {
-		for _, logger := range l.Loggers {
-			logger.Fatal(format, words...)
-		}
+	for _, logger := range l.dispatchWithStack(Error, "", nil, callerStack(), format, words) {
+		sinkFatal(logger, format, words...)
 	}
+	l.exit(1)
 }
 
-// FatalError log message
+// FatalError log message, it panics with the error once every sink wrote it
 func (l *Logger) FatalError(e error, format string, words ...string) {
-	for _, logger := range l.Loggers {
-		logger.Error(format, words...)
+	for _, logger := range l.dispatchWithStack(Error, "", e, callerStack(), exceptionFormat(e, format), words) {
+		sinkFatalError(logger, e, format, words...)
 	}
+	l.Flush()
 
 	if e != nil {
 		panic(e)
 	}
 }
+
+// SetExitHandler Replaces the function called to end the process after a fatal message or a
+// completed task, nil restores os.Exit
+func (l *Logger) SetExitHandler(handler func(code int)) *Logger {
+	root := l.rootLogger()
+	root.hookMutex.Lock()
+	defer root.hookMutex.Unlock()
+	root.exitHandler = handler
+	return l
+}
+
+// exit flushes the sinks and ends the process
+func (l *Logger) exit(code int) {
+	l.Flush()
+
+	root := l.rootLogger()
+	root.hookMutex.RLock()
+	handler := root.exitHandler
+	root.hookMutex.RUnlock()
+	if handler == nil {
+		handler = exitProcess
+	}
+
+	handler(code)
+}
+
+// terminalLogger is implemented by the sinks that end the process when they are used directly
+// and by the sinks wrapping them, the Logger writes through these methods so the process only
+// ends once every sink wrote the message
+type terminalLogger interface {
+	writeTaskSuccess(format string, isComplete bool, words ...string)
+	writeTaskError(format string, isComplete bool, words ...string)
+	writeFatal(format string, words ...string)
+	writeFatalError(e error, format string, words ...string)
+}
+
+// sinkTaskSuccess writes the completed task to the sink without ending the process
+func sinkTaskSuccess(sink Log, format string, isComplete bool, words ...string) {
+	if logger, ok := sink.(terminalLogger); ok {
+		logger.writeTaskSuccess(format, isComplete, words...)
+		return
+	}
+	sink.TaskSuccess(format, isComplete, words...)
+}
+
+// sinkTaskError writes the failed task to the sink without ending the process
+func sinkTaskError(sink Log, format string, isComplete bool, words ...string) {
+	if logger, ok := sink.(terminalLogger); ok {
+		logger.writeTaskError(format, isComplete, words...)
+		return
+	}
+	sink.TaskError(format, isComplete, words...)
+}
+
+// sinkFatal writes the fatal message to the sink without ending the process
+func sinkFatal(sink Log, format string, words ...string) {
+	if logger, ok := sink.(terminalLogger); ok {
+		logger.writeFatal(format, words...)
+		return
+	}
+	sink.Fatal(format, words...)
+}
+
+// sinkFatalError writes the fatal error to the sink without panicking
+func sinkFatalError(sink Log, e error, format string, words ...string) {
+	if logger, ok := sink.(terminalLogger); ok {
+		logger.writeFatalError(e, format, words...)
+		return
+	}
+	sink.FatalError(e, format, words...)
+}
+
+// taskErrorExitCode returns the exit code of a failed task, the pipeline agents get the
+// failure from the task result so the exit code is 0 for them
+func taskErrorExitCode() int {
+	if isPipelineAgent() {
+		return 0
+	}
+
+	return 1
+}
+
+// exceptionFormat returns the message of the error escaped as a format when the format is empty
+func exceptionFormat(err error, format string) string {
+	if format == "" && err != nil {
+		return strings.ReplaceAll(err.Error(), "%", "%%")
+	}
+
+	return format
+}
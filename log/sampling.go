@@ -0,0 +1,360 @@
+package log
+
+import (
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	strcolor "github.com/cjlapao/common-go/strcolor"
+)
+
+const defaultSamplingInterval = time.Second
+
+// SamplingRule writes the first messages of a key in every interval and then one in every
+// Thereafter messages, a zero Thereafter suppresses the rest of the interval
+type SamplingRule struct {
+	First      int
+	Thereafter int
+}
+
+// SamplingOptions configures the sampled logger, the messages are grouped by level and format
+// and the levels without a rule are not sampled. At the end of every interval a summary with
+// the number of suppressed messages is written for each key
+type SamplingOptions struct {
+	Interval time.Duration
+	Rules    map[Level]SamplingRule
+}
+
+// SamplingStats are the counters of the sampled logger
+type SamplingStats struct {
+	Written    uint64
+	Suppressed uint64
+}
+
+type samplingKey struct {
+	level  Level
+	format string
+}
+
+type samplingCounter struct {
+	count      int
+	suppressed int
+}
+
+// samplingState is shared by a sampled logger and the loggers created from it
+type samplingState struct {
+	mutex      sync.Mutex
+	options    SamplingOptions
+	counters   map[samplingKey]*samplingCounter
+	written    uint64
+	suppressed uint64
+	stop       chan struct{}
+	stopped    chan struct{}
+	closeOnce  sync.Once
+}
+
+// SampledLogger limits the messages written to the sink so hot paths like retry loops do not
+// flood it. Fatal and completed task messages are always written
+type SampledLogger struct {
+	sink          Log
+	state         *samplingState
+	fields        map[string]interface{}
+	correlationId string
+}
+
+// NewSampledLogger Creates a sampled logger writing to the sink, Close stops the summaries
+func NewSampledLogger(sink Log, options SamplingOptions) *SampledLogger {
+	if options.Interval <= 0 {
+		options.Interval = defaultSamplingInterval
+	}
+
+	rules := make(map[Level]SamplingRule, len(options.Rules))
+	for level, rule := range options.Rules {
+		rules[level] = rule
+	}
+	options.Rules = rules
+
+	logger := &SampledLogger{
+		sink: sink,
+		state: &samplingState{
+			options:  options,
+			counters: make(map[samplingKey]*samplingCounter),
+			stop:     make(chan struct{}),
+			stopped:  make(chan struct{}),
+		},
+	}
+	go logger.run()
+
+	return logger
+}
+
+// Stats Returns the counters of the logger
+func (l *SampledLogger) Stats() SamplingStats {
+	l.state.mutex.Lock()
+	defer l.state.mutex.Unlock()
+
+	return SamplingStats{Written: l.state.written, Suppressed: l.state.suppressed}
+}
+
+// Close Stops the summaries after writing the summary of the current interval
+func (l *SampledLogger) Close() error {
+	l.state.closeOnce.Do(func() {
+		close(l.state.stop)
+		<-l.state.stopped
+		l.summarize()
+	})
+
+	return nil
+}
+
+// WithFields Returns a logger sharing the same counters that also writes the fields
+func (l *SampledLogger) WithFields(fields map[string]interface{}) Log {
+	return &SampledLogger{
+		sink:          l.sink,
+		state:         l.state,
+		fields:        mergeFields(l.fields, fields),
+		correlationId: l.correlationId,
+	}
+}
+
+// WithCorrelationId Returns a logger sharing the same counters that writes the correlation id
+func (l *SampledLogger) WithCorrelationId(correlationId string) Log {
+	return &SampledLogger{
+		sink:          l.sink,
+		state:         l.state,
+		fields:        l.fields,
+		correlationId: correlationId,
+	}
+}
+
+func (l *SampledLogger) UseTimestamp(value bool) {
+	l.sink.UseTimestamp(value)
+}
+
+func (l *SampledLogger) UseCorrelationId(value bool) {
+	l.sink.UseCorrelationId(value)
+}
+
+// Log Log information message
+func (l *SampledLogger) Log(format string, level Level, words ...string) {
+	if l.allow(level, format) {
+		l.target().Log(format, level, words...)
+	}
+}
+
+// LogHighlight Log information message
+func (l *SampledLogger) LogHighlight(format string, level Level, highlightColor strcolor.ColorCode, words ...string) {
+	if l.allow(level, format) {
+		l.target().LogHighlight(format, level, highlightColor, words...)
+	}
+}
+
+// Info log information message
+func (l *SampledLogger) Info(format string, words ...string) {
+	if l.allow(Info, format) {
+		l.target().Info(format, words...)
+	}
+}
+
+// Success log message
+func (l *SampledLogger) Success(format string, words ...string) {
+	if l.allow(Info, format) {
+		l.target().Success(format, words...)
+	}
+}
+
+// TaskSuccess log message, completed tasks are not sampled
+func (l *SampledLogger) TaskSuccess(format string, isComplete bool, words ...string) {
+	if isComplete || l.allow(Info, format) {
+		l.target().TaskSuccess(format, isComplete, words...)
+	}
+}
+
+// Warn log message
+func (l *SampledLogger) Warn(format string, words ...string) {
+	if l.allow(Warning, format) {
+		l.target().Warn(format, words...)
+	}
+}
+
+// TaskWarn log message
+func (l *SampledLogger) TaskWarn(format string, words ...string) {
+	if l.allow(Warning, format) {
+		l.target().TaskWarn(format, words...)
+	}
+}
+
+// Command log message
+func (l *SampledLogger) Command(format string, words ...string) {
+	if l.allow(Info, format) {
+		l.target().Command(format, words...)
+	}
+}
+
+// Disabled log message
+func (l *SampledLogger) Disabled(format string, words ...string) {
+	if l.allow(Info, format) {
+		l.target().Disabled(format, words...)
+	}
+}
+
+// Notice log message
+func (l *SampledLogger) Notice(format string, words ...string) {
+	if l.allow(Info, format) {
+		l.target().Notice(format, words...)
+	}
+}
+
+// Debug log message
+func (l *SampledLogger) Debug(format string, words ...string) {
+	if l.allow(Debug, format) {
+		l.target().Debug(format, words...)
+	}
+}
+
+// Trace log message
+func (l *SampledLogger) Trace(format string, words ...string) {
+	if l.allow(Trace, format) {
+		l.target().Trace(format, words...)
+	}
+}
+
+// Error log message
+func (l *SampledLogger) Error(format string, words ...string) {
+	if l.allow(Error, format) {
+		l.target().Error(format, words...)
+	}
+}
+
+// Exception log message
+func (l *SampledLogger) Exception(err error, format string, words ...string) {
+	key := format
+	if key == "" && err != nil {
+		key = err.Error()
+	}
+	if l.allow(Error, key) {
+		l.target().Exception(err, format, words...)
+	}
+}
+
+// LogError log message
+func (l *SampledLogger) LogError(message error) {
+	if message != nil && l.allow(Error, message.Error()) {
+		l.target().LogError(message)
+	}
+}
+
+// TaskError log message, completed tasks are not sampled
+func (l *SampledLogger) TaskError(format string, isComplete bool, words ...string) {
+	if isComplete || l.allow(Error, format) {
+		l.target().TaskError(format, isComplete, words...)
+	}
+}
+
+// Fatal log message, it is not sampled
+func (l *SampledLogger) Fatal(format string, words ...string) {
+	l.target().Fatal(format, words...)
+}
+
+// FatalError log message, it is not sampled
+func (l *SampledLogger) FatalError(e error, format string, words ...string) {
+	l.target().FatalError(e, format, words...)
+}
+
+func (l *SampledLogger) writeTaskSuccess(format string, isComplete bool, words ...string) {
+	if isComplete || l.allow(Info, format) {
+		sinkTaskSuccess(l.target(), format, isComplete, words...)
+	}
+}
+
+func (l *SampledLogger) writeTaskError(format string, isComplete bool, words ...string) {
+	if isComplete || l.allow(Error, format) {
+		sinkTaskError(l.target(), format, isComplete, words...)
+	}
+}
+
+func (l *SampledLogger) writeFatal(format string, words ...string) {
+	sinkFatal(l.target(), format, words...)
+}
+
+func (l *SampledLogger) writeFatalError(e error, format string, words ...string) {
+	sinkFatalError(l.target(), e, format, words...)
+}
+
+func (l *SampledLogger) target() Log {
+	return decorateSink(l.sink, l.fields, l.correlationId)
+}
+
+// allow counts the message and returns true if it should be written
+func (l *SampledLogger) allow(level Level, format string) bool {
+	state := l.state
+	state.mutex.Lock()
+	defer state.mutex.Unlock()
+
+	rule, ok := state.options.Rules[level]
+	if !ok {
+		state.written++
+		return true
+	}
+
+	key := samplingKey{level: level, format: format}
+	counter, ok := state.counters[key]
+	if !ok {
+		counter = &samplingCounter{}
+		state.counters[key] = counter
+	}
+	counter.count++
+
+	if counter.count <= rule.First || (rule.Thereafter > 0 && (counter.count-rule.First)%rule.Thereafter == 0) {
+		state.written++
+		return true
+	}
+
+	counter.suppressed++
+	state.suppressed++
+	return false
+}
+
+// run writes the summaries at the end of every interval until the logger is closed
+func (l *SampledLogger) run() {
+	defer close(l.state.stopped)
+
+	ticker := time.NewTicker(l.state.options.Interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			l.summarize()
+		case <-l.state.stop:
+			return
+		}
+	}
+}
+
+// summarize resets the counters and writes how many messages of each key were suppressed
+func (l *SampledLogger) summarize() {
+	state := l.state
+	state.mutex.Lock()
+	counters := state.counters
+	state.counters = make(map[samplingKey]*samplingCounter)
+	state.mutex.Unlock()
+
+	keys := make([]samplingKey, 0, len(counters))
+	for key, counter := range counters {
+		if counter.suppressed > 0 {
+			keys = append(keys, key)
+		}
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if keys[i].level != keys[j].level {
+			return keys[i].level < keys[j].level
+		}
+		return keys[i].format < keys[j].format
+	})
+
+	for _, key := range keys {
+		l.sink.Log("Suppressed %s messages like %s", key.level, strconv.Itoa(counters[key].suppressed), strconv.Quote(key.format))
+	}
+}
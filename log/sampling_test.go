@@ -0,0 +1,79 @@
+package log
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSampledLogger_WritesTheFirstAndThenOneInEvery(t *testing.T) {
+	// Arrange
+	var buffer bytes.Buffer
+	logger := &Logger{LogLevel: Info}
+	sampledLogger := logger.AddSampledLogger(NewJsonLogger(&buffer), SamplingOptions{
+		Interval: time.Hour,
+		Rules:    map[Level]SamplingRule{Warning: {First: 3, Thereafter: 10}},
+	})
+	defer sampledLogger.Close()
+
+	// Act
+	for i := 0; i < 25; i++ {
+		logger.With("attempt", i).Warn("retrying %s", fmt.Sprint(i))
+	}
+	logger.Info("not sampled")
+
+	// Assert
+	lines := decodeJsonLines(t, &buffer)
+	assert.Len(t, lines, 6)
+	assert.Equal(t, float64(12), lines[3]["attempt"])
+	assert.Equal(t, float64(22), lines[4]["attempt"])
+	assert.Equal(t, "not sampled", lines[5]["message"])
+	assert.Equal(t, SamplingStats{Written: 6, Suppressed: 20}, sampledLogger.Stats())
+}
+
+func TestSampledLogger_SummarizesAndResetsTheInterval(t *testing.T) {
+	// Arrange
+	var buffer bytes.Buffer
+	sampledLogger := NewSampledLogger(NewJsonLogger(&buffer), SamplingOptions{
+		Interval: time.Hour,
+		Rules:    map[Level]SamplingRule{Error: {First: 1}},
+	})
+	defer sampledLogger.Close()
+	for i := 0; i < 5; i++ {
+		sampledLogger.Error("connection to %s failed", "db")
+	}
+
+	// Act
+	sampledLogger.summarize()
+	sampledLogger.Error("connection to %s failed", "db")
+
+	// Assert
+	lines := decodeJsonLines(t, &buffer)
+	assert.Len(t, lines, 3)
+	assert.Equal(t, "connection to db failed", lines[0]["message"])
+	assert.Equal(t, `Suppressed 4 messages like "connection to %s failed"`, lines[1]["message"])
+	assert.Equal(t, "error", lines[1]["level"])
+	assert.Equal(t, "connection to db failed", lines[2]["message"])
+}
+
+func TestSampledLogger_WritesTheSummaryPeriodically(t *testing.T) {
+	// Arrange
+	writer := &blockingWriter{release: make(chan struct{})}
+	close(writer.release)
+	sampledLogger := NewSampledLogger(NewJsonLogger(writer), SamplingOptions{
+		Interval: 10 * time.Millisecond,
+		Rules:    map[Level]SamplingRule{Info: {First: 1}},
+	})
+	defer sampledLogger.Close()
+
+	// Act
+	sampledLogger.Info("hot path")
+	sampledLogger.Info("hot path")
+
+	// Assert
+	waitUntil(t, func() bool { return strings.Contains(writer.String(), "Suppressed 1 messages") })
+}
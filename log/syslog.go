@@ -0,0 +1,308 @@
+package log
+
+import (
+	"bytes"
+	"errors"
+	"net"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// SyslogFacility is the syslog facility of the messages
+type SyslogFacility int
+
+// Syslog facilities, the zero value is replaced by SyslogFacilityUser
+const (
+	SyslogFacilityUser   SyslogFacility = 1
+	SyslogFacilityDaemon SyslogFacility = 3
+	SyslogFacilityAuth   SyslogFacility = 4
+	SyslogFacilityLocal0 SyslogFacility = 16
+	SyslogFacilityLocal1 SyslogFacility = 17
+	SyslogFacilityLocal2 SyslogFacility = 18
+	SyslogFacilityLocal3 SyslogFacility = 19
+	SyslogFacilityLocal4 SyslogFacility = 20
+	SyslogFacilityLocal5 SyslogFacility = 21
+	SyslogFacilityLocal6 SyslogFacility = 22
+	SyslogFacilityLocal7 SyslogFacility = 23
+)
+
+// Syslog severities as defined by RFC5424
+const (
+	syslogSeverityError   = 3
+	syslogSeverityWarning = 4
+	syslogSeverityNotice  = 5
+	syslogSeverityInfo    = 6
+	syslogSeverityDebug   = 7
+)
+
+const (
+	syslogNilValue        = "-"
+	syslogStructuredId    = "fields@32473"
+	syslogTimestampLayout = "2006-01-02T15:04:05.000000Z07:00"
+	defaultSyslogSocket   = "/dev/log"
+)
+
+// SyslogOptions configures the syslog logger. Network is udp, tcp, unix or unixgram, when it
+// is empty the messages are sent to the local /dev/log socket. Messages sent over stream
+// networks are framed with their length as described in RFC6587
+type SyslogOptions struct {
+	Network     string
+	Address     string
+	Facility    SyslogFacility
+	AppName     string
+	Hostname    string
+	DialTimeout time.Duration
+}
+
+// SyslogLogger sends the entries as RFC5424 messages, the fields and the correlation id are
+// written as structured data
+type SyslogLogger struct {
+	baseLogger
+	connection *socketWriter
+}
+
+// NewSyslogLogger Creates a syslog logger connected to the server
+func NewSyslogLogger(options SyslogOptions) (*SyslogLogger, error) {
+	if options.Network == "" {
+		options.Network = "unixgram"
+	}
+	if options.Address == "" {
+		if options.Network != "unix" && options.Network != "unixgram" {
+			return nil, errors.New("syslog address is empty")
+		}
+		options.Address = defaultSyslogSocket
+	}
+	if options.Facility == 0 {
+		options.Facility = SyslogFacilityUser
+	}
+	if options.AppName == "" {
+		options.AppName = defaultAppName()
+	}
+	if options.Hostname == "" {
+		options.Hostname, _ = os.Hostname()
+	}
+
+	connection, err := newSocketWriter(options.Network, options.Address, options.DialTimeout)
+	if err != nil {
+		return nil, err
+	}
+
+	framed := options.Network == "tcp" || options.Network == "tcp4" || options.Network == "tcp6" || options.Network == "unix"
+	encode := func(entry Entry, useTimestamp bool) []byte {
+		message := encodeSyslogEntry(entry, useTimestamp, options)
+		if framed {
+			return append([]byte(strconv.Itoa(len(message))+" "), message...)
+		}
+
+		return message
+	}
+
+	return &SyslogLogger{
+		baseLogger: newBaseLogger(connection, encode),
+		connection: connection,
+	}, nil
+}
+
+// WithFields Returns a logger sharing the same connection that also writes the fields
+func (l *SyslogLogger) WithFields(fields map[string]interface{}) Log {
+	return &SyslogLogger{
+		baseLogger: l.withFields(fields),
+		connection: l.connection,
+	}
+}
+
+// WithCorrelationId Returns a logger sharing the same connection that writes the correlation id
+func (l *SyslogLogger) WithCorrelationId(correlationId string) Log {
+	return &SyslogLogger{
+		baseLogger: l.withCorrelationId(correlationId),
+		connection: l.connection,
+	}
+}
+
+func (l *SyslogLogger) withCaller(caller string, at time.Time) Log {
+	return &SyslogLogger{
+		baseLogger: l.withCallerLocation(caller, at),
+		connection: l.connection,
+	}
+}
+
+// Close Closes the connection to the server
+func (l *SyslogLogger) Close() error {
+	l.output.mutex.Lock()
+	defer l.output.mutex.Unlock()
+	return l.connection.Close()
+}
+
+// encodeSyslogEntry renders the entry as a RFC5424 message without the transport framing
+func encodeSyslogEntry(entry Entry, useTimestamp bool, options SyslogOptions) []byte {
+	var buffer bytes.Buffer
+	priority := int(options.Facility)*8 + syslogSeverity(entry)
+	buffer.WriteString("<" + strconv.Itoa(priority) + ">1 ")
+
+	timestamp := syslogNilValue
+	if useTimestamp {
+		timestamp = entry.Time.Format(syslogTimestampLayout)
+	}
+	buffer.WriteString(timestamp + " ")
+	buffer.WriteString(syslogHeaderValue(options.Hostname, 255) + " ")
+	buffer.WriteString(syslogHeaderValue(options.AppName, 48) + " ")
+	buffer.WriteString(strconv.Itoa(os.Getpid()) + " ")
+	buffer.WriteString(syslogNilValue + " ")
+
+	parameters := make(map[string]interface{}, len(entry.Fields)+2)
+	for key, value := range entry.Fields {
+		parameters[key] = value
+	}
+	if entry.CorrelationId != "" {
+		parameters["correlation_id"] = entry.CorrelationId
+	}
+	if entry.Caller != "" {
+		parameters["caller"] = entry.Caller
+	}
+
+	if len(parameters) == 0 {
+		buffer.WriteString(syslogNilValue)
+	} else {
+		buffer.WriteString("[" + syslogStructuredId)
+		for _, key := range sortedFieldKeys(parameters) {
+			buffer.WriteString(" " + syslogParameterName(key) + "=\"" + syslogParameterValue(parameters[key]) + "\"")
+		}
+		buffer.WriteString("]")
+	}
+
+	message := entry.Message
+	if entry.Error != nil && entry.Error.Error() != message {
+		message = message + ": " + entry.Error.Error()
+	}
+	if message != "" {
+		buffer.WriteString(" " + message)
+	}
+
+	return buffer.Bytes()
+}
+
+// syslogSeverity maps the level and the kind of the entry to a syslog severity
+func syslogSeverity(entry Entry) int {
+	switch entry.Level {
+	case Error:
+		return syslogSeverityError
+	case Warning:
+		return syslogSeverityWarning
+	case Info:
+		if entry.Kind == KindNotice {
+			return syslogSeverityNotice
+		}
+		return syslogSeverityInfo
+	}
+
+	return syslogSeverityDebug
+}
+
+// syslogHeaderValue keeps the printable ascii characters of a header field
+func syslogHeaderValue(value string, maxLength int) string {
+	result := strings.Map(func(r rune) rune {
+		if r < 33 || r > 126 {
+			return -1
+		}
+		return r
+	}, value)
+
+	if len(result) > maxLength {
+		result = result[:maxLength]
+	}
+	if result == "" {
+		return syslogNilValue
+	}
+
+	return result
+}
+
+func syslogParameterName(name string) string {
+	result := strings.Map(func(r rune) rune {
+		if r < 33 || r > 126 || r == '=' || r == ']' || r == '"' {
+			return '_'
+		}
+		return r
+	}, name)
+
+	if len(result) > 32 {
+		result = result[:32]
+	}
+
+	return result
+}
+
+func syslogParameterValue(value interface{}) string {
+	replacer := strings.NewReplacer(`\`, `\\`, `"`, `\"`, `]`, `\]`)
+	return replacer.Replace(fieldText(value))
+}
+
+func defaultAppName() string {
+	if len(os.Args) == 0 {
+		return ""
+	}
+
+	return filepath.Base(os.Args[0])
+}
+
+// socketWriter writes every call as one message to the connection, dialing it again once
+// if the write fails
+type socketWriter struct {
+	network string
+	address string
+	timeout time.Duration
+	conn    net.Conn
+}
+
+func newSocketWriter(network string, address string, timeout time.Duration) (*socketWriter, error) {
+	writer := &socketWriter{network: network, address: address, timeout: timeout}
+	if err := writer.dial(); err != nil {
+		return nil, err
+	}
+
+	return writer, nil
+}
+
+func (w *socketWriter) dial() error {
+	timeout := w.timeout
+	if timeout <= 0 {
+		timeout = 5 * time.Second
+	}
+
+	conn, err := net.DialTimeout(w.network, w.address, timeout)
+	if err != nil {
+		return err
+	}
+
+	w.conn = conn
+	return nil
+}
+
+func (w *socketWriter) Write(p []byte) (int, error) {
+	if w.conn != nil {
+		if n, err := w.conn.Write(p); err == nil {
+			return n, nil
+		}
+		w.conn.Close()
+		w.conn = nil
+	}
+
+	if err := w.dial(); err != nil {
+		return 0, err
+	}
+
+	return w.conn.Write(p)
+}
+
+func (w *socketWriter) Close() error {
+	if w.conn == nil {
+		return nil
+	}
+
+	err := w.conn.Close()
+	w.conn = nil
+	return err
+}
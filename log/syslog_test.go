@@ -0,0 +1,117 @@
+package log
+
+import (
+	"bufio"
+	"errors"
+	"io"
+	"net"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSyslogLogger_SendsRFC5424OverUdp(t *testing.T) {
+	// Arrange
+	listener, err := net.ListenPacket("udp", "127.0.0.1:0")
+	assert.Nil(t, err)
+	defer listener.Close()
+	logger, err := NewSyslogLogger(SyslogOptions{Network: "udp", Address: listener.LocalAddr().String(), Facility: SyslogFacilityLocal0, AppName: "api", Hostname: "host"})
+	assert.Nil(t, err)
+	defer logger.Close()
+	logger.UseTimestamp(false)
+
+	// Act
+	logger.WithFields(map[string]interface{}{"user": `bob "b"`}).Warn("disk %s", "full")
+	logger.Exception(errors.New("boom"), "failed")
+
+	// Assert
+	first := readDatagram(t, listener)
+	assert.Regexp(t, `^<132>1 - host api \d+ - \[fields@32473 caller="log/syslog_test\.go:\d+" user="bob \\"b\\""\] disk full$`, first)
+	second := readDatagram(t, listener)
+	assert.True(t, strings.HasPrefix(second, "<131>1 - host api "))
+	assert.True(t, strings.HasSuffix(second, "] failed: boom"))
+}
+
+func TestSyslogLogger_FramesMessagesOverTcp(t *testing.T) {
+	// Arrange
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.Nil(t, err)
+	defer listener.Close()
+	received := make(chan string, 2)
+	go func() {
+		conn, err := listener.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		reader := bufio.NewReader(conn)
+		for i := 0; i < 2; i++ {
+			length, _ := reader.ReadString(' ')
+			size, _ := strconv.Atoi(strings.TrimSpace(length))
+			message := make([]byte, size)
+			if _, err := io.ReadFull(reader, message); err != nil {
+				return
+			}
+			received <- string(message)
+		}
+	}()
+	logger, err := NewSyslogLogger(SyslogOptions{Network: "tcp", Address: listener.Addr().String(), AppName: "api", Hostname: "host"})
+	assert.Nil(t, err)
+	defer logger.Close()
+
+	// Act
+	logger.Info("first")
+	logger.Debug("second")
+
+	// Assert
+	assert.Regexp(t, `^<14>1 \d{4}-\d\d-\d\dT\S+ host api \d+ - \[.*\] first$`, waitMessage(t, received))
+	assert.Regexp(t, `^<15>1 .* second$`, waitMessage(t, received))
+}
+
+func TestSyslogLogger_SendsOverUnixDatagram(t *testing.T) {
+	// Arrange
+	path := filepath.Join(t.TempDir(), "syslog.sock")
+	listener, err := net.ListenUnixgram("unixgram", &net.UnixAddr{Name: path, Net: "unixgram"})
+	assert.Nil(t, err)
+	defer listener.Close()
+	logger, err := NewSyslogLogger(SyslogOptions{Address: path, AppName: "api", Hostname: "host"})
+	assert.Nil(t, err)
+	defer logger.Close()
+
+	// Act
+	logger.Notice("notice")
+
+	// Assert
+	assert.Regexp(t, `^<13>1 .* notice$`, readDatagram(t, listener))
+}
+
+func TestSyslogLogger_RequiresAnAddressForNetworkTransports(t *testing.T) {
+	// Act
+	_, err := NewSyslogLogger(SyslogOptions{Network: "udp"})
+
+	// Assert
+	assert.EqualError(t, err, "syslog address is empty")
+}
+
+func readDatagram(t *testing.T, listener net.PacketConn) string {
+	buffer := make([]byte, 65536)
+	listener.SetReadDeadline(time.Now().Add(2 * time.Second))
+	n, _, err := listener.ReadFrom(buffer)
+	assert.Nil(t, err)
+	return string(buffer[:n])
+}
+
+func waitMessage(t *testing.T, received chan string) string {
+	select {
+	case message := <-received:
+		return message
+	case <-time.After(2 * time.Second):
+		t.Fatal("timed out waiting for the message")
+	}
+
+	return ""
+}
@@ -1,6 +1,13 @@
 package odata
 
-import "github.com/cjlapao/common-go/parser"
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/cjlapao/common-go/parser"
+)
 
 // GlobalFilterTokenizer the global filter tokenizer
 var globalFilterTokenizer = filterTokenizer()
@@ -30,15 +37,16 @@ func filterTokenizer() *parser.Tokenizer {
 	tokenizer.Add("^\\(", parser.FilterTokenOpenParen)
 	tokenizer.Add("^\\)", parser.FilterTokenCloseParen)
 	tokenizer.Add("^,", parser.FilterTokenComma)
-	tokenizer.Add("^(eq|ne|gt|ge|lt|le|and|or) ", parser.FilterTokenLogical)
+	tokenizer.Add("^(eq|ne|gt|ge|lt|le|and|or|in) ", parser.FilterTokenLogical)
 	tokenizer.Add("^(contains|endswith|startswith)", parser.FilterTokenFunc)
+	tokenizer.Add("^[0-9]{4,4}-[0-9]{2,2}-[0-9]{2,2}T[0-9]{2,2}:[0-9]{2,2}(:[0-9]{2,2}(.[0-9]+)?)?(Z|[+-][0-9]{2,2}:[0-9]{2,2})", parser.FilterTokenDateTime)
+	tokenizer.Add("^-?[0-9]{4,4}-[0-9]{2,2}-[0-9]{2,2}", parser.FilterTokenDate)
+	tokenizer.Add("^[0-9]{2,2}:[0-9]{2,2}(:[0-9]{2,2}(.[0-9]+)?)?", parser.FilterTokenTime)
 	tokenizer.Add("^-?[0-9]+\\.[0-9]+", parser.FilterTokenFloat)
 	tokenizer.Add("^-?[0-9]+", parser.FilterTokenInteger)
 	tokenizer.Add("^(?i:true|false)", parser.FilterTokenBoolean)
+	tokenizer.Add("^null\\b", parser.FilterTokenNull)
 	tokenizer.Add("^'(''|[^'])*'", parser.FilterTokenString)
-	tokenizer.Add("^-?[0-9]{4,4}-[0-9]{2,2}-[0-9]{2,2}", parser.FilterTokenDate)
-	tokenizer.Add("^[0-9]{2,2}:[0-9]{2,2}(:[0-9]{2,2}(.[0-9]+)?)?", parser.FilterTokenTime)
-	tokenizer.Add("^[0-9]{4,4}-[0-9]{2,2}-[0-9]{2,2}T[0-9]{2,2}:[0-9]{2,2}(:[0-9]{2,2}(.[0-9]+)?)?(Z|[+-][0-9]{2,2}:[0-9]{2,2})", parser.FilterTokenDateTime)
 	tokenizer.Add("^[a-zA-Z][a-zA-Z0-9_.]*", parser.FilterTokenLiteral)
 	tokenizer.Add("^_id", parser.FilterTokenLiteral)
 	tokenizer.Ignore("^ ", parser.FilterTokenWhitespace)
@@ -55,6 +63,7 @@ func filterParser() *parser.Parser {
 	filterParser.DefineOperator("le", 2, parser.OpAssociationLeft, 4)
 	filterParser.DefineOperator("eq", 2, parser.OpAssociationLeft, 3)
 	filterParser.DefineOperator("ne", 2, parser.OpAssociationLeft, 3)
+	filterParser.DefineListOperator("in", parser.OpAssociationLeft, 3)
 	filterParser.DefineOperator("and", 2, parser.OpAssociationLeft, 2)
 	filterParser.DefineOperator("or", 2, parser.OpAssociationLeft, 1)
 	filterParser.DefineFunction("contains", 2)
@@ -63,3 +72,39 @@ func filterParser() *parser.Parser {
 
 	return filterParser
 }
+
+// TokenValue Returns the Go value of a filter value token, strings are unquoted and lists
+// return the values of their items
+func TokenValue(token *parser.Token) (interface{}, error) {
+	switch token.Type {
+	case parser.FilterTokenString:
+		text, _ := token.Value.(string)
+		if len(text) < 2 || text[0] != '\'' || text[len(text)-1] != '\'' {
+			return nil, errors.New("invalid string value " + text)
+		}
+		return strings.ReplaceAll(text[1:len(text)-1], "''", "'"), nil
+	case parser.FilterTokenInteger, parser.FilterTokenFloat, parser.FilterTokenBoolean:
+		return token.Value, nil
+	case parser.FilterTokenDate, parser.FilterTokenDateTime, parser.FilterTokenTime:
+		value, ok := token.Value.(time.Time)
+		if !ok || value.IsZero() {
+			return nil, errors.New("invalid date or time value")
+		}
+		return value, nil
+	case parser.FilterTokenNull:
+		return nil, nil
+	case parser.FilterTokenList:
+		items, _ := token.Value.([]*parser.Token)
+		values := make([]interface{}, len(items))
+		for i, item := range items {
+			value, err := TokenValue(item)
+			if err != nil {
+				return nil, err
+			}
+			values[i] = value
+		}
+		return values, nil
+	}
+
+	return nil, fmt.Errorf("%v is not a value", token.Value)
+}
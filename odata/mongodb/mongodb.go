@@ -0,0 +1,248 @@
+package mongodb
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"regexp"
+	"strings"
+
+	"github.com/cjlapao/common-go/odata"
+	"github.com/cjlapao/common-go/parser"
+)
+
+// ErrInvalidInput Client errors
+var ErrInvalidInput = errors.New("odata syntax error")
+
+// ErrUnknownField is returned when a field is not in the field map of the options
+var ErrUnknownField = errors.New("odata field is not allowed")
+
+var comparisonOperators = map[string]string{
+	"eq": "$eq",
+	"ne": "$ne",
+	"gt": "$gt",
+	"ge": "$gte",
+	"lt": "$lt",
+	"le": "$lte",
+	"in": "$in",
+}
+
+var logicalOperators = map[string]string{
+	"and": "$and",
+	"or":  "$or",
+}
+
+// Options restricts and renames the fields used in the query. When Fields is empty every
+// field is allowed and used as is, otherwise it maps the OData field to the document field,
+// for example {"id": "_id"}
+type Options struct {
+	Fields map[string]string
+}
+
+// Query holds the documents to pass to the MongoDB driver. Sort keeps its keys in SortKeys in
+// the $orderby order as a map has no order, build an ordered document from them when sorting
+// by more than one field
+type Query struct {
+	Filter     map[string]interface{}
+	Sort       map[string]interface{}
+	SortKeys   []string
+	Projection map[string]interface{}
+	Skip       int64
+	Limit      int64
+	Count      bool
+}
+
+// BuildQuery Parses the OData query values and builds the MongoDB documents
+func BuildQuery(query url.Values, options Options) (*Query, error) {
+	queryMap, err := odata.ParseURLValues(query)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrInvalidInput, err.Error())
+	}
+
+	return BuildQueryFromMap(queryMap, options)
+}
+
+// BuildQueryFromMap Builds the MongoDB documents from the output of odata.ParseURLValues
+func BuildQueryFromMap(queryMap map[string]interface{}, options Options) (*Query, error) {
+	result := &Query{Filter: map[string]interface{}{}}
+
+	if node, ok := queryMap[odata.Filter].(*parser.ParseNode); ok && node != nil {
+		filter, err := options.Filter(node)
+		if err != nil {
+			return nil, err
+		}
+		result.Filter = filter
+	}
+
+	if items, ok := queryMap[odata.OrderBy].([]odata.OrderItem); ok && len(items) > 0 {
+		sort, keys, err := options.Sort(items)
+		if err != nil {
+			return nil, err
+		}
+		result.Sort = sort
+		result.SortKeys = keys
+	}
+
+	if fields, ok := queryMap[odata.Select].([]string); ok {
+		projection, err := options.Projection(fields)
+		if err != nil {
+			return nil, err
+		}
+		result.Projection = projection
+	}
+
+	if limit, ok := queryMap[odata.Top].(int); ok {
+		if limit < 0 {
+			return nil, fmt.Errorf("%w: $top cannot be negative", ErrInvalidInput)
+		}
+		result.Limit = int64(limit)
+	}
+	if skip, ok := queryMap[odata.Skip].(int); ok {
+		if skip < 0 {
+			return nil, fmt.Errorf("%w: $skip cannot be negative", ErrInvalidInput)
+		}
+		result.Skip = int64(skip)
+	}
+
+	count, _ := queryMap[odata.Count].(bool)
+	inlineCount, _ := queryMap[odata.InlineCount].(string)
+	result.Count = count || strings.TrimSpace(inlineCount) == "allpages"
+
+	return result, nil
+}
+
+// Filter Converts the filter tree into a query document, nested and/or operators are
+// flattened into a single $and/$or
+func (o Options) Filter(node *parser.ParseNode) (map[string]interface{}, error) {
+	if node == nil || node.Token == nil || len(node.Children) != 2 {
+		return nil, ErrInvalidInput
+	}
+
+	operator, _ := node.Token.Value.(string)
+	if mongoOperator, ok := logicalOperators[operator]; ok {
+		conditions := make([]interface{}, 0, 2)
+		for _, child := range node.Children {
+			condition, err := o.Filter(child)
+			if err != nil {
+				return nil, err
+			}
+			if nested, ok := condition[mongoOperator].([]interface{}); ok && len(condition) == 1 {
+				conditions = append(conditions, nested...)
+			} else {
+				conditions = append(conditions, condition)
+			}
+		}
+
+		return map[string]interface{}{mongoOperator: conditions}, nil
+	}
+
+	field, value, err := o.fieldAndValue(node)
+	if err != nil {
+		return nil, err
+	}
+
+	if mongoOperator, ok := comparisonOperators[operator]; ok {
+		if _, isList := value.([]interface{}); isList != (operator == "in") {
+			return nil, fmt.Errorf("%w: invalid value for %s", ErrInvalidInput, operator)
+		}
+
+		return map[string]interface{}{field: map[string]interface{}{mongoOperator: value}}, nil
+	}
+
+	text, ok := value.(string)
+	if !ok {
+		return nil, fmt.Errorf("%w: %s needs a string value", ErrInvalidInput, operator)
+	}
+
+	pattern := regexp.QuoteMeta(text)
+	switch operator {
+	case "contains":
+	case "startswith":
+		pattern = "^" + pattern
+	case "endswith":
+		pattern = pattern + "$"
+	default:
+		return nil, fmt.Errorf("%w: unknown operator %v", ErrInvalidInput, node.Token.Value)
+	}
+
+	return map[string]interface{}{field: map[string]interface{}{"$regex": pattern}}, nil
+}
+
+// Sort Converts the order items into a sort document and returns its keys in order
+func (o Options) Sort(items []odata.OrderItem) (map[string]interface{}, []string, error) {
+	sort := make(map[string]interface{}, len(items))
+	keys := make([]string, 0, len(items))
+	for _, item := range items {
+		field, err := o.field(item.Field)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		direction := 1
+		if item.Order == odata.Descendent {
+			direction = -1
+		}
+		if _, exists := sort[field]; !exists {
+			keys = append(keys, field)
+		}
+		sort[field] = direction
+	}
+
+	return sort, keys, nil
+}
+
+// Projection Converts the selected fields into a projection document, selecting all the
+// fields returns nil
+func (o Options) Projection(fields []string) (map[string]interface{}, error) {
+	if len(fields) == 0 || (len(fields) == 1 && strings.TrimSpace(fields[0]) == "*") {
+		return nil, nil
+	}
+
+	projection := make(map[string]interface{}, len(fields))
+	for _, name := range fields {
+		field, err := o.field(strings.TrimSpace(name))
+		if err != nil {
+			return nil, err
+		}
+		projection[field] = 1
+	}
+
+	return projection, nil
+}
+
+func (o Options) field(name string) (string, error) {
+	if name == "" || strings.HasPrefix(name, "$") {
+		return "", fmt.Errorf("%w: invalid field name %s", ErrInvalidInput, name)
+	}
+	if len(o.Fields) == 0 {
+		return name, nil
+	}
+
+	field, ok := o.Fields[name]
+	if !ok || field == "" {
+		return "", fmt.Errorf("%w: %s", ErrUnknownField, name)
+	}
+
+	return field, nil
+}
+
+// fieldAndValue returns the document field and the value of a comparison or function node
+func (o Options) fieldAndValue(node *parser.ParseNode) (string, interface{}, error) {
+	fieldNode, valueNode := node.Children[0], node.Children[1]
+	if fieldNode.Token == nil || valueNode.Token == nil || fieldNode.Token.Type != parser.FilterTokenLiteral {
+		return "", nil, ErrInvalidInput
+	}
+
+	name, _ := fieldNode.Token.Value.(string)
+	field, err := o.field(name)
+	if err != nil {
+		return "", nil, err
+	}
+
+	value, err := odata.TokenValue(valueNode.Token)
+	if err != nil {
+		return "", nil, fmt.Errorf("%w: %s", ErrInvalidInput, err.Error())
+	}
+
+	return field, value, nil
+}
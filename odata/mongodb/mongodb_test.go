@@ -0,0 +1,144 @@
+package mongodb
+
+import (
+	"errors"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBuildQuery_ConvertsAllOptions(t *testing.T) {
+	// Arrange
+	query := url.Values{}
+	query.Set("$select", "name,address.city")
+	query.Set("$filter", "name eq 'O''Brien' and age ge 21 and createdAt lt 2021-03-04T10:00:00Z")
+	query.Set("$orderby", "age desc,name")
+	query.Set("$top", "10")
+	query.Set("$skip", "20")
+	query.Set("$count", "")
+
+	// Act
+	result, err := BuildQuery(query, Options{})
+
+	// Assert
+	assert.Nil(t, err)
+	assert.Equal(t, map[string]interface{}{
+		"$and": []interface{}{
+			map[string]interface{}{"name": map[string]interface{}{"$eq": "O'Brien"}},
+			map[string]interface{}{"age": map[string]interface{}{"$gte": 21}},
+			map[string]interface{}{"createdAt": map[string]interface{}{"$lt": time.Date(2021, 3, 4, 10, 0, 0, 0, time.UTC)}},
+		},
+	}, result.Filter)
+	assert.Equal(t, map[string]interface{}{"age": -1, "name": 1}, result.Sort)
+	assert.Equal(t, []string{"age", "name"}, result.SortKeys)
+	assert.Equal(t, map[string]interface{}{"name": 1, "address.city": 1}, result.Projection)
+	assert.Equal(t, int64(10), result.Limit)
+	assert.Equal(t, int64(20), result.Skip)
+	assert.True(t, result.Count)
+}
+
+func TestBuildQuery_EmptyQueryMatchesEverything(t *testing.T) {
+	// Act
+	result, err := BuildQuery(url.Values{}, Options{})
+
+	// Assert
+	assert.Nil(t, err)
+	assert.Equal(t, map[string]interface{}{}, result.Filter)
+	assert.Nil(t, result.Sort)
+	assert.Nil(t, result.Projection)
+	assert.Zero(t, result.Limit)
+	assert.False(t, result.Count)
+}
+
+func TestFilter_KeepsOrAndPrecedence(t *testing.T) {
+	// Arrange
+	query := url.Values{}
+	query.Set("$filter", "status ne 'closed' or (priority gt 2 and owner in ('ann','bob'))")
+
+	// Act
+	result, err := BuildQuery(query, Options{})
+
+	// Assert
+	assert.Nil(t, err)
+	assert.Equal(t, map[string]interface{}{
+		"$or": []interface{}{
+			map[string]interface{}{"status": map[string]interface{}{"$ne": "closed"}},
+			map[string]interface{}{"$and": []interface{}{
+				map[string]interface{}{"priority": map[string]interface{}{"$gt": 2}},
+				map[string]interface{}{"owner": map[string]interface{}{"$in": []interface{}{"ann", "bob"}}},
+			}},
+		},
+	}, result.Filter)
+}
+
+func TestFilter_FunctionsUseEscapedRegexes(t *testing.T) {
+	// Arrange
+	query := url.Values{}
+	query.Set("$filter", "contains(name,'a.b*') or startswith(name,'(x)') or endswith(name,'$1')")
+
+	// Act
+	result, err := BuildQuery(query, Options{})
+
+	// Assert
+	assert.Nil(t, err)
+	conditions := result.Filter["$or"].([]interface{})
+	assert.Equal(t, map[string]interface{}{"name": map[string]interface{}{"$regex": `a\.b\*`}}, conditions[0])
+	assert.Equal(t, map[string]interface{}{"name": map[string]interface{}{"$regex": `^\(x\)`}}, conditions[1])
+	assert.Equal(t, map[string]interface{}{"name": map[string]interface{}{"$regex": `\$1$`}}, conditions[2])
+}
+
+func TestBuildQuery_MapsAndRestrictsFields(t *testing.T) {
+	// Arrange
+	options := Options{Fields: map[string]string{"id": "_id", "name": "profile.name"}}
+	query := url.Values{}
+	query.Set("$filter", "id eq '59a6fbaf22e60174f5107a9a'")
+	query.Set("$orderby", "name")
+	query.Set("$select", "id,name")
+
+	// Act
+	result, err := BuildQuery(query, options)
+	_, unknownErr := BuildQuery(url.Values{"$filter": {"password eq 'x'"}}, options)
+
+	// Assert
+	assert.Nil(t, err)
+	assert.Equal(t, map[string]interface{}{"_id": map[string]interface{}{"$eq": "59a6fbaf22e60174f5107a9a"}}, result.Filter)
+	assert.Equal(t, map[string]interface{}{"profile.name": 1}, result.Sort)
+	assert.Equal(t, map[string]interface{}{"_id": 1, "profile.name": 1}, result.Projection)
+	assert.True(t, errors.Is(unknownErr, ErrUnknownField))
+}
+
+func TestFilter_NullComparisons(t *testing.T) {
+	// Arrange
+	query := url.Values{}
+	query.Set("$filter", "deletedAt eq null and owner ne null")
+
+	// Act
+	result, err := BuildQuery(query, Options{})
+
+	// Assert
+	assert.Nil(t, err)
+	assert.Equal(t, map[string]interface{}{
+		"$and": []interface{}{
+			map[string]interface{}{"deletedAt": map[string]interface{}{"$eq": nil}},
+			map[string]interface{}{"owner": map[string]interface{}{"$ne": nil}},
+		},
+	}, result.Filter)
+}
+
+func TestBuildQuery_InvalidInput(t *testing.T) {
+	// Arrange
+	tests := []string{"$top=-1", "$skip=-5", "$filter=name eq", "$filter=status in ()", "$filter=contains(name,1)"}
+
+	for _, test := range tests {
+		query, _ := url.ParseQuery(test)
+
+		// Act
+		result, err := BuildQuery(query, Options{})
+
+		// Assert
+		assert.Nil(t, result, test)
+		assert.True(t, errors.Is(err, ErrInvalidInput), test)
+	}
+}
@@ -397,6 +397,11 @@ func TestParseWithFilter(t *testing.T) {
 			"endswith(upc_code, '789'))", true, nil}, // large valid filter case
 		{"_id gt '59a6fbaf22e60174f5107a9a' and upc_code eq 'val'", true, nil}, // paging with mongo id
 		{"gtin eq '123'", true, nil},                                       // key name with operator substring
+		{"status in ('new','open') and index in (1, 2)", true, nil},        // in operator with lists
+		{"status in ()", false, errors.New("")},                            // in operator with an empty list
+		{"status in ('new',)", false, errors.New("")},                      // in operator with a missing value
+		{"status in (name)", false, errors.New("")},                        // in operator with a field in the list
+		{"status in 'new'", false, errors.New("")},                         // in operator without a list
 		{"name eq 'val')", false, errors.New("")},                          // bad parentheses
 		{"(name eq )", false, errors.New("")},                              // missing value in equals operator
 		{"(name eq hello) and (name fakeop hello)", false, errors.New("")}, // bad operator
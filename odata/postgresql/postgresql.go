@@ -6,238 +6,490 @@
 
 package postgresql
 
-// import (
-// 	"database/sql"
-// 	"fmt"
-// 	"net/url"
-// 	"strconv"
-// 	"strings"
-
-// 	parser "github.com/cjlapao/common-go/odata"
-// 	"github.com/pkg/errors"
-// )
-
-// // ErrInvalidInput Client errors
-// var ErrInvalidInput = errors.New("odata syntax error")
-
-// var sqlOperators = map[string]string{
-// 	"eq":         "=",
-// 	"ne":         "!=",
-// 	"gt":         ">",
-// 	"ge":         ">=",
-// 	"lt":         "<",
-// 	"le":         "<=",
-// 	"or":         "or",
-// 	"and":        "and",
-// 	"contains":   "%%%s%%",
-// 	"endswith":   "%%%s",
-// 	"startswith": "%s%%",
-// }
-
-// // ODataSQLQuery builds a SQL like query based on OData 2.0 specification
-// func ODataSQLQuery(query url.Values, table string, column string, db *sql.DB) (*sql.Rows, error) {
-
-// 	// Parse url values
-// 	queryMap, err := parser.ParseURLValues(query)
-// 	if err != nil {
-// 		return nil, errors.Wrap(ErrInvalidInput, err.Error())
-// 	}
-
-// 	var finalQuery strings.Builder
-
-// 	// SELECT clause
-// 	finalQuery.WriteString(buildSelectClause(queryMap, column))
-
-// 	// FROM clause
-// 	finalQuery.WriteString(" FROM ")
-// 	finalQuery.WriteString(pq.QuoteIdentifier(table))
-
-// 	// WHERE clause
-// 	if queryMap[parser.Filter] != nil {
-// 		finalQuery.WriteString(" WHERE ")
-// 		filterQuery, _ := queryMap[parser.Filter].(*parser.ParseNode)
-// 		filterClause, err := applyFilter(filterQuery, column)
-// 		if err != nil {
-// 			return nil, errors.Wrap(ErrInvalidInput, err.Error())
-// 		}
-
-// 		finalQuery.WriteString(filterClause)
-// 	}
-
-// 	// Order by
-// 	if queryMap[parser.OrderBy] != nil {
-// 		finalQuery.WriteString(buildOrderBy(queryMap, column))
-// 	}
-
-// 	// Limit & Offset
-// 	finalQuery.WriteString(buildLimitSkipClause(queryMap))
-
-// 	rows, err := db.Query(finalQuery.String())
-// 	if err != nil {
-// 		return nil, err
-// 	}
-// 	return rows, nil
-
-// }
-
-// // ODataCount returns the number of rows from a table
-// func ODataCount(db *sql.DB, table string) (int, error) {
-// 	var count int
-// 	selectStmt := fmt.Sprintf("SELECT count(*) FROM %s", pq.QuoteIdentifier(table))
-// 	row := db.QueryRow(selectStmt)
-// 	err := row.Scan(&count)
-// 	if err != nil {
-// 		return 0, err
-// 	}
-// 	return count, nil
-// }
-
-// func buildSelectClause(queryMap map[string]interface{}, column string) string {
-
-// 	// Select clause
-// 	// 'data' is the column name of the jsonb data
-// 	selectSlice, _ := queryMap["$select"].([]string)
-// 	if len(selectSlice) == 0 {
-// 		return "SELECT * "
-// 	}
-
-// 	var selectClause strings.Builder
-// 	selectClause.WriteString("SELECT id,jsonb_build_object(")
-// 	col := pq.QuoteIdentifier(column)
-
-// 	for _, fieldName := range selectSlice[:len(selectSlice)-1] {
-// 		fmt.Fprintf(&selectClause, "%s, %s -> %s,",
-// 			pq.QuoteLiteral(fieldName), col, pq.QuoteLiteral(fieldName))
-// 	}
-
-// 	// last one without a comma
-// 	fieldName := selectSlice[len(selectSlice)-1]
-// 	fmt.Fprintf(&selectClause, "%s, %s -> %s ) AS %s", pq.QuoteLiteral(fieldName), col, pq.QuoteLiteral(fieldName), col)
-
-// 	return selectClause.String()
-// }
-
-// func buildLimitSkipClause(queryMap map[string]interface{}) string {
-
-// 	limit, existLimit := queryMap[parser.Top].(int)
-// 	skip, existSkip := queryMap[parser.Skip].(int)
-
-// 	var queryString strings.Builder
-
-// 	if existLimit {
-// 		queryString.WriteString(" LIMIT ")
-// 		queryString.WriteString(strconv.Itoa(limit))
-// 	}
-
-// 	if existSkip {
-// 		queryString.WriteString(" OFFSET ")
-// 		queryString.WriteString(strconv.Itoa(skip))
-// 	}
-
-// 	return queryString.String()
-
-// }
-
-// func buildOrderBy(queryMap map[string]interface{}, column string) string {
-
-// 	var query strings.Builder
-// 	query.WriteString(" ORDER BY ")
-
-// 	col := pq.QuoteIdentifier(column)
-// 	orderBySlice := queryMap[parser.OrderBy].([]parser.OrderItem)
-
-// 	for id, item := range orderBySlice {
-// 		fmt.Fprintf(&query, "%s ->> %s", col, pq.QuoteLiteral(item.Field))
-// 		if item.Order == "desc" {
-// 			query.WriteString(" DESC ")
-// 		}
-
-// 		if len(orderBySlice) > id+1 {
-// 			query.WriteString(",")
-// 		}
-// 	}
-
-// 	return query.String()
-// }
-
-// func applyFilter(node *parser.ParseNode, column string) (string, error) {
-
-// 	if len(node.Children) != 2 {
-// 		return "", ErrInvalidInput
-// 	}
-
-// 	var filter strings.Builder
-
-// 	operator := node.Token.Value.(string)
-// 	sqlOp := sqlOperators[operator]
-// 	if operator == "" || sqlOp == "" {
-// 		// invalid or unknown operator
-// 		return "", ErrInvalidInput
-// 	}
-
-// 	switch operator {
-
-// 	case "eq", "ne", "gt", "ge", "lt", "le":
-
-// 		if _, keyOk := node.Children[0].Token.Value.(string); !keyOk {
-// 			return "", ErrInvalidInput
-// 		}
-
-// 		left := pq.QuoteLiteral(node.Children[0].Token.Value.(string))
-
-// 		if value, valueOk := node.Children[1].Token.Value.(string); valueOk {
-// 			node.Children[1].Token.Value = escapeQuote(value)
-// 		}
-
-// 		right := pq.QuoteLiteral(fmt.Sprintf("%v", node.Children[1].Token.Value))
-
-// 		fmt.Fprintf(&filter, "%s ->> %s %s %s", pq.QuoteIdentifier(column), left, sqlOp, right)
-
-// 	case "or", "and":
-
-// 		leftFilter, err := applyFilter(node.Children[0], column) // Left children
-// 		if err != nil {
-// 			return "", err
-// 		}
-// 		rightFilter, err := applyFilter(node.Children[1], column) // Right children
-// 		if err != nil {
-// 			return "", err
-// 		}
-// 		fmt.Fprintf(&filter, "%s %s %s", leftFilter, operator, rightFilter)
-
-// 	//Functions
-// 	case "contains", "endswith", "startswith":
-// 		if _, ok := node.Children[1].Token.Value.(string); !ok {
-// 			return "", ErrInvalidInput
-// 		}
-// 		// Remove single quote
-// 		value, valueOk := node.Children[1].Token.Value.(string)
-// 		if !valueOk {
-// 			return "", ErrInvalidInput
-// 		}
-// 		node.Children[1].Token.Value = escapeQuote(value)
-
-// 		left := pq.QuoteLiteral(node.Children[0].Token.Value.(string))
-// 		right := pq.QuoteLiteral(fmt.Sprintf(sqlOp, node.Children[1].Token.Value.(string)))
-
-// 		fmt.Fprintf(&filter, "%s ->> %s LIKE %s", pq.QuoteIdentifier(column), left, right)
-// 	}
-
-// 	return filter.String(), nil
-// }
-
-// func escapeQuote(value string) string {
-
-// 	if len(value) <= 1 {
-// 		return ""
-// 	}
-
-// 	if value[0] == '\'' {
-// 		value = value[1:]
-// 	}
-// 	if value[len(value)-1] == '\'' {
-// 		value = value[:len(value)-1]
-// 	}
-
-// 	return value
-// }
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/cjlapao/common-go/odata"
+	"github.com/cjlapao/common-go/parser"
+)
+
+// ErrInvalidInput Client errors
+var ErrInvalidInput = errors.New("odata syntax error")
+
+// ErrUnknownField is returned when a field is not in the field to column map of the table
+var ErrUnknownField = errors.New("odata field is not allowed")
+
+var sqlOperators = map[string]string{
+	"eq":  "=",
+	"ne":  "<>",
+	"gt":  ">",
+	"ge":  ">=",
+	"lt":  "<",
+	"le":  "<=",
+	"or":  "OR",
+	"and": "AND",
+	"in":  "IN",
+}
+
+var likePatterns = map[string]string{
+	"contains":   "%%%s%%",
+	"endswith":   "%%%s",
+	"startswith": "%s%%",
+}
+
+// TableOptions describes the table the query runs against. When JsonbColumn is set the
+// fields are keys of the jsonb document stored in that column, nested keys are separated by
+// dots, and Columns optionally restricts and renames them. Otherwise Columns maps every
+// field that can be used in the query to its column and any other field is rejected
+type TableOptions struct {
+	Table       string
+	JsonbColumn string
+	IdColumn    string
+	Columns     map[string]string
+}
+
+// Query is a parameterized statement, the values of the $1, $2... placeholders are in Args.
+// CountSQL counts the rows matching the filter when the query asked for the count
+type Query struct {
+	SQL       string
+	Args      []interface{}
+	Count     bool
+	CountSQL  string
+	CountArgs []interface{}
+}
+
+// BuildQuery Parses the OData query values and builds the parameterized statement
+func BuildQuery(query url.Values, options TableOptions) (*Query, error) {
+	queryMap, err := odata.ParseURLValues(query)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrInvalidInput, err.Error())
+	}
+
+	return BuildQueryFromMap(queryMap, options)
+}
+
+// BuildQueryFromMap Builds the parameterized statement from the output of odata.ParseURLValues
+func BuildQueryFromMap(queryMap map[string]interface{}, options TableOptions) (*Query, error) {
+	if options.Table == "" {
+		return nil, errors.New("table name is empty")
+	}
+	if options.JsonbColumn == "" && len(options.Columns) == 0 {
+		return nil, errors.New("a jsonb column or the field to column map is required")
+	}
+	if options.IdColumn == "" {
+		options.IdColumn = "id"
+	}
+
+	statement := &statementBuilder{options: options}
+	var sqlQuery strings.Builder
+
+	selectClause, err := statement.selectClause(queryMap)
+	if err != nil {
+		return nil, err
+	}
+	sqlQuery.WriteString(selectClause)
+	sqlQuery.WriteString(" FROM ")
+	sqlQuery.WriteString(QuoteIdentifier(options.Table))
+
+	whereClause, err := statement.whereClause(queryMap)
+	if err != nil {
+		return nil, err
+	}
+	sqlQuery.WriteString(whereClause)
+
+	orderByClause, err := statement.orderByClause(queryMap)
+	if err != nil {
+		return nil, err
+	}
+	sqlQuery.WriteString(orderByClause)
+
+	limitClause, err := statement.limitSkipClause(queryMap)
+	if err != nil {
+		return nil, err
+	}
+	sqlQuery.WriteString(limitClause)
+
+	result := &Query{
+		SQL:  sqlQuery.String(),
+		Args: statement.args,
+	}
+
+	count, _ := queryMap[odata.Count].(bool)
+	inlineCount, _ := queryMap[odata.InlineCount].(string)
+	if count || strings.TrimSpace(inlineCount) == "allpages" {
+		countStatement := &statementBuilder{options: options}
+		countWhere, err := countStatement.whereClause(queryMap)
+		if err != nil {
+			return nil, err
+		}
+
+		result.Count = true
+		result.CountSQL = "SELECT count(*) FROM " + QuoteIdentifier(options.Table) + countWhere
+		result.CountArgs = countStatement.args
+	}
+
+	return result, nil
+}
+
+// ODataSQLQuery builds a SQL like query based on OData 2.0 specification for a table with a
+// jsonb column and runs it
+func ODataSQLQuery(query url.Values, table string, column string, db *sql.DB) (*sql.Rows, error) {
+	statement, err := BuildQuery(query, TableOptions{Table: table, JsonbColumn: column})
+	if err != nil {
+		return nil, err
+	}
+
+	return db.Query(statement.SQL, statement.Args...)
+}
+
+// ODataCount returns the number of rows from a table
+func ODataCount(db *sql.DB, table string) (int, error) {
+	var count int
+	row := db.QueryRow("SELECT count(*) FROM " + QuoteIdentifier(table))
+	if err := row.Scan(&count); err != nil {
+		return 0, err
+	}
+
+	return count, nil
+}
+
+// QuoteIdentifier Quotes an identifier, the parts of a schema qualified name are quoted
+// separately
+func QuoteIdentifier(name string) string {
+	parts := strings.Split(name, ".")
+	for i, part := range parts {
+		if end := strings.IndexRune(part, 0); end > -1 {
+			part = part[:end]
+		}
+		parts[i] = `"` + strings.ReplaceAll(part, `"`, `""`) + `"`
+	}
+
+	return strings.Join(parts, ".")
+}
+
+// statementBuilder numbers the placeholders and keeps their values
+type statementBuilder struct {
+	options TableOptions
+	args    []interface{}
+}
+
+// bind adds the value and returns its placeholder
+func (s *statementBuilder) bind(value interface{}) string {
+	s.args = append(s.args, value)
+	return "$" + strconv.Itoa(len(s.args))
+}
+
+func (s *statementBuilder) selectClause(queryMap map[string]interface{}) (string, error) {
+	fields, _ := queryMap[odata.Select].([]string)
+	if len(fields) == 0 || (len(fields) == 1 && strings.TrimSpace(fields[0]) == "*") {
+		return "SELECT *", nil
+	}
+
+	columns := make([]string, 0, len(fields))
+	if s.options.JsonbColumn == "" {
+		for _, field := range fields {
+			column, err := s.column(strings.TrimSpace(field))
+			if err != nil {
+				return "", err
+			}
+			columns = append(columns, column)
+		}
+
+		return "SELECT " + strings.Join(columns, ", "), nil
+	}
+
+	for _, field := range fields {
+		field = strings.TrimSpace(field)
+		path, err := s.jsonPath(field)
+		if err != nil {
+			return "", err
+		}
+		columns = append(columns, s.bind(field)+"::text, "+s.jsonValue(path, false))
+	}
+
+	jsonbColumn := QuoteIdentifier(s.options.JsonbColumn)
+	return "SELECT " + QuoteIdentifier(s.options.IdColumn) + ", jsonb_build_object(" + strings.Join(columns, ", ") + ") AS " + jsonbColumn, nil
+}
+
+func (s *statementBuilder) whereClause(queryMap map[string]interface{}) (string, error) {
+	node, ok := queryMap[odata.Filter].(*parser.ParseNode)
+	if !ok || node == nil {
+		return "", nil
+	}
+
+	filter, err := s.filter(node)
+	if err != nil {
+		return "", err
+	}
+
+	return " WHERE " + filter, nil
+}
+
+func (s *statementBuilder) orderByClause(queryMap map[string]interface{}) (string, error) {
+	items, _ := queryMap[odata.OrderBy].([]odata.OrderItem)
+	if len(items) == 0 {
+		return "", nil
+	}
+
+	parts := make([]string, 0, len(items))
+	for _, item := range items {
+		field, err := s.orderExpression(item.Field)
+		if err != nil {
+			return "", err
+		}
+
+		direction := "ASC"
+		if item.Order == odata.Descendent {
+			direction = "DESC"
+		}
+		parts = append(parts, field+" "+direction)
+	}
+
+	return " ORDER BY " + strings.Join(parts, ", "), nil
+}
+
+func (s *statementBuilder) limitSkipClause(queryMap map[string]interface{}) (string, error) {
+	var clause strings.Builder
+	if limit, ok := queryMap[odata.Top].(int); ok {
+		if limit < 0 {
+			return "", fmt.Errorf("%w: $top cannot be negative", ErrInvalidInput)
+		}
+		clause.WriteString(" LIMIT " + s.bind(limit))
+	}
+	if skip, ok := queryMap[odata.Skip].(int); ok {
+		if skip < 0 {
+			return "", fmt.Errorf("%w: $skip cannot be negative", ErrInvalidInput)
+		}
+		clause.WriteString(" OFFSET " + s.bind(skip))
+	}
+
+	return clause.String(), nil
+}
+
+// filter converts the filter tree, the and/or operands are wrapped in parentheses so the
+// precedence of the parsed tree is kept
+func (s *statementBuilder) filter(node *parser.ParseNode) (string, error) {
+	if node == nil || node.Token == nil || len(node.Children) != 2 {
+		return "", ErrInvalidInput
+	}
+
+	operator, _ := node.Token.Value.(string)
+	switch operator {
+	case "and", "or":
+		left, err := s.filter(node.Children[0])
+		if err != nil {
+			return "", err
+		}
+		right, err := s.filter(node.Children[1])
+		if err != nil {
+			return "", err
+		}
+
+		return "(" + left + " " + sqlOperators[operator] + " " + right + ")", nil
+	case "eq", "ne", "gt", "ge", "lt", "le":
+		field, value, err := fieldAndValue(node)
+		if err != nil {
+			return "", err
+		}
+		left, err := s.fieldExpression(field, value)
+		if err != nil {
+			return "", err
+		}
+		if value == nil {
+			return nullComparison(left, operator)
+		}
+
+		return left + " " + sqlOperators[operator] + " " + s.bind(value), nil
+	case "in":
+		field, value, err := fieldAndValue(node)
+		if err != nil {
+			return "", err
+		}
+		values, ok := value.([]interface{})
+		if !ok || len(values) == 0 {
+			return "", fmt.Errorf("%w: in needs a list of values", ErrInvalidInput)
+		}
+		for _, item := range values[1:] {
+			if valueKind(item) != valueKind(values[0]) {
+				return "", fmt.Errorf("%w: in needs values of the same type", ErrInvalidInput)
+			}
+		}
+		left, err := s.fieldExpression(field, values[0])
+		if err != nil {
+			return "", err
+		}
+
+		placeholders := make([]string, len(values))
+		for i, item := range values {
+			placeholders[i] = s.bind(item)
+		}
+
+		return left + " " + sqlOperators[operator] + " (" + strings.Join(placeholders, ", ") + ")", nil
+	case "contains", "endswith", "startswith":
+		field, value, err := fieldAndValue(node)
+		if err != nil {
+			return "", err
+		}
+		text, ok := value.(string)
+		if !ok {
+			return "", fmt.Errorf("%w: %s needs a string value", ErrInvalidInput, operator)
+		}
+		left, err := s.fieldExpression(field, nil)
+		if err != nil {
+			return "", err
+		}
+
+		return left + " LIKE " + s.bind(fmt.Sprintf(likePatterns[operator], escapeLike(text))), nil
+	}
+
+	return "", fmt.Errorf("%w: unknown operator %v", ErrInvalidInput, node.Token.Value)
+}
+
+// valueKind returns the postgres type the value is compared as, integers and floats are both
+// numeric
+func valueKind(value interface{}) string {
+	switch value.(type) {
+	case int, float64:
+		return "numeric"
+	case bool:
+		return "boolean"
+	case time.Time:
+		return "timestamptz"
+	}
+
+	return "text"
+}
+
+// nullComparison returns the IS NULL or IS NOT NULL test used for eq null and ne null, the
+// other operators cannot compare with null
+func nullComparison(left string, operator string) (string, error) {
+	switch operator {
+	case "eq":
+		return left + " IS NULL", nil
+	case "ne":
+		return left + " IS NOT NULL", nil
+	}
+
+	return "", fmt.Errorf("%w: %s cannot compare with null", ErrInvalidInput, operator)
+}
+
+// orderExpression returns the column, or the jsonb value of the key so numbers and dates are
+// sorted by the jsonb ordering instead of as text
+func (s *statementBuilder) orderExpression(field string) (string, error) {
+	if s.options.JsonbColumn == "" {
+		return s.column(field)
+	}
+
+	path, err := s.jsonPath(field)
+	if err != nil {
+		return "", err
+	}
+
+	return s.jsonValue(path, false), nil
+}
+
+// fieldExpression returns the column, or the jsonb key cast to the type of the value it is
+// compared with
+func (s *statementBuilder) fieldExpression(field string, value interface{}) (string, error) {
+	if s.options.JsonbColumn == "" {
+		return s.column(field)
+	}
+
+	path, err := s.jsonPath(field)
+	if err != nil {
+		return "", err
+	}
+
+	expression := s.jsonValue(path, true)
+	if kind := valueKind(value); kind != "text" {
+		return "(" + expression + ")::" + kind, nil
+	}
+
+	return expression, nil
+}
+
+func (s *statementBuilder) column(field string) (string, error) {
+	column, ok := s.options.Columns[field]
+	if !ok || column == "" {
+		return "", fmt.Errorf("%w: %s", ErrUnknownField, field)
+	}
+
+	return QuoteIdentifier(column), nil
+}
+
+// jsonPath returns the jsonb keys of the field
+func (s *statementBuilder) jsonPath(field string) ([]string, error) {
+	if field == "" {
+		return nil, ErrInvalidInput
+	}
+	if len(s.options.Columns) > 0 {
+		key, ok := s.options.Columns[field]
+		if !ok || key == "" {
+			return nil, fmt.Errorf("%w: %s", ErrUnknownField, field)
+		}
+		field = key
+	}
+
+	return strings.Split(field, "."), nil
+}
+
+// jsonValue returns the expression reading the keys from the jsonb column as jsonb or as text
+func (s *statementBuilder) jsonValue(path []string, asText bool) string {
+	column := QuoteIdentifier(s.options.JsonbColumn)
+	if len(path) == 1 {
+		operator := " -> "
+		if asText {
+			operator = " ->> "
+		}
+		return column + operator + s.bind(path[0]) + "::text"
+	}
+
+	operator := " #> "
+	if asText {
+		operator = " #>> "
+	}
+	return column + operator + s.bind(textArray(path)) + "::text[]"
+}
+
+// fieldAndValue returns the field name and the value of a comparison or function node
+func fieldAndValue(node *parser.ParseNode) (string, interface{}, error) {
+	fieldNode, valueNode := node.Children[0], node.Children[1]
+	if fieldNode.Token == nil || valueNode.Token == nil || fieldNode.Token.Type != parser.FilterTokenLiteral {
+		return "", nil, ErrInvalidInput
+	}
+
+	field, ok := fieldNode.Token.Value.(string)
+	if !ok || field == "" {
+		return "", nil, ErrInvalidInput
+	}
+
+	value, err := odata.TokenValue(valueNode.Token)
+	if err != nil {
+		return "", nil, fmt.Errorf("%w: %s", ErrInvalidInput, err.Error())
+	}
+
+	return field, value, nil
+}
+
+// escapeLike escapes the LIKE wildcards using the default backslash escape character
+func escapeLike(value string) string {
+	replacer := strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+	return replacer.Replace(value)
+}
+
+// textArray renders the keys as a PostgreSQL text array literal
+func textArray(values []string) string {
+	quoted := make([]string, len(values))
+	replacer := strings.NewReplacer(`\`, `\\`, `"`, `\"`)
+	for i, value := range values {
+		quoted[i] = `"` + replacer.Replace(value) + `"`
+	}
+
+	return "{" + strings.Join(quoted, ",") + "}"
+}
@@ -6,122 +6,195 @@
 
 package postgresql
 
-// import (
-// 	"database/sql"
-// 	"fmt"
-// 	"net/url"
-// 	"testing"
-
-// 	_ "github.com/lib/pq" // postgreSQL driver
-// 	"github.com/pkg/errors"
-// )
-
-// // For testing, run PostgreSQL as a docker container
-// // docker run -p 5432:5432 postgres:11-alpine
-// // current SQL mock testing libraries do not support jsonb
-
-// const (
-// 	host     = "postgres"
-// 	port     = 5432
-// 	user     = "postgres"
-// 	password = ""
-// 	dbname   = "postgres"
-// )
-
-// func TestFilter(t *testing.T) {
-
-// 	db := dbSetup()
-
-// 	var filterTests = []struct {
-// 		input    string
-// 		expected bool
-// 		Err      error
-// 	}{
-// 		{"((epc_item_type gt 0) and (event ne 'departed') and (required eq true) and (count lt 0.1) or " +
-// 			"(SKU eq '123') and contains(epc_time, '0') and startswith(epc_code, '456') or " +
-// 			"endswith(upc_code, '789'))", true, nil}, // large valid filter case
-// 		{"name eq 'test'", true, nil},                                      // key name with operator
-// 		{"age gt 10", true, nil},                                           // key name with operator
-// 		{"age ge 10", true, nil},                                           // key name with operator
-// 		{"age lt 10", true, nil},                                           // key name with operator
-// 		{"age le 10", true, nil},                                           // key name with operator
-// 		{"(name eq 'test') and (age gt 10) or (age le 10)", true, nil},     // key name with operator
-// 		{"name eq 'val')", false, errors.New("")},                          // bad parentheses
-// 		{"(name eq )", false, errors.New("")},                              // missing value in equals operator
-// 		{"(name eq hello) and (name fakeop hello)", false, errors.New("")}, // bad operator
-// 		{"epc_item_type ne 0 and name", false, errors.New("")},             // operators can't have a mix operators and literals
-// 		{"epc_item_type ne 0 and 0", false, errors.New("")},                // // operators can't have a mix operators and literals
-// 		{"name and epc_item_type ne 0", false, errors.New("")},             // // operators can't have a mix operators and literals
-// 		{"name eqs epc_item_type", false, errors.New("")},                  // typo operator
-// 		{"contains(and, epc_item_type)", false, errors.New("")},            // operator in function
-// 		{"0 eq epc_item_type", false, errors.New("")},                      // integer key name
-// 		{"", false, errors.New("")},                                        // empty string test
-// 	}
-
-// 	for _, expectedVal := range filterTests {
-// 		var testURLString = fmt.Sprintf("http://localhost/test?$filter=%s", expectedVal.input)
-// 		testURL, err := url.Parse(testURLString)
-// 		if err != nil {
-// 			t.Fatalf("Failed to parse test url")
-// 			return
-// 		}
-
-// 		_, errorQuery := ODataSQLQuery(testURL.Query(), "test", "data", db)
-
-// 		if (errorQuery != nil) != (expectedVal.Err != nil) {
-// 			t.Errorf("Expected error mismatch. Error : %s", errorQuery)
-// 		}
-
-// 	}
-
-// }
-
-// func TestODataQuery(t *testing.T) {
-
-// 	db := dbSetup()
-
-// 	testURL, err := url.Parse("http://localhost/test?$top=10&$skip=10&$select=name,lastname,age&$orderby=time asc,name desc,age")
-// 	if err != nil {
-// 		t.Error("failed to parse test url")
-// 	}
-
-// 	_, errorQuery := ODataSQLQuery(testURL.Query(), "test", "data", db)
-
-// 	if errorQuery != nil {
-// 		t.Error(errorQuery)
-// 	}
-
-// }
-
-// func TestCount(t *testing.T) {
-
-// 	db := dbSetup()
-
-// 	_, errorQuery := ODataCount(db, "test")
-
-// 	if errorQuery != nil {
-// 		t.Error(errorQuery)
-// 	}
-
-// }
-
-// func dbSetup() *sql.DB {
-
-// 	const schema = `
-// 			 CREATE TABLE IF NOT EXISTS test (
-// 				 id int,
-// 				 data JSONB
-// 			 );
-// 	 `
-// 	// Connect to PostgreSQL
-// 	psqlInfo := fmt.Sprintf("host=%s port=%d user=%s password=%s dbname=%s sslmode=disable", host, port, user, password, dbname)
-
-// 	db, err := sql.Open("postgres", psqlInfo)
-// 	if err != nil {
-// 		panic(err)
-// 	}
-// 	// Create table
-// 	db.Exec(schema)
-
-// 	return db
-// }
+import (
+	"errors"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBuildQuery_JsonbDocumentTable(t *testing.T) {
+	// Arrange
+	query := url.Values{}
+	query.Set("$select", "name,address.city")
+	query.Set("$filter", "name eq 'O''Brien' and (age gt 21 or address.city ne 'Porto')")
+	query.Set("$orderby", "age desc,name")
+	query.Set("$top", "10")
+	query.Set("$skip", "20")
+
+	// Act
+	result, err := BuildQuery(query, TableOptions{Table: "public.users", JsonbColumn: "data"})
+
+	// Assert
+	assert.Nil(t, err)
+	assert.Equal(t, `SELECT "id", jsonb_build_object($1::text, "data" -> $2::text, $3::text, "data" #> $4::text[]) AS "data"`+
+		` FROM "public"."users"`+
+		` WHERE ("data" ->> $5::text = $6 AND (("data" ->> $7::text)::numeric > $8 OR "data" #>> $9::text[] <> $10))`+
+		` ORDER BY "data" -> $11::text DESC, "data" -> $12::text ASC LIMIT $13 OFFSET $14`, result.SQL)
+	assert.Equal(t, []interface{}{
+		"name", "name", "address.city", `{"address","city"}`,
+		"name", "O'Brien", "age", 21, `{"address","city"}`, "Porto",
+		"age", "name", 10, 20,
+	}, result.Args)
+	assert.False(t, result.Count)
+	assert.Empty(t, result.CountSQL)
+}
+
+func TestBuildQuery_ColumnTable(t *testing.T) {
+	// Arrange
+	query := url.Values{}
+	query.Set("$select", "name,createdAt")
+	query.Set("$filter", "active eq true and createdAt ge 2021-03-04T10:00:00Z")
+	query.Set("$orderby", "createdAt desc")
+	options := TableOptions{
+		Table:   "users",
+		Columns: map[string]string{"name": "full_name", "active": "active", "createdAt": "created_at"},
+	}
+
+	// Act
+	result, err := BuildQuery(query, options)
+
+	// Assert
+	assert.Nil(t, err)
+	assert.Equal(t, `SELECT "full_name", "created_at" FROM "users" WHERE ("active" = $1 AND "created_at" >= $2) ORDER BY "created_at" DESC`, result.SQL)
+	assert.Equal(t, []interface{}{true, time.Date(2021, 3, 4, 10, 0, 0, 0, time.UTC)}, result.Args)
+}
+
+func TestBuildQuery_OrderByComparesJsonbValues(t *testing.T) {
+	// Arrange
+	query := url.Values{}
+	query.Set("$filter", "age in (9, 10, 100)")
+	query.Set("$orderby", "age,address.zip desc")
+
+	// Act
+	result, err := BuildQuery(query, TableOptions{Table: "users", JsonbColumn: "data"})
+
+	// Assert
+	assert.Nil(t, err)
+	assert.Equal(t, `SELECT * FROM "users" WHERE ("data" ->> $1::text)::numeric IN ($2, $3, $4)`+
+		` ORDER BY "data" -> $5::text ASC, "data" #> $6::text[] DESC`, result.SQL)
+	assert.Equal(t, []interface{}{"age", 9, 10, 100, "age", `{"address","zip"}`}, result.Args)
+}
+
+func TestBuildQuery_RejectsFieldsOutsideTheColumnMap(t *testing.T) {
+	// Arrange
+	options := TableOptions{Table: "users", Columns: map[string]string{"name": "full_name"}}
+	tests := []string{"$filter=password eq 'x'", "$select=password", "$orderby=password"}
+
+	for _, test := range tests {
+		query, _ := url.ParseQuery(test)
+
+		// Act
+		result, err := BuildQuery(query, options)
+
+		// Assert
+		assert.Nil(t, result, test)
+		assert.True(t, errors.Is(err, ErrUnknownField), test)
+	}
+}
+
+func TestBuildQuery_EscapesLikeWildcards(t *testing.T) {
+	// Arrange
+	query := url.Values{}
+	query.Set("$filter", "contains(name,'50%_off') or startswith(name,'a\\b') or endswith(name,'z')")
+	options := TableOptions{Table: "products", Columns: map[string]string{"name": "name"}}
+
+	// Act
+	result, err := BuildQuery(query, options)
+
+	// Assert
+	assert.Nil(t, err)
+	assert.Equal(t, `SELECT * FROM "products" WHERE (("name" LIKE $1 OR "name" LIKE $2) OR "name" LIKE $3)`, result.SQL)
+	assert.Equal(t, []interface{}{`%50\%\_off%`, `a\\b%`, `%z`}, result.Args)
+}
+
+func TestBuildQuery_InOperator(t *testing.T) {
+	// Arrange
+	query := url.Values{}
+	query.Set("$filter", "status in ('new','open') and attempts in (1, 2.5)")
+
+	// Act
+	result, err := BuildQuery(query, TableOptions{Table: "tickets", JsonbColumn: "doc"})
+
+	// Assert
+	assert.Nil(t, err)
+	assert.Equal(t, `SELECT * FROM "tickets" WHERE ("doc" ->> $1::text IN ($2, $3) AND ("doc" ->> $4::text)::numeric IN ($5, $6))`, result.SQL)
+	assert.Equal(t, []interface{}{"status", "new", "open", "attempts", 1, 2.5}, result.Args)
+}
+
+func TestBuildQuery_NullComparisons(t *testing.T) {
+	// Arrange
+	query := url.Values{}
+	query.Set("$filter", "deletedAt eq null and owner ne null and nullable eq 'x'")
+	options := TableOptions{Table: "tickets", Columns: map[string]string{"deletedAt": "deleted_at", "owner": "owner", "nullable": "nullable"}}
+
+	// Act
+	result, err := BuildQuery(query, options)
+	_, jsonbErr := BuildQuery(url.Values{"$filter": {"owner eq null"}}, TableOptions{Table: "tickets", JsonbColumn: "doc"})
+	_, invalidErr := BuildQuery(url.Values{"$filter": {"owner gt null"}}, options)
+
+	// Assert
+	assert.Nil(t, err)
+	assert.Equal(t, `SELECT * FROM "tickets" WHERE (("deleted_at" IS NULL AND "owner" IS NOT NULL) AND "nullable" = $1)`, result.SQL)
+	assert.Equal(t, []interface{}{"x"}, result.Args)
+	assert.Nil(t, jsonbErr)
+	assert.True(t, errors.Is(invalidErr, ErrInvalidInput))
+}
+
+func TestBuildQuery_CountHasItsOwnPlaceholders(t *testing.T) {
+	// Arrange
+	query := url.Values{}
+	query.Set("$select", "name")
+	query.Set("$filter", "price le 9.5")
+	query.Set("$top", "5")
+	query.Set("$inlinecount", "allpages")
+
+	// Act
+	result, err := BuildQuery(query, TableOptions{Table: "products", JsonbColumn: "doc", IdColumn: "key"})
+
+	// Assert
+	assert.Nil(t, err)
+	assert.Equal(t, `SELECT "key", jsonb_build_object($1::text, "doc" -> $2::text) AS "doc" FROM "products" WHERE ("doc" ->> $3::text)::numeric <= $4 LIMIT $5`, result.SQL)
+	assert.True(t, result.Count)
+	assert.Equal(t, `SELECT count(*) FROM "products" WHERE ("doc" ->> $1::text)::numeric <= $2`, result.CountSQL)
+	assert.Equal(t, []interface{}{"price", 9.5}, result.CountArgs)
+}
+
+func TestBuildQuery_InvalidInput(t *testing.T) {
+	// Arrange
+	options := TableOptions{Table: "products", JsonbColumn: "doc"}
+	tests := []string{
+		"$top=-1", "$skip=-5", "$filter=name eq", "$unknown=1",
+		"$filter=status in ('new', 1)", "$filter=attempts in (1, true)", "$filter=createdAt in (2021-03-04, 'x')",
+	}
+
+	for _, test := range tests {
+		query, _ := url.ParseQuery(test)
+
+		// Act
+		result, err := BuildQuery(query, options)
+
+		// Assert
+		assert.Nil(t, result, test)
+		assert.True(t, errors.Is(err, ErrInvalidInput), test)
+	}
+}
+
+func TestBuildQuery_RequiresTableOptions(t *testing.T) {
+	// Act
+	_, missingTable := BuildQuery(url.Values{}, TableOptions{JsonbColumn: "doc"})
+	_, missingColumns := BuildQuery(url.Values{}, TableOptions{Table: "products"})
+
+	// Assert
+	assert.NotNil(t, missingTable)
+	assert.NotNil(t, missingColumns)
+}
+
+func TestQuoteIdentifier(t *testing.T) {
+	// Act + Assert
+	assert.Equal(t, `"users"`, QuoteIdentifier("users"))
+	assert.Equal(t, `"app"."users"`, QuoteIdentifier("app.users"))
+	assert.Equal(t, `"us""ers"`, QuoteIdentifier(`us"ers`))
+}
@@ -1,6 +1,9 @@
 package parser
 
-import "errors"
+import (
+	"errors"
+	"strings"
+)
 
 // Parser parser structure
 type Parser struct {
@@ -8,11 +11,17 @@ type Parser struct {
 	Operators map[string]*Operator
 	// Map from string inputs to function types
 	Functions map[string]*Function
+	// Operators whose right operand is a parenthesized list of values
+	ListOperators map[string]bool
 }
 
 // EmptyParser create empty parser
 func EmptyParser() *Parser {
-	return &Parser{make(map[string]*Operator), make(map[string]*Function)}
+	return &Parser{
+		Operators:     make(map[string]*Operator),
+		Functions:     make(map[string]*Function),
+		ListOperators: make(map[string]bool),
+	}
 }
 
 // DefineOperator Adds an operator to the language. Provide the token, a precedence, and
@@ -21,12 +30,23 @@ func (p *Parser) DefineOperator(token string, operands, assoc, precedence int) {
 	p.Operators[token] = &Operator{token, assoc, operands, precedence}
 }
 
+// DefineListOperator Adds a binary operator whose right operand is a list of values, for
+// example "name in ('a','b')". The list is parsed as a single FilterTokenList token
+func (p *Parser) DefineListOperator(token string, assoc, precedence int) {
+	p.DefineOperator(token, 2, assoc, precedence)
+	p.ListOperators[token] = true
+}
+
 // DefineFunction Adds a function to the language
 func (p *Parser) DefineFunction(token string, params int) {
 	p.Functions[token] = &Function{token, params}
 }
 
 func (p *Parser) Parse(tokens []*Token) (*ParseNode, error) {
+	tokens, err := p.groupLists(tokens)
+	if err != nil {
+		return nil, err
+	}
 	postfix, err := p.infixToPostfix(tokens)
 	if err != nil {
 		return nil, err
@@ -39,6 +59,68 @@ func (p *Parser) Parse(tokens []*Token) (*ParseNode, error) {
 	return tree, nil
 }
 
+// groupLists Replaces the parenthesized values following a list operator with a single list
+// token holding the value tokens
+func (p *Parser) groupLists(tokens []*Token) ([]*Token, error) {
+	if len(p.ListOperators) == 0 {
+		return tokens, nil
+	}
+
+	result := make([]*Token, 0, len(tokens))
+	for i := 0; i < len(tokens); i++ {
+		result = append(result, tokens[i])
+		if !p.ListOperators[tokens[i].stringValue] {
+			continue
+		}
+
+		i++
+		if i >= len(tokens) || tokens[i].Type != FilterTokenOpenParen {
+			return nil, errors.New("parse error: " + tokens[i-1].stringValue + " expects a list of values")
+		}
+
+		values := make([]*Token, 0)
+		texts := make([]string, 0)
+		expectValue := true
+		closed := false
+		for i++; i < len(tokens); i++ {
+			token := tokens[i]
+			if token.Type == FilterTokenCloseParen && !expectValue {
+				closed = true
+				break
+			}
+			if expectValue && isValueToken(token) {
+				values = append(values, token)
+				texts = append(texts, token.stringValue)
+			} else if expectValue || token.Type != FilterTokenComma {
+				return nil, errors.New("parse error: invalid list of values")
+			}
+			expectValue = !expectValue
+		}
+		if !closed {
+			return nil, errors.New("parse error: mismatched parenthesis")
+		}
+
+		result = append(result, &Token{
+			stringValue: "(" + strings.Join(texts, ",") + ")",
+			Value:       values,
+			Type:        FilterTokenList,
+		})
+	}
+
+	return result, nil
+}
+
+// isValueToken returns true for the tokens that can be used as list values
+func isValueToken(token *Token) bool {
+	switch token.Type {
+	case FilterTokenString, FilterTokenInteger, FilterTokenFloat, FilterTokenBoolean,
+		FilterTokenDate, FilterTokenTime, FilterTokenDateTime:
+		return true
+	}
+
+	return false
+}
+
 // InfixToPostfix Parses the input string of tokens using the given definitions of operators
 // and functions. (Everything else is assumed to be a literal.) Uses the
 // Shunting-Yard algorithm.
@@ -16,4 +16,6 @@ const (
 	FilterTokenDateTime
 	FilterTokenBoolean
 	FilterTokenLiteral
+	FilterTokenList
+	FilterTokenNull
 )
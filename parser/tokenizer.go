@@ -89,11 +89,29 @@ func convertValue(token []byte, tokenType int) (interface{}, error) {
 		return strconv.ParseBool(string(token))
 	case FilterTokenFloat:
 		return strconv.ParseFloat(string(token), 10)
+	case FilterTokenNull:
+		return nil, nil
 	case FilterTokenLiteral, FilterTokenString:
 		return strings.TrimSpace(string(token)), nil
-	case FilterTokenDateTime, FilterTokenDate, FilterTokenTime:
-		return time.Parse(time.RFC1123, string(token))
+	case FilterTokenDateTime:
+		return parseTime(string(token), time.RFC3339Nano, "2006-01-02T15:04Z07:00")
+	case FilterTokenDate:
+		return parseTime(string(token), time.DateOnly)
+	case FilterTokenTime:
+		return parseTime(string(token), "15:04:05.999999999", "15:04")
 	default:
 		return strings.TrimSpace(string(token)), nil
 	}
 }
+
+func parseTime(value string, layouts ...string) (time.Time, error) {
+	var err error
+	for _, layout := range layouts {
+		var result time.Time
+		if result, err = time.Parse(layout, value); err == nil {
+			return result, nil
+		}
+	}
+
+	return time.Time{}, err
+}
@@ -0,0 +1,67 @@
+package encryption
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"errors"
+	"io"
+)
+
+var ErrInvalidCipherText = errors.New("cipher text is too short")
+
+type AESHelper struct{}
+
+// GenerateKey Generates a random AES key, only 128, 192 and 256 bit keys are supported
+func (h AESHelper) GenerateKey(size EncryptionKeySize) ([]byte, error) {
+	length := int(size) / 8
+	if length != 16 && length != 24 && length != 32 {
+		return nil, aes.KeySizeError(length)
+	}
+
+	key := make([]byte, length)
+	if _, err := io.ReadFull(rand.Reader, key); err != nil {
+		return nil, err
+	}
+
+	return key, nil
+}
+
+// Encrypt Encrypts the plain text with AES-GCM, the random nonce is prepended to the result
+func (h AESHelper) Encrypt(key []byte, plainText []byte) ([]byte, error) {
+	gcm, err := h.newGCM(key)
+	if err != nil {
+		return nil, err
+	}
+
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		return nil, err
+	}
+
+	return gcm.Seal(nonce, nonce, plainText, nil), nil
+}
+
+// Decrypt Decrypts a cipher text created by Encrypt
+func (h AESHelper) Decrypt(key []byte, cipherText []byte) ([]byte, error) {
+	gcm, err := h.newGCM(key)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(cipherText) < gcm.NonceSize() {
+		return nil, ErrInvalidCipherText
+	}
+
+	nonce := cipherText[:gcm.NonceSize()]
+	return gcm.Open(nil, nonce, cipherText[gcm.NonceSize():], nil)
+}
+
+func (h AESHelper) newGCM(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+
+	return cipher.NewGCM(block)
+}
@@ -3,6 +3,7 @@ package encryption
 import (
 	"crypto/rand"
 	"crypto/rsa"
+	"crypto/sha256"
 	"crypto/x509"
 	"encoding/base64"
 	"encoding/pem"
@@ -148,3 +149,13 @@ func (h RSAHelper) GenerateKeys(size EncryptionKeySize) (*rsa.PrivateKey, *rsa.P
 
 	return priv, &priv.PublicKey
 }
+
+// WrapKey Encrypts a data key with the public key using RSA-OAEP with SHA-256
+func (h RSAHelper) WrapKey(publicKey *rsa.PublicKey, key []byte) ([]byte, error) {
+	return rsa.EncryptOAEP(sha256.New(), rand.Reader, publicKey, key, nil)
+}
+
+// UnwrapKey Decrypts a data key created by WrapKey
+func (h RSAHelper) UnwrapKey(privateKey *rsa.PrivateKey, wrappedKey []byte) ([]byte, error) {
+	return rsa.DecryptOAEP(sha256.New(), rand.Reader, privateKey, wrappedKey, nil)
+}
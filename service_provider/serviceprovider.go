@@ -1,6 +1,7 @@
 package service_provider
 
 import (
+	"fmt"
 	"net/http"
 	"strings"
 
@@ -63,3 +64,30 @@ func (sp *ServiceProvider) GetBaseUrl(r *http.Request) string {
 
 	return baseUrl
 }
+
+// WatchLogLevel Sets the level of the logger from the configuration key and changes it every
+// time the key changes, an empty key uses CJ_LOG_LEVEL. The returned function stops watching
+func (sp *ServiceProvider) WatchLogLevel(key string) func() {
+	if key == "" {
+		key = constants.LOG_LEVEL
+	}
+
+	apply := func(value interface{}) {
+		if value == nil {
+			return
+		}
+
+		level, err := log.ParseLevel(fmt.Sprintf("%v", value))
+		if err != nil {
+			sp.Logger.Warn("Ignoring the %s configuration, %s", key, err.Error())
+			return
+		}
+
+		sp.Logger.SetLevel(level)
+	}
+
+	apply(sp.Configuration.Get(key))
+	return sp.Configuration.Watch(key, func(oldValue interface{}, newValue interface{}) {
+		apply(newValue)
+	})
+}
@@ -3,6 +3,7 @@ package service_provider
 import (
 	"testing"
 
+	"github.com/cjlapao/common-go/log"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -49,3 +50,25 @@ func TestGetServiceProviderShouldReturnExistingServicesWithDefaultValues(t *test
 	assert.NotNilf(t, svc.Logger, "Logger should not be nil")
 	assert.NotNilf(t, svc.Version, "Version Service should not be nil")
 }
+
+func TestWatchLogLevelShouldChangeTheLoggerLevel(t *testing.T) {
+	// Arrange
+	globalProviderContainer = nil
+	svc := New()
+	svc.Configuration.UpsertKey("TEST_LOG_LEVEL", "debug")
+	defer svc.Logger.SetLevel(log.Info)
+
+	// Act
+	cancel := svc.WatchLogLevel("TEST_LOG_LEVEL")
+	initial := svc.Logger.Level()
+	svc.Configuration.UpsertKey("TEST_LOG_LEVEL", "trace")
+	changed := svc.Logger.Level()
+	svc.Configuration.UpsertKey("TEST_LOG_LEVEL", "unknown")
+	cancel()
+	svc.Configuration.UpsertKey("TEST_LOG_LEVEL", "error")
+
+	// Assert
+	assert.Equal(t, log.Debug, initial)
+	assert.Equal(t, log.Trace, changed)
+	assert.Equal(t, log.Trace, svc.Logger.Level())
+}